@@ -304,6 +304,291 @@ func init() {
 		viper.SetDefault(key, defaultValue)
 	}
 
+	{
+		const (
+			key          = keys.SubmitMaxBytes
+			longOpt      = "submit-max-bytes"
+			envVar       = release.ENVPREFIX + "_CIRCONUS_SUBMIT_MAX_BYTES"
+			description  = "Maximum serialized size of a trap submission, larger batches are split into chunks"
+			defaultValue = defaults.SubmitMaxBytes
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key         = keys.SubmitMaxMetrics
+			longOpt     = "submit-max-metrics"
+			envVar      = release.ENVPREFIX + "_CIRCONUS_SUBMIT_MAX_METRICS"
+			description = "Maximum number of metrics in a trap submission, larger batches are split into chunks (0 = unlimited)"
+		)
+		defaultValue := uint(defaults.SubmitMaxMetrics)
+
+		rootCmd.PersistentFlags().Uint(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key         = keys.SubmitParallelism
+			longOpt     = "submit-parallelism"
+			envVar      = release.ENVPREFIX + "_CIRCONUS_SUBMIT_PARALLELISM"
+			description = "Number of chunks of a split trap submission sent concurrently"
+		)
+		defaultValue := uint(defaults.SubmitParallelism)
+
+		rootCmd.PersistentFlags().Uint(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key         = keys.SubmitStreamMin
+			longOpt     = "submit-stream-min"
+			envVar      = release.ENVPREFIX + "_CIRCONUS_SUBMIT_STREAM_MIN"
+			description = "Minimum number of metrics in a trap submission chunk to stream it (encoded directly into the request body, sent without a Content-Length) (0 = never stream)"
+		)
+		defaultValue := uint(defaults.SubmitStreamMin)
+
+		rootCmd.PersistentFlags().Uint(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key         = keys.BrokerMaxIdleConns
+			longOpt     = "broker-max-idle-conns"
+			envVar      = release.ENVPREFIX + "_CIRCONUS_BROKER_MAX_IDLE_CONNS"
+			description = "Maximum idle (keep-alive) connections kept to the broker"
+		)
+		defaultValue := uint(defaults.BrokerMaxIdleConns)
+
+		rootCmd.PersistentFlags().Uint(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.BrokerIdleConnTimeout
+			longOpt      = "broker-idle-conn-timeout"
+			envVar       = release.ENVPREFIX + "_CIRCONUS_BROKER_IDLE_CONN_TIMEOUT"
+			description  = "How long idle broker connections are kept open"
+			defaultValue = defaults.BrokerIdleConnTimeout
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.BrokerDisableHTTP2
+			longOpt      = "broker-disable-http2"
+			envVar       = release.ENVPREFIX + "_CIRCONUS_BROKER_DISABLE_HTTP2"
+			description  = "Disable negotiating http/2 with the broker"
+			defaultValue = defaults.BrokerDisableHTTP2
+		)
+
+		rootCmd.PersistentFlags().Bool(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.BrokerRoundRobin
+			longOpt      = "broker-round-robin"
+			envVar       = release.ENVPREFIX + "_CIRCONUS_BROKER_ROUND_ROBIN"
+			description  = "Spread submissions across healthy brokers (check bundles with multiple brokers)"
+			defaultValue = defaults.BrokerRoundRobin
+		)
+
+		rootCmd.PersistentFlags().Bool(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.Sinks
+			longOpt      = "sinks"
+			envVar       = release.ENVPREFIX + "_CIRCONUS_SINKS"
+			description  = "Comma separated list of metric destinations (circonus-trap, stdout-json, file, prometheus-remote-write, otlp)"
+			defaultValue = defaults.Sinks
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.SinkFile
+			longOpt      = "sink-file"
+			envVar       = release.ENVPREFIX + "_CIRCONUS_SINK_FILE"
+			description  = "File metrics are appended to when using the file sink"
+			defaultValue = defaults.SinkFile
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.RemoteWriteURL
+			longOpt      = "remote-write-url"
+			envVar       = release.ENVPREFIX + "_CIRCONUS_REMOTE_WRITE_URL"
+			description  = "Prometheus remote-write endpoint URL (prometheus-remote-write sink)"
+			defaultValue = defaults.RemoteWriteURL
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.RemoteWriteBearerToken
+			longOpt      = "remote-write-bearer-token"
+			envVar       = release.ENVPREFIX + "_CIRCONUS_REMOTE_WRITE_BEARER_TOKEN"
+			description  = "Prometheus remote-write endpoint bearer token (prometheus-remote-write sink)"
+			defaultValue = defaults.RemoteWriteBearerToken
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.OTLPURL
+			longOpt      = "otlp-url"
+			envVar       = release.ENVPREFIX + "_CIRCONUS_OTLP_URL"
+			description  = "OpenTelemetry OTLP/HTTP metrics endpoint URL (otlp sink)"
+			defaultValue = defaults.OTLPURL
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.OTLPEncoding
+			longOpt      = "otlp-encoding"
+			envVar       = release.ENVPREFIX + "_CIRCONUS_OTLP_ENCODING"
+			description  = "OTLP/HTTP request encoding, protobuf or json (otlp sink)"
+			defaultValue = defaults.OTLPEncoding
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.OTLPHeaders
+			longOpt      = "otlp-headers"
+			envVar       = release.ENVPREFIX + "_CIRCONUS_OTLP_HEADERS"
+			description  = "Comma separated list of key=value headers sent with OTLP/HTTP requests (otlp sink)"
+			defaultValue = defaults.OTLPHeaders
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.SpoolDir
+			longOpt      = "spool-dir"
+			envVar       = release.ENVPREFIX + "_CIRCONUS_SPOOL_DIR"
+			description  = "Directory failed submissions are spooled to and replayed from (empty disables spooling)"
+			defaultValue = defaults.SpoolDir
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.SpoolMaxSize
+			longOpt      = "spool-max-size"
+			envVar       = release.ENVPREFIX + "_CIRCONUS_SPOOL_MAX_SIZE"
+			description  = "Maximum total size of spooled submissions, oldest are dropped first"
+			defaultValue = defaults.SpoolMaxSize
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.SpoolMaxAge
+			longOpt      = "spool-max-age"
+			envVar       = release.ENVPREFIX + "_CIRCONUS_SPOOL_MAX_AGE"
+			description  = "Maximum age of spooled submissions, older are dropped"
+			defaultValue = defaults.SpoolMaxAge
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key         = keys.SpoolMaxReplayRate
+			longOpt     = "spool-max-replay-rate"
+			envVar      = release.ENVPREFIX + "_CIRCONUS_SPOOL_MAX_REPLAY_RATE"
+			description = "Maximum spooled submissions replayed per second (0 = unlimited)"
+		)
+		defaultValue := uint(defaults.SpoolMaxReplayRate)
+
+		rootCmd.PersistentFlags().Uint(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
 	//
 	// hidden circonus options for development and debugging
 	//
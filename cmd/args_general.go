@@ -73,4 +73,169 @@ func init() {
 		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
 		viper.SetDefault(key, defaultValue)
 	}
+
+	{
+		const (
+			key          = keys.ConfigWatchInterval
+			longOpt      = "config-watch-interval"
+			envVar       = release.ENVPREFIX + "_CONFIG_WATCH_INTERVAL"
+			description  = "Check configuration files for changes and reload every interval (e.g. 30s), blank disables (reload with SIGHUP)"
+			defaultValue = defaults.ConfigWatchInterval
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.ProbesMaxCollectAge
+			longOpt      = "probe-max-collect-age"
+			envVar       = release.ENVPREFIX + "_PROBE_MAX_COLLECT_AGE"
+			description  = "Liveness fails when no collection has succeeded within this duration, blank uses 3 collection intervals"
+			defaultValue = defaults.ProbesMaxCollectAge
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.ProbesMaxSubmitAge
+			longOpt      = "probe-max-submit-age"
+			envVar       = release.ENVPREFIX + "_PROBE_MAX_SUBMIT_AGE"
+			description  = "Liveness fails when no submission has succeeded within this duration"
+			defaultValue = defaults.ProbesMaxSubmitAge
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.ProbesMaxDeadlineTimeouts
+			longOpt      = "probe-max-deadline-timeouts"
+			envVar       = release.ENVPREFIX + "_PROBE_MAX_DEADLINE_TIMEOUTS"
+			description  = "Liveness fails after this many consecutive collections hit the collection deadline, 0 disables"
+			defaultValue = defaults.ProbesMaxDeadlineTimeouts
+		)
+
+		rootCmd.PersistentFlags().Uint(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.ServerListen
+			longOpt      = "server-listen"
+			envVar       = release.ENVPREFIX + "_SERVER_LISTEN"
+			description  = "Listen address for the internal http server (stats, metrics, probes)"
+			defaultValue = defaults.ServerListen
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.ServerTLSCertFile
+			longOpt      = "server-tls-cert-file"
+			envVar       = release.ENVPREFIX + "_SERVER_TLS_CERT_FILE"
+			description  = "Certificate file for serving https from the internal http server"
+			defaultValue = defaults.ServerTLSCertFile
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.ServerTLSKeyFile
+			longOpt      = "server-tls-key-file"
+			envVar       = release.ENVPREFIX + "_SERVER_TLS_KEY_FILE"
+			description  = "Key file for serving https from the internal http server"
+			defaultValue = defaults.ServerTLSKeyFile
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.ServerClientCAFile
+			longOpt      = "server-client-ca-file"
+			envVar       = release.ENVPREFIX + "_SERVER_CLIENT_CA_FILE"
+			description  = "CA file to verify client certificates (mTLS) for the internal http server, requires TLS"
+			defaultValue = defaults.ServerClientCAFile
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.ServerBearerToken
+			longOpt      = "server-bearer-token"
+			envVar       = release.ENVPREFIX + "_SERVER_BEARER_TOKEN"
+			description  = "Bearer token required by the internal http server"
+			defaultValue = defaults.ServerBearerToken
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.ServerBearerTokenFile
+			longOpt      = "server-bearer-token-file"
+			envVar       = release.ENVPREFIX + "_SERVER_BEARER_TOKEN_FILE"
+			description  = "File containing the bearer token required by the internal http server"
+			defaultValue = defaults.ServerBearerTokenFile
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.ServerEnablePprof
+			longOpt      = "server-enable-pprof"
+			envVar       = release.ENVPREFIX + "_SERVER_ENABLE_PPROF"
+			description  = "Enable pprof endpoints (/debug/pprof/) on the internal http server"
+			defaultValue = defaults.ServerEnablePprof
+		)
+
+		rootCmd.PersistentFlags().Bool(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
 }
@@ -488,6 +488,36 @@ func init() {
 		viper.SetDefault(key, defaultValue)
 	}
 
+	{
+		const (
+			key          = keys.K8SAPIQPS
+			longOpt      = "k8s-api-qps"
+			envVar       = release.ENVPREFIX + "_K8S_API_QPS"
+			description  = "Kubernetes API queries per second"
+			defaultValue = defaults.K8SAPIQPS
+		)
+
+		rootCmd.PersistentFlags().Uint(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.K8SAPIBurst
+			longOpt      = "k8s-api-burst"
+			envVar       = release.ENVPREFIX + "_K8S_API_BURST"
+			description  = "Kubernetes API query burst"
+			defaultValue = defaults.K8SAPIBurst
+		)
+
+		rootCmd.PersistentFlags().Uint(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
 	{
 		const (
 			key          = keys.K8SDynamicCollectorFile
@@ -502,4 +532,316 @@ func init() {
 		bindEnvError(envVar, viper.BindEnv(key, envVar))
 		viper.SetDefault(key, defaultValue)
 	}
+
+	{
+		const (
+			key          = keys.K8SPodCacheResync
+			longOpt      = "k8s-pod-cache-resync"
+			envVar       = release.ENVPREFIX + "_K8S_POD_CACHE_RESYNC"
+			description  = "Kubernetes pod cache resync period"
+			defaultValue = defaults.K8SPodCacheResync
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.K8SNodeRequestMode
+			longOpt      = "k8s-node-request-mode"
+			envVar       = release.ENVPREFIX + "_K8S_NODE_REQUEST_MODE"
+			description  = "Kubernetes kubelet request mode, proxy (via api-server) or direct"
+			defaultValue = defaults.K8SNodeRequestMode
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.K8SKubeletPort
+			longOpt      = "k8s-kubelet-port"
+			envVar       = release.ENVPREFIX + "_K8S_KUBELET_PORT"
+			description  = "Kubernetes kubelet port for direct mode if not in node status"
+			defaultValue = defaults.K8SKubeletPort
+		)
+
+		rootCmd.PersistentFlags().Int(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.K8SKubeletCAFile
+			longOpt      = "k8s-kubelet-cafile"
+			envVar       = release.ENVPREFIX + "_K8S_KUBELET_CAFILE"
+			description  = "Kubernetes kubelet CA file for direct mode (default: api CA file)"
+			defaultValue = defaults.K8SKubeletCAFile
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.K8SKubeletInsecure
+			longOpt      = "k8s-kubelet-insecure"
+			envVar       = release.ENVPREFIX + "_K8S_KUBELET_INSECURE"
+			description  = "Kubernetes do not verify kubelet certificates in direct mode"
+			defaultValue = defaults.K8SKubeletInsecure
+		)
+
+		rootCmd.PersistentFlags().Bool(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.K8SMode
+			longOpt      = "mode"
+			envVar       = release.ENVPREFIX + "_MODE"
+			description  = "Collection mode, all, cluster (cluster-scope collectors only), or node (local node only, run as DaemonSet)"
+			defaultValue = defaults.K8SMode
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.K8SNodeName
+			longOpt      = "k8s-node-name"
+			envVar       = "NODE_NAME"
+			description  = "Kubernetes name of the node the agent is running on, required in node mode (downward api spec.nodeName)"
+			defaultValue = defaults.K8SNodeName
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+	{
+		const (
+			key          = keys.K8SCollectorSchedules
+			longOpt      = "k8s-collector-schedules"
+			envVar       = release.ENVPREFIX + "_K8S_COLLECTOR_SCHEDULES"
+			description  = "Kubernetes per-collector intervals and deadlines, id=interval[/deadline],... (ids: node, ksm, api, dns, health, dynamic e.g. api=5m,node=1m/50s)"
+			defaultValue = defaults.K8SCollectorSchedules
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+	{
+		const (
+			key          = keys.K8SLeaderElect
+			longOpt      = "k8s-leader-elect"
+			envVar       = release.ENVPREFIX + "_K8S_LEADER_ELECT"
+			description  = "Kubernetes enable leader election, for running multiple replicas (only the leader collects)"
+			defaultValue = defaults.K8SLeaderElect
+		)
+
+		rootCmd.PersistentFlags().Bool(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.K8SLeaderElectionNamespace
+			longOpt      = "k8s-leader-election-namespace"
+			envVar       = release.ENVPREFIX + "_K8S_LEADER_ELECTION_NAMESPACE"
+			description  = "Kubernetes leader election lease namespace (default: service account namespace)"
+			defaultValue = defaults.K8SLeaderElectionNamespace
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.K8SLeaderElectionLeaseName
+			longOpt      = "k8s-leader-election-lease-name"
+			envVar       = release.ENVPREFIX + "_K8S_LEADER_ELECTION_LEASE_NAME"
+			description  = "Kubernetes leader election lease name"
+			defaultValue = defaults.K8SLeaderElectionLeaseName
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.K8SLeaderElectionLeaseDuration
+			longOpt      = "k8s-leader-election-lease-duration"
+			envVar       = release.ENVPREFIX + "_K8S_LEADER_ELECTION_LEASE_DURATION"
+			description  = "Kubernetes leader election lease duration, standby replicas take over after this long without a renewal"
+			defaultValue = defaults.K8SLeaderElectionLeaseDuration
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.K8SLeaderElectionRenewDeadline
+			longOpt      = "k8s-leader-election-renew-deadline"
+			envVar       = release.ENVPREFIX + "_K8S_LEADER_ELECTION_RENEW_DEADLINE"
+			description  = "Kubernetes leader election renew deadline, the leader gives up leadership if it cannot renew in this long"
+			defaultValue = defaults.K8SLeaderElectionRenewDeadline
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.K8SLeaderElectionRetryPeriod
+			longOpt      = "k8s-leader-election-retry-period"
+			envVar       = release.ENVPREFIX + "_K8S_LEADER_ELECTION_RETRY_PERIOD"
+			description  = "Kubernetes leader election retry period, how often to try to acquire or renew the lease"
+			defaultValue = defaults.K8SLeaderElectionRetryPeriod
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.K8SClientCertFile
+			longOpt      = "k8s-client-cert-file"
+			envVar       = release.ENVPREFIX + "_K8S_CLIENT_CERT_FILE"
+			description  = "Kubernetes API client certificate file (alternative to a bearer token)"
+			defaultValue = defaults.K8SClientCertFile
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.K8SClientKeyFile
+			longOpt      = "k8s-client-key-file"
+			envVar       = release.ENVPREFIX + "_K8S_CLIENT_KEY_FILE"
+			description  = "Kubernetes API client certificate key file"
+			defaultValue = defaults.K8SClientKeyFile
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.K8SExecCommand
+			longOpt      = "k8s-exec-command"
+			envVar       = release.ENVPREFIX + "_K8S_EXEC_COMMAND"
+			description  = "Kubernetes API credential exec plugin command (e.g. aws-iam-authenticator, alternative to a bearer token)"
+			defaultValue = defaults.K8SExecCommand
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key         = keys.K8SExecArgs
+			longOpt     = "k8s-exec-args"
+			envVar      = release.ENVPREFIX + "_K8S_EXEC_ARGS"
+			description = "Kubernetes API credential exec plugin arguments (comma separated)"
+		)
+
+		rootCmd.PersistentFlags().StringSlice(longOpt, defaults.K8SExecArgs, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaults.K8SExecArgs)
+	}
+
+	{
+		const (
+			key          = keys.K8SExecAPIVersion
+			longOpt      = "k8s-exec-api-version"
+			envVar       = release.ENVPREFIX + "_K8S_EXEC_API_VERSION"
+			description  = "Kubernetes API credential exec plugin api version"
+			defaultValue = defaults.K8SExecAPIVersion
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.K8SKubeconfig
+			longOpt      = "k8s-kubeconfig"
+			envVar       = release.ENVPREFIX + "_K8S_KUBECONFIG"
+			description  = "Kubernetes kubeconfig file, for running outside the cluster (supersedes api url, ca file and credentials)"
+			defaultValue = defaults.K8SKubeconfig
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = keys.K8SKubeContext
+			longOpt      = "k8s-kube-context"
+			envVar       = release.ENVPREFIX + "_K8S_KUBE_CONTEXT"
+			description  = "Kubernetes kubeconfig context (default: current-context)"
+			defaultValue = defaults.K8SKubeContext
+		)
+
+		rootCmd.PersistentFlags().String(longOpt, defaultValue, envDescription(description, envVar))
+		bindFlagError(longOpt, viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(longOpt)))
+		bindEnvError(envVar, viper.BindEnv(key, envVar))
+		viper.SetDefault(key, defaultValue)
+	}
 }
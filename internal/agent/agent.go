@@ -14,8 +14,11 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/circonus"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/cluster"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config/defaults"
@@ -32,20 +35,42 @@ type Agent struct {
 	group       *errgroup.Group
 	groupCtx    context.Context
 	groupCancel context.CancelFunc
-	clusters    map[string]*cluster.Cluster
 	signalCh    chan os.Signal
 	logger      zerolog.Logger
+
+	clusters   map[string]*cluster.Cluster
+	runs       map[string]clusterRun
+	failed     map[string]string // clusters which failed to initialize
+	clustersmu sync.RWMutex
+
+	watchInterval time.Duration
+	reloadmu      sync.Mutex
+
+	pprof   bool
+	probes  cluster.ProbeThresholds
+	probemu sync.RWMutex
 }
 
-func serveHTTP(w http.ResponseWriter, r *http.Request) {
+func (a *Agent) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if a.pprof && strings.HasPrefix(r.URL.Path, "/debug/pprof/") {
+		servePprof(w, r) // profile and symbol also accept POST
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
 		switch r.URL.Path {
 		case "/stats", "/stats/":
 			expvar.Handler().ServeHTTP(w, r)
+		case "/metrics", "/metrics/":
+			circonus.TelemetryHandler().ServeHTTP(w, r)
 		case "/health", "/health/":
 			w.WriteHeader(http.StatusOK)
 			fmt.Fprintln(w, "Alive")
+		case "/readyz", "/readyz/":
+			a.readyz(w, r)
+		case "/livez", "/livez/":
+			a.livez(w, r)
 		default:
 			http.NotFound(w, r)
 		}
@@ -65,6 +90,8 @@ func New() (*Agent, error) {
 		groupCtx:    gctx,
 		groupCancel: cancel,
 		clusters:    make(map[string]*cluster.Cluster),
+		runs:        make(map[string]clusterRun),
+		failed:      make(map[string]string),
 		signalCh:    make(chan os.Signal, 10),
 		logger:      log.With().Str("pkg", "agent").Logger(),
 	}
@@ -74,13 +101,19 @@ func New() (*Agent, error) {
 		return nil, err
 	}
 
-	var cfg *config.Config
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
 
-	if err := viper.Unmarshal(&cfg); err != nil {
-		return nil, fmt.Errorf("parsing config: %w", err)
+	a.probes, err = probeThresholds(cfg.Probes)
+	if err != nil {
+		return nil, err
 	}
 
 	a.logger.Info().
+		Str(keys.K8SMode, viper.GetString(keys.K8SMode)).
+		Bool(keys.K8SLeaderElect, viper.GetBool(keys.K8SLeaderElect)).
 		Bool(keys.K8SEnableAPIServer, viper.GetBool(keys.K8SEnableAPIServer)).
 		Bool(keys.K8SEnableCadvisorMetrics, viper.GetBool(keys.K8SEnableCadvisorMetrics)).
 		Bool(keys.K8SEnableDNSMetrics, viper.GetBool(keys.K8SEnableDNSMetrics)).
@@ -95,41 +128,28 @@ func New() (*Agent, error) {
 		Bool(keys.K8SIncludeContainers, viper.GetBool(keys.K8SIncludeContainers)).
 		Bool(keys.K8SIncludePods, viper.GetBool(keys.K8SIncludePods)).
 		Str(keys.K8SInterval, viper.GetString(keys.K8SInterval)).
+		Str(keys.K8SCollectorSchedules, viper.GetString(keys.K8SCollectorSchedules)).
 		Str(keys.CollectDeadline, viper.GetString(keys.CollectDeadline)).
 		Str(keys.SubmitDeadline, viper.GetString(keys.SubmitDeadline)).
 		Msg("collection configuration")
 
-	// Set the hidden settings based on viper
-	cfg.Circonus.Base64Tags = defaults.Base64Tags
-	if viper.GetBool(keys.NoBase64) {
-		cfg.Circonus.Base64Tags = false
-	}
-	cfg.Circonus.UseGZIP = defaults.UseGZIP
-	if viper.GetBool(keys.NoGZIP) {
-		cfg.Circonus.UseGZIP = false
-	}
-	cfg.Circonus.DryRun = viper.GetBool(keys.DryRun)
-	// cfg.Circonus.StreamMetrics = viper.GetBool(keys.StreamMetrics)
-	cfg.Circonus.LogAgentMetrics = viper.GetBool(keys.LogAgentMetrics)
-
-	cfg.Circonus.NodeCC = viper.GetBool(keys.NodeCC)
-
 	if len(cfg.Clusters) > 0 { // multiple clusters
 		for _, clusterConfig := range cfg.Clusters {
-			clusterConfig := clusterConfig
-			c, err := cluster.New(gctx, clusterConfig, cfg.Circonus, a.logger)
+			c, run, err := a.newCluster(clusterConfig, cfg.Circonus)
 			if err != nil {
 				a.logger.Error().Err(err).Msg("configuring cluster, skipping...")
+				a.setFailed(clusterConfig.Name, err)
 				continue
 			}
-			a.clusters[clusterConfig.Name] = c
+			a.addCluster(clusterConfig.Name, c, run)
 		}
 	} else { // single cluster
-		c, err := cluster.New(gctx, cfg.Kubernetes, cfg.Circonus, a.logger)
+		c, run, err := a.newCluster(cfg.Kubernetes, cfg.Circonus)
 		if err != nil {
 			a.logger.Error().Err(err).Msg("configuring cluster")
+			a.setFailed(cfg.Kubernetes.Name, err)
 		} else {
-			a.clusters[cfg.Kubernetes.Name] = c
+			a.addCluster(cfg.Kubernetes.Name, c, run)
 		}
 	}
 
@@ -139,18 +159,27 @@ func New() (*Agent, error) {
 
 	a.signalNotifySetup()
 
+	if cfg.ConfigWatchInterval != "" {
+		watchInterval, err := time.ParseDuration(cfg.ConfigWatchInterval)
+		if err != nil {
+			return nil, fmt.Errorf("parsing config watch interval: %w", err)
+		}
+		a.watchInterval = watchInterval
+	}
+
+	srv, err := a.newServer(cfg.Server)
+	if err != nil {
+		return nil, err
+	}
+
 	go func() {
-		// _ = http.ListenAndServe(":6060", nil) // pprof
 		// NOTE: http://addr:8080/stats - application stats
+		//       http://addr:8080/metrics - agent self-telemetry (prometheus)
+		//       http://addr:8080/readyz - readiness probe
+		//       http://addr:8080/livez - liveness probe
 		//       http://addr:8080/health - liveness probe
-		srv := http.Server{
-			Addr:              ":8080",
-			WriteTimeout:      10 * time.Second,
-			ReadHeaderTimeout: 2 * time.Second,
-			ReadTimeout:       10 * time.Second,
-			Handler:           http.HandlerFunc(serveHTTP),
-		}
-		err := srv.ListenAndServe()
+		//       http://addr:8080/debug/pprof/ - pprof (when enabled)
+		err := serveInternal(srv, cfg.Server)
 		if !errors.Is(err, http.ErrServerClosed) {
 			log.Fatal().Err(err).Msg("internal http server exited")
 		}
@@ -163,11 +192,19 @@ func New() (*Agent, error) {
 func (a *Agent) Start() error {
 	a.group.Go(a.handleSignals)
 
-	for id := range a.clusters {
-		id := id
-		a.group.Go(func() error {
-			return a.clusters[id].Start(a.groupCtx)
-		})
+	if a.watchInterval > 0 {
+		a.group.Go(a.watchConfig)
+	}
+
+	a.clustersmu.RLock()
+	names := make([]string, 0, len(a.clusters))
+	for name := range a.clusters {
+		names = append(names, name)
+	}
+	a.clustersmu.RUnlock()
+
+	for _, name := range names {
+		a.startCluster(name, false)
 	}
 
 	log.Debug().
@@ -178,6 +215,31 @@ func (a *Agent) Start() error {
 	return a.group.Wait()
 }
 
+// loadConfig unmarshals the validated configuration and sets the hidden settings
+func loadConfig() (*config.Config, error) {
+	var cfg *config.Config
+
+	if err := viper.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+
+	// Set the hidden settings based on viper
+	cfg.Circonus.Base64Tags = defaults.Base64Tags
+	if viper.GetBool(keys.NoBase64) {
+		cfg.Circonus.Base64Tags = false
+	}
+	cfg.Circonus.UseGZIP = defaults.UseGZIP
+	if viper.GetBool(keys.NoGZIP) {
+		cfg.Circonus.UseGZIP = false
+	}
+	cfg.Circonus.DryRun = viper.GetBool(keys.DryRun)
+	cfg.Circonus.LogAgentMetrics = viper.GetBool(keys.LogAgentMetrics)
+
+	cfg.Circonus.NodeCC = viper.GetBool(keys.NodeCC)
+
+	return cfg, nil
+}
+
 // Stop cleans up and shuts down the Agent
 func (a *Agent) Stop() {
 	a.stopSignalHandler()
@@ -0,0 +1,122 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package agent
+
+import (
+	"context"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/cluster"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+)
+
+// clusterRun is the context a cluster runs in, cancelled to stop the cluster
+type clusterRun struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{} // closed when the cluster's Start returns, nil until started
+}
+
+// newCluster initializes a cluster, in its own context, using the circonus
+// configuration with the cluster's circonus overrides applied
+func (a *Agent) newCluster(clusterConfig config.Cluster, circCfg config.Circonus) (*cluster.Cluster, clusterRun, error) {
+	cc, err := config.ClusterCirconus(circCfg, clusterConfig)
+	if err != nil {
+		return nil, clusterRun{}, err
+	}
+
+	ctx, cancel := context.WithCancel(a.groupCtx)
+	c, err := cluster.New(ctx, clusterConfig, cc, a.logger)
+	if err != nil {
+		cancel()
+		return nil, clusterRun{}, err
+	}
+
+	return c, clusterRun{ctx: ctx, cancel: cancel}, nil
+}
+
+// addCluster adds an initialized cluster, stopping the cluster it replaces (restart).
+// The replaced cluster is stopped before returning, so the two never run together.
+func (a *Agent) addCluster(name string, c *cluster.Cluster, run clusterRun) {
+	a.clustersmu.Lock()
+	prev, prevRun := a.clusters[name], a.runs[name]
+	a.clusters[name] = c
+	a.runs[name] = run
+	delete(a.failed, name)
+	a.clustersmu.Unlock()
+
+	if prevRun.cancel != nil {
+		stopCluster(prev, prevRun)
+	}
+}
+
+// setFailed records a cluster which failed to initialize or start
+func (a *Agent) setFailed(name string, err error) {
+	a.clustersmu.Lock()
+	defer a.clustersmu.Unlock()
+	a.failed[name] = err.Error()
+}
+
+// removeCluster stops a cluster removed from the configuration
+func (a *Agent) removeCluster(name string) {
+	a.clustersmu.Lock()
+	c := a.clusters[name]
+	run, ok := a.runs[name]
+	delete(a.clusters, name)
+	delete(a.runs, name)
+	delete(a.failed, name)
+	a.clustersmu.Unlock()
+
+	if ok {
+		stopCluster(c, run)
+	}
+}
+
+// stopCluster cancels a cluster's run, waits for its Start to return (when started)
+// then releases the state it publishes. Not called with clustersmu held, a cluster
+// failing to start takes it.
+func stopCluster(c *cluster.Cluster, run clusterRun) {
+	run.cancel()
+	if run.done != nil {
+		<-run.done
+	}
+	if c != nil {
+		c.Close()
+	}
+}
+
+// startCluster runs a cluster in the agent's group. An error from a cluster started
+// with the agent stops the agent, a cluster added (or restarted) by a configuration
+// reload is marked failed instead and retried on the next reload.
+func (a *Agent) startCluster(name string, added bool) {
+	a.clustersmu.Lock()
+	c, run := a.clusters[name], a.runs[name]
+	if c == nil {
+		a.clustersmu.Unlock()
+		return
+	}
+	run.done = make(chan struct{})
+	a.runs[name] = run
+	a.clustersmu.Unlock()
+
+	a.group.Go(func() error {
+		err := c.Start(run.ctx)
+		close(run.done)
+		if err == nil || !added {
+			return err
+		}
+		a.logger.Error().Err(err).Str("cluster", name).Msg("starting cluster")
+		a.clustersmu.Lock()
+		if a.clusters[name] == c { // not replaced by a later reload
+			run.cancel()
+			c.Close()
+			delete(a.clusters, name)
+			delete(a.runs, name)
+			a.failed[name] = err.Error()
+		}
+		a.clustersmu.Unlock()
+		return nil
+	})
+}
@@ -0,0 +1,130 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package agent
+
+import (
+	"context"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/cluster"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/k8s"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/testsupport"
+	"github.com/rs/zerolog"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestClusterRestart(t *testing.T) {
+	t.Log("Testing cluster restart and removal")
+
+	kube := testsupport.NewKubeAPI("v1.24.3")
+	defer kube.Close()
+	defer k8s.SetClientFactory(kube.NewClient)()
+
+	api, err := testsupport.NewAPI()
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	defer api.Close()
+
+	cfg := *kube.ClusterConfig("test-cluster")
+	cfg.BearerToken = "test-bearer-token"
+	cfg.Interval = "1s"
+	cfg.LeaderElection = config.LeaderElection{
+		Enabled:       true,
+		Namespace:     "default",
+		LeaseName:     "test-lease",
+		LeaseDuration: "1s",
+		RenewDeadline: "500ms",
+		RetryPeriod:   "100ms",
+	}
+	circCfg := *api.CirconusConfig("test-cluster")
+	circCfg.CollectDeadline = "1s"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	g, gctx := errgroup.WithContext(ctx)
+
+	a := &Agent{
+		group:    g,
+		groupCtx: gctx,
+		clusters: make(map[string]*cluster.Cluster),
+		runs:     make(map[string]clusterRun),
+		failed:   make(map[string]string),
+		logger:   zerolog.Nop(),
+	}
+
+	leaderStats := expvar.Get("leader_election").(*expvar.Map)
+	published := func() bool {
+		for i := 0; i < 100; i++ {
+			if leaderStats.Get("test-cluster") != nil {
+				return true
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		return false
+	}
+	stopped := func(run clusterRun) bool {
+		select {
+		case <-run.done:
+			return true
+		default:
+			return false
+		}
+	}
+
+	c, run, err := a.newCluster(cfg, circCfg)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	a.addCluster("test-cluster", c, run)
+	a.startCluster("test-cluster", false)
+	if !published() {
+		t.Fatal("expected leader_election stats for test-cluster")
+	}
+	first := a.runs["test-cluster"]
+
+	t.Log("restart")
+	{
+		nc, nrun, err := a.newCluster(cfg, circCfg)
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		a.addCluster("test-cluster", nc, nrun)
+		if !stopped(first) {
+			t.Fatal("expected previous instance to be stopped")
+		}
+		if v := leaderStats.Get("test-cluster"); v != nil {
+			t.Fatalf("expected previous instance to be closed, got %v", v)
+		}
+		a.startCluster("test-cluster", true)
+		if !published() {
+			t.Fatal("expected leader_election stats for restarted test-cluster")
+		}
+	}
+
+	t.Log("remove")
+	{
+		second := a.runs["test-cluster"]
+		a.removeCluster("test-cluster")
+		if !stopped(second) {
+			t.Fatal("expected instance to be stopped")
+		}
+		if v := leaderStats.Get("test-cluster"); v != nil {
+			t.Fatalf("expected instance to be closed, got %v", v)
+		}
+		if len(a.clusters) != 0 || len(a.runs) != 0 {
+			t.Fatalf("expected no clusters, got %v", a.clusters)
+		}
+	}
+
+	cancel()
+	if err := g.Wait(); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/cluster"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+)
+
+// probeResponse is the body of the /readyz and /livez responses
+type probeResponse struct {
+	Clusters map[string]cluster.ProbeStatus `json:"clusters"`
+	Failed   map[string]string              `json:"failed_clusters,omitempty"` // clusters which failed to initialize
+	Status   string                         `json:"status"`
+}
+
+// probeThresholds parses the liveness probe thresholds
+func probeThresholds(cfg config.Probes) (cluster.ProbeThresholds, error) {
+	t := cluster.ProbeThresholds{MaxDeadlineTimeouts: cfg.MaxDeadlineTimeouts}
+
+	if cfg.MaxCollectAge != "" {
+		d, err := time.ParseDuration(cfg.MaxCollectAge)
+		if err != nil {
+			return t, fmt.Errorf("parsing probe max collect age %s: %w", cfg.MaxCollectAge, err)
+		}
+		t.MaxCollectAge = d
+	}
+
+	if cfg.MaxSubmitAge != "" {
+		d, err := time.ParseDuration(cfg.MaxSubmitAge)
+		if err != nil {
+			return t, fmt.Errorf("parsing probe max submit age %s: %w", cfg.MaxSubmitAge, err)
+		}
+		t.MaxSubmitAge = d
+	}
+
+	return t, nil
+}
+
+// readyz reports whether all clusters are ready, clusters which failed to initialize are not ready
+func (a *Agent) readyz(w http.ResponseWriter, _ *http.Request) {
+	clusters, failed := a.probeClusters()
+	resp := probeResponse{
+		Clusters: make(map[string]cluster.ProbeStatus, len(clusters)),
+		Failed:   failed,
+	}
+	ok := len(failed) == 0
+	for name, c := range clusters {
+		s := c.Ready()
+		resp.Clusters[name] = s
+		ok = ok && s.OK
+	}
+	writeProbe(w, resp, ok)
+}
+
+// livez reports whether all clusters are collecting and submitting within the probe thresholds
+func (a *Agent) livez(w http.ResponseWriter, _ *http.Request) {
+	a.probemu.RLock()
+	t := a.probes
+	a.probemu.RUnlock()
+
+	clusters, _ := a.probeClusters()
+	resp := probeResponse{
+		Clusters: make(map[string]cluster.ProbeStatus, len(clusters)),
+	}
+	ok := true
+	for name, c := range clusters {
+		s := c.Live(t)
+		resp.Clusters[name] = s
+		ok = ok && s.OK
+	}
+	writeProbe(w, resp, ok)
+}
+
+// probeClusters returns a copy of the current and failed clusters, which change on configuration reloads
+func (a *Agent) probeClusters() (map[string]*cluster.Cluster, map[string]string) {
+	a.clustersmu.RLock()
+	defer a.clustersmu.RUnlock()
+
+	clusters := make(map[string]*cluster.Cluster, len(a.clusters))
+	for name, c := range a.clusters {
+		clusters[name] = c
+	}
+	var failed map[string]string
+	if len(a.failed) > 0 {
+		failed = make(map[string]string, len(a.failed))
+		for name, err := range a.failed {
+			failed[name] = err
+		}
+	}
+
+	return clusters, failed
+}
+
+func writeProbe(w http.ResponseWriter, resp probeResponse, ok bool) {
+	code := http.StatusOK
+	resp.Status = "ok"
+	if !ok {
+		code = http.StatusServiceUnavailable
+		resp.Status = "failed"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,213 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package agent
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config/keys"
+	"github.com/spf13/viper"
+)
+
+// reload re-reads the configuration file, validates it and passes each cluster its
+// new configuration, applied at the start of their next collection. Clusters added to
+// the configuration (or which previously failed to initialize) are started, clusters
+// removed are stopped and clusters with changed client or check settings are restarted.
+func (a *Agent) reload() error {
+	a.reloadmu.Lock()
+	defer a.reloadmu.Unlock()
+
+	if viper.ConfigFileUsed() != "" {
+		if err := viper.ReadInConfig(); err != nil {
+			return fmt.Errorf("reading config file %s: %w", viper.ConfigFileUsed(), err)
+		}
+	}
+
+	// clear the derived collect deadline so it follows a changed collection interval
+	viper.Set(keys.CollectDeadline, nil)
+
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	probes, err := probeThresholds(cfg.Probes)
+	if err != nil {
+		return err
+	}
+	a.probemu.Lock()
+	a.probes = probes
+	a.probemu.Unlock()
+
+	clusters := cfg.Clusters
+	if len(clusters) == 0 {
+		clusters = []config.Cluster{cfg.Kubernetes}
+	}
+
+	seen := make(map[string]bool)
+	added := []string{}
+	for _, clusterConfig := range clusters {
+		name := clusterConfig.Name
+		seen[name] = true
+
+		a.clustersmu.RLock()
+		c, ok := a.clusters[name]
+		a.clustersmu.RUnlock()
+
+		if ok {
+			cc, err := config.ClusterCirconus(cfg.Circonus, clusterConfig)
+			if err != nil {
+				a.logger.Error().Err(err).Str("cluster", name).Msg("reloading cluster configuration, keeping current configuration")
+				continue
+			}
+			restart := c.RestartRequired(clusterConfig, cc)
+			if len(restart) == 0 {
+				if err := c.Reload(clusterConfig, cc); err != nil {
+					a.logger.Error().Err(err).Str("cluster", name).Msg("reloading cluster configuration, keeping current configuration")
+				}
+				continue
+			}
+			a.logger.Info().Str("cluster", name).Strs("settings", restart).Msg("changed settings require a restart, restarting cluster")
+		} else {
+			a.logger.Info().Str("cluster", name).Msg("new cluster, starting")
+		}
+
+		// new, previously failed or restarted cluster, a cluster being restarted
+		// keeps running if its new configuration fails to initialize
+		nc, run, err := a.newCluster(clusterConfig, cfg.Circonus)
+		if err != nil {
+			a.logger.Error().Err(err).Str("cluster", name).Msg("configuring cluster")
+			if !ok {
+				a.setFailed(name, err)
+			}
+			continue
+		}
+		a.addCluster(name, nc, run)
+		added = append(added, name)
+	}
+
+	a.clustersmu.RLock()
+	removed := []string{}
+	for name := range a.clusters {
+		if !seen[name] {
+			removed = append(removed, name)
+		}
+	}
+	for name := range a.failed {
+		if !seen[name] {
+			removed = append(removed, name)
+		}
+	}
+	a.clustersmu.RUnlock()
+
+	for _, name := range removed {
+		a.logger.Info().Str("cluster", name).Msg("cluster removed from configuration, stopping")
+		a.removeCluster(name)
+	}
+
+	for _, name := range added {
+		a.startCluster(name, true)
+	}
+
+	return nil
+}
+
+// watchedFiles returns the configuration file and the files referenced by the configuration.
+// Bearer token files are not watched, rotated tokens are picked up by k8s.TokenSource
+// without reloading (and re-applying the check configuration of) every cluster.
+func watchedFiles() []string {
+	files := []string{
+		viper.ConfigFileUsed(),
+		viper.GetString(keys.MetricFiltersFile),
+		viper.GetString(keys.DefaultAlertsFile),
+		viper.GetString(keys.CustomRulesFile),
+		viper.GetString(keys.K8SDynamicCollectorFile),
+	}
+
+	var clusters []config.Cluster
+	if err := viper.UnmarshalKey(keys.K8SClusters, &clusters); err == nil {
+		for _, c := range clusters {
+			files = append(files, c.DynamicCollectorFile)
+			cc, err := config.ClusterCirconus(config.Circonus{}, c)
+			if err != nil {
+				continue
+			}
+			files = append(files, cc.MetricFiltersFile, cc.DefaultAlertsFile, cc.CustomRulesFile)
+		}
+	}
+
+	return files
+}
+
+// watchedFiles returns the watched files, viper is not safe for concurrent use so
+// the configuration is not read while a reload (e.g. on SIGHUP) is re-reading it
+func (a *Agent) watchedFiles() []string {
+	a.reloadmu.Lock()
+	defer a.reloadmu.Unlock()
+	return watchedFiles()
+}
+
+// fileHashes returns the sha256 of each file's contents, missing files hash to an
+// empty string so that adding or removing a file is detected as a change
+func fileHashes(files []string) map[string]string {
+	hashes := make(map[string]string)
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			hashes[file] = ""
+			continue
+		}
+		hashes[file] = fmt.Sprintf("%x", sha256.Sum256(data))
+	}
+	return hashes
+}
+
+// watchConfig polls the configuration file and the files it references (metric
+// filters, alerts, rules and dynamic collectors), reloading when any of them
+// change. Polling by content works with ConfigMap volumes, where files are
+// replaced by swapping a symlink rather than being written in place.
+func (a *Agent) watchConfig() error {
+	a.logger.Info().Str("interval", a.watchInterval.String()).Msg("watching configuration files for changes")
+
+	hashes := fileHashes(a.watchedFiles())
+
+	ticker := time.NewTicker(a.watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.groupCtx.Done():
+			return nil
+		case <-ticker.C:
+			current := fileHashes(a.watchedFiles())
+			changed := []string{}
+			for file, hash := range current {
+				if prev, ok := hashes[file]; !ok || prev != hash {
+					changed = append(changed, file)
+				}
+			}
+			hashes = current
+			if len(changed) == 0 {
+				continue
+			}
+			a.logger.Info().Strs("files", changed).Msg("configuration files changed, reloading")
+			if err := a.reload(); err != nil {
+				a.logger.Error().Err(err).Msg("reloading configuration, keeping current configuration")
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package agent
+
+import (
+	"testing"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config/keys"
+	"github.com/spf13/viper"
+)
+
+func TestWatchedFiles(t *testing.T) {
+	t.Log("Testing watchedFiles")
+
+	viper.Set(keys.MetricFiltersFile, "/etc/filters.json")
+	viper.Set(keys.K8SBearerTokenFile, "/var/run/secrets/token")
+	viper.Set(keys.K8SClusters, []map[string]interface{}{
+		{
+			"name":                   "c1",
+			"bearer_token_file":      "/var/run/secrets/c1-token",
+			"dynamic_collector_file": "/etc/c1-collectors.json",
+			"circonus":               map[string]interface{}{"metric_filters_file": "/etc/c1-filters.json"},
+		},
+	})
+	defer viper.Reset()
+
+	files := make(map[string]bool)
+	for _, file := range watchedFiles() {
+		files[file] = true
+	}
+
+	for _, expect := range []string{"/etc/filters.json", "/etc/c1-collectors.json", "/etc/c1-filters.json"} {
+		if !files[expect] {
+			t.Fatalf("expected %s to be watched, got %v", expect, files)
+		}
+	}
+	for _, token := range []string{"/var/run/secrets/token", "/var/run/secrets/c1-token"} {
+		if files[token] {
+			t.Fatalf("expected token file %s not to be watched", token)
+		}
+	}
+}
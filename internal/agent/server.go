@@ -0,0 +1,142 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package agent
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config/defaults"
+	"github.com/pkg/errors"
+)
+
+// newServer configures the internal http server (stats, metrics, probes and optionally pprof)
+func (a *Agent) newServer(cfg config.Server) (*http.Server, error) {
+	listen := cfg.Listen
+	if listen == "" {
+		listen = defaults.ServerListen
+	}
+
+	auth := &authHandler{next: http.HandlerFunc(a.serveHTTP)}
+
+	token := cfg.BearerToken
+	if token == "" && cfg.BearerTokenFile != "" {
+		data, err := os.ReadFile(cfg.BearerTokenFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading server bearer token file")
+		}
+		token = strings.TrimSpace(string(data))
+		if token == "" {
+			return nil, errors.Errorf("server bearer token file %s is empty", cfg.BearerTokenFile)
+		}
+	}
+	auth.token = token
+
+	srv := &http.Server{
+		Addr:              listen,
+		WriteTimeout:      10 * time.Second,
+		ReadHeaderTimeout: 2 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		Handler:           auth,
+	}
+
+	if cfg.EnablePprof {
+		a.pprof = true
+		srv.WriteTimeout = 90 * time.Second // cpu profiles default to 30s
+	}
+
+	if cfg.TLSCertFile != "" {
+		srv.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		if cfg.ClientCAFile != "" {
+			cert, err := os.ReadFile(cfg.ClientCAFile)
+			if err != nil {
+				return nil, errors.Wrap(err, "reading server client ca file")
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(cert) {
+				return nil, errors.Errorf("unable to add server client ca file %s", cfg.ClientCAFile)
+			}
+			srv.TLSConfig.ClientCAs = pool
+			// probes (e.g. kubelet) do not present client certificates, the
+			// certificate is required by authHandler for all other endpoints
+			srv.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+			auth.clientCert = true
+		}
+	}
+
+	a.logger.Info().
+		Str("listen", listen).
+		Bool("tls", srv.TLSConfig != nil).
+		Bool("client_cert_auth", auth.clientCert).
+		Bool("token_auth", auth.token != "").
+		Bool("pprof", a.pprof).
+		Msg("internal http server")
+
+	return srv, nil
+}
+
+// serveInternal runs the internal http server
+func serveInternal(srv *http.Server, cfg config.Server) error {
+	if cfg.TLSCertFile != "" {
+		return srv.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+	}
+	return srv.ListenAndServe()
+}
+
+// servePprof serves the go pprof endpoints under /debug/pprof/
+func servePprof(w http.ResponseWriter, r *http.Request) {
+	switch strings.TrimPrefix(r.URL.Path, "/debug/pprof/") {
+	case "cmdline":
+		pprof.Cmdline(w, r)
+	case "profile":
+		pprof.Profile(w, r)
+	case "symbol":
+		pprof.Symbol(w, r)
+	case "trace":
+		pprof.Trace(w, r)
+	default:
+		pprof.Index(w, r) // index and named profiles (heap, goroutine, etc.)
+	}
+}
+
+// authHandler requires a bearer token and/or verified client certificate,
+// except for the probe endpoints
+type authHandler struct {
+	next       http.Handler
+	token      string
+	clientCert bool
+}
+
+func (h *authHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/health", "/health/", "/livez", "/livez/", "/readyz", "/readyz/":
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	if h.clientCert && (r.TLS == nil || len(r.TLS.VerifiedChains) == 0) {
+		http.Error(w, "client certificate required", http.StatusUnauthorized)
+		return
+	}
+
+	if h.token != "" {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	h.next.ServeHTTP(w, r)
+}
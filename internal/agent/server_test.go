@@ -0,0 +1,132 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package agent
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+	"github.com/rs/zerolog"
+)
+
+func TestAuthHandler(t *testing.T) {
+	t.Log("Testing authHandler")
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	get := func(h http.Handler, path, token string, tlsState *tls.ConnectionState) int {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		r.TLS = tlsState
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	t.Log("bearer token")
+	{
+		h := &authHandler{next: next, token: "secret"}
+		if code := get(h, "/stats", "", nil); code != http.StatusUnauthorized {
+			t.Fatalf("expected 401, got %d", code)
+		}
+		if code := get(h, "/stats", "wrong", nil); code != http.StatusUnauthorized {
+			t.Fatalf("expected 401, got %d", code)
+		}
+		if code := get(h, "/stats", "secret", nil); code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", code)
+		}
+		for _, path := range []string{"/health", "/livez", "/readyz"} {
+			if code := get(h, path, "", nil); code != http.StatusOK {
+				t.Fatalf("expected 200 for %s, got %d", path, code)
+			}
+		}
+	}
+
+	t.Log("client certificate")
+	{
+		h := &authHandler{next: next, clientCert: true}
+		if code := get(h, "/metrics", "", nil); code != http.StatusUnauthorized {
+			t.Fatalf("expected 401, got %d", code)
+		}
+		if code := get(h, "/metrics", "", &tls.ConnectionState{}); code != http.StatusUnauthorized {
+			t.Fatalf("expected 401, got %d", code)
+		}
+		verified := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{&x509.Certificate{}}}}
+		if code := get(h, "/metrics", "", verified); code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", code)
+		}
+		if code := get(h, "/readyz", "", nil); code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", code)
+		}
+	}
+
+	t.Log("no auth")
+	{
+		h := &authHandler{next: next}
+		if code := get(h, "/stats", "", nil); code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", code)
+		}
+	}
+}
+
+func TestNewServer(t *testing.T) {
+	t.Log("Testing newServer")
+
+	a := &Agent{logger: zerolog.Nop()}
+
+	t.Log("defaults")
+	{
+		srv, err := a.newServer(config.Server{})
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if srv.Addr != ":8080" {
+			t.Fatalf("expected :8080, got %s", srv.Addr)
+		}
+		if srv.TLSConfig != nil {
+			t.Fatal("expected no tls config")
+		}
+	}
+
+	t.Log("token file")
+	{
+		file := filepath.Join(t.TempDir(), "token")
+		if err := os.WriteFile(file, []byte("secret\n"), 0600); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		srv, err := a.newServer(config.Server{Listen: "127.0.0.1:9090", BearerTokenFile: file, EnablePprof: true})
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if srv.Addr != "127.0.0.1:9090" {
+			t.Fatalf("expected 127.0.0.1:9090, got %s", srv.Addr)
+		}
+		if tok := srv.Handler.(*authHandler).token; tok != "secret" {
+			t.Fatalf("expected secret, got %q", tok)
+		}
+		if !a.pprof {
+			t.Fatal("expected pprof enabled")
+		}
+	}
+
+	t.Log("missing client ca file")
+	{
+		_, err := a.newServer(config.Server{TLSCertFile: "cert.pem", TLSKeyFile: "key.pem", ClientCAFile: filepath.Join(t.TempDir(), "missing")})
+		if err == nil {
+			t.Fatal("expected error")
+		}
+	}
+}
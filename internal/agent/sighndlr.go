@@ -34,7 +34,11 @@ func (a *Agent) handleSignals() error {
 			switch sig {
 			case os.Interrupt, unix.SIGTERM:
 				a.Stop()
-			case unix.SIGPIPE, unix.SIGHUP:
+			case unix.SIGHUP:
+				if err := a.reload(); err != nil {
+					log.Error().Err(err).Msg("reloading configuration, keeping current configuration")
+				}
+			case unix.SIGPIPE:
 				// Noop
 			case unix.SIGTRAP:
 				stacklen := runtime.Stack(buf, true)
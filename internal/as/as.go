@@ -19,35 +19,40 @@ import (
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/circonus"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config/defaults"
-	"github.com/circonus-labs/circonus-kubernetes-agent/internal/k8s"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/promtext"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/release"
 	"github.com/pkg/errors"
 	"github.com/prometheus/common/expfmt"
 	"github.com/rs/zerolog"
+	"k8s.io/client-go/kubernetes"
 )
 
 type AS struct {
 	sync.Mutex
 	config       *config.Cluster
 	check        *circonus.Check
+	clientset    kubernetes.Interface
 	log          zerolog.Logger
 	apiTimelimit time.Duration
 	running      bool
 }
 
-func New(cfg *config.Cluster, parentLog zerolog.Logger, check *circonus.Check) (*AS, error) {
+func New(cfg *config.Cluster, parentLog zerolog.Logger, check *circonus.Check, clientset kubernetes.Interface) (*AS, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid cluster config (nil)")
 	}
 	if check == nil {
 		return nil, errors.New("invalid check (nil)")
 	}
+	if clientset == nil {
+		return nil, errors.New("invalid clientset (nil)")
+	}
 
 	as := &AS{
-		config: cfg,
-		check:  check,
-		log:    parentLog.With().Str("collector", "api-server").Logger(),
+		config:    cfg,
+		check:     check,
+		clientset: clientset,
+		log:       parentLog.With().Str("collector", "api-server").Logger(),
 	}
 
 	if cfg.APITimelimit != "" {
@@ -95,14 +100,8 @@ func (as *AS) Collect(ctx context.Context, tlsConfig *tls.Config, ts *time.Time)
 
 	collectStart := time.Now()
 
-	clientset, err := k8s.GetClient(as.config)
-	if err != nil {
-		as.log.Error().Err(err).Msg("initializing client set")
-		return
-	}
-
 	start := time.Now()
-	req := clientset.CoreV1().RESTClient().Get().RequestURI("/metrics")
+	req := as.clientset.CoreV1().RESTClient().Get().RequestURI("/metrics")
 	res := req.Do(ctx)
 
 	data, err := res.Raw()
@@ -13,13 +13,11 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config/keys"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/release"
 	apiclient "github.com/circonus-labs/go-apiclient"
 	"github.com/hashicorp/go-version"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
-	"github.com/spf13/viper"
 )
 
 const defaultRuleSetsStr119 = `
@@ -760,9 +758,8 @@ type CustomRules struct {
 	Rules []apiclient.RuleSet `json:"rules"`
 }
 
-func initializeAlerting(client *apiclient.API, logger zerolog.Logger, clusterName, clusterTag, clusterVers, checkCID, checkUUID string) {
-	configFile := viper.GetString(keys.DefaultAlertsFile)
-	data, err := os.ReadFile(configFile)
+func initializeAlerting(client *apiclient.API, logger zerolog.Logger, alertsFile, rulesFile, clusterName, clusterTag, clusterVers, checkCID, checkUUID string) {
+	data, err := os.ReadFile(alertsFile)
 	if err != nil {
 		logger.Warn().Err(err).Msg("skipping")
 		return
@@ -793,7 +790,7 @@ func initializeAlerting(client *apiclient.API, logger zerolog.Logger, clusterNam
 	}
 
 	// create custom rules
-	if err := createCustomRules(client, logger, clusterName, clusterTag, checkCID); err != nil {
+	if err := createCustomRules(client, logger, rulesFile, clusterName, clusterTag, checkCID); err != nil {
 		logger.Error().Err(err).Msg("alerting custom rules")
 		return
 	}
@@ -1082,15 +1079,14 @@ func manageDefaultRules(client *apiclient.API, logger zerolog.Logger, da Default
 	return nil
 }
 
-func createCustomRules(client *apiclient.API, logger zerolog.Logger, clusterName, clusterTag, checkCID string) error {
+func createCustomRules(client *apiclient.API, logger zerolog.Logger, rulesFile, clusterName, clusterTag, checkCID string) error {
 	logger.Debug().Msg("create custom alerting rules")
-	configFile := viper.GetString(keys.CustomRulesFile)
-	data, err := os.ReadFile(configFile)
+	data, err := os.ReadFile(rulesFile)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil
 		}
-		logger.Warn().Err(err).Str("custom_rule_config", configFile).Msg("loading")
+		logger.Warn().Err(err).Str("custom_rule_config", rulesFile).Msg("loading")
 		return err
 	}
 
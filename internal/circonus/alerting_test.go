@@ -0,0 +1,152 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package circonus
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/testsupport"
+	apiclient "github.com/circonus-labs/go-apiclient"
+	"github.com/rs/zerolog"
+)
+
+func TestInitializeAlerting(t *testing.T) {
+	t.Log("Testing initializeAlerting")
+
+	api, err := testsupport.NewAPI()
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	defer api.Close()
+
+	c := &Check{config: newTestCirconusConfig(api), log: zerolog.Nop()}
+	client, err := c.createAPIClient()
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	dir := t.TempDir()
+	alertsFile := filepath.Join(dir, "default-alerts.json")
+	rulesFile := filepath.Join(dir, "custom-rules.json")
+
+	const (
+		clusterName = "test-cluster"
+		clusterTag  = "cluster:test-cluster"
+		clusterVers = "v1.24.3"
+		checkCID    = "/check/1234"
+		checkUUID   = "00000000-0000-4000-8000-000000001234"
+	)
+
+	rules, err := defaultRules(clusterVers)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	t.Log("no alerts file")
+	{
+		initializeAlerting(client, zerolog.Nop(), alertsFile, rulesFile, clusterName, clusterTag, clusterVers, checkCID, checkUUID)
+		if n := len(api.Requests()); n != 0 {
+			t.Fatalf("expected no api requests, got %d", n)
+		}
+	}
+
+	t.Log("no contact")
+	{
+		if err := os.WriteFile(alertsFile, []byte(`{"contact":{}}`), 0600); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		initializeAlerting(client, zerolog.Nop(), alertsFile, rulesFile, clusterName, clusterTag, clusterVers, checkCID, checkUUID)
+		if n := len(api.Requests()); n != 0 {
+			t.Fatalf("expected no api requests, got %d", n)
+		}
+	}
+
+	t.Log("create contact group and default rules")
+	{
+		if err := os.WriteFile(alertsFile, []byte(`{"contact":{"email":"ops@example.com"},"rule_settings":{"cpu_utilization":{"threshold":"80"},"crashloops_container":{"disabled":true}}}`), 0600); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		initializeAlerting(client, zerolog.Nop(), alertsFile, rulesFile, clusterName, clusterTag, clusterVers, checkCID, checkUUID)
+
+		cgs := api.ContactGroups()
+		if len(cgs) != 1 {
+			t.Fatalf("expected 1 contact group, got %d", len(cgs))
+		}
+		if cgs[0].Name != clusterName+" default alerts" || cgs[0].Contacts.External[0].Info != "ops@example.com" {
+			t.Fatalf("unexpected contact group %#v", cgs[0])
+		}
+
+		ruleSets := api.RuleSets()
+		if len(ruleSets) != len(rules)-1 {
+			t.Fatalf("expected %d rule sets, got %d", len(rules)-1, len(ruleSets))
+		}
+		for _, rs := range ruleSets {
+			if rs.CheckCID != checkCID {
+				t.Fatalf("expected check %s, got %s", checkCID, rs.CheckCID)
+			}
+			if strings.Contains(rs.Name, "{cluster_name}") {
+				t.Fatalf("expected cluster name in rule name, got %s", rs.Name)
+			}
+			if len(rs.ContactGroups[1]) != 1 || rs.ContactGroups[1][0] != cgs[0].CID {
+				t.Fatalf("expected contact group %s, got %v", cgs[0].CID, rs.ContactGroups)
+			}
+			if rs.MetricName == "node_cpu_usage_seconds_total" && rs.Rules[1].Value != "80" {
+				t.Fatalf("expected cpu threshold 80, got %v", rs.Rules[1].Value)
+			}
+		}
+	}
+
+	t.Log("existing contact group and rules")
+	{
+		initializeAlerting(client, zerolog.Nop(), alertsFile, rulesFile, clusterName, clusterTag, clusterVers, checkCID, checkUUID)
+
+		if n := len(api.ContactGroups()); n != 1 {
+			t.Fatalf("expected 1 contact group, got %d", n)
+		}
+		if n := len(api.RuleSets()); n != len(rules)-1 {
+			t.Fatalf("expected %d rule sets, got %d", len(rules)-1, n)
+		}
+		if n := api.RequestCount("PUT /rule_set/"); n != 0 {
+			t.Fatalf("expected unchanged rules to not be updated, got %d updates", n)
+		}
+	}
+
+	t.Log("custom rules and configured contact group")
+	{
+		cg := api.AddContactGroup(apiclient.ContactGroup{Name: "ops"})
+		if err := os.WriteFile(alertsFile, []byte(`{"contact":{"group_cid":"`+cg.CID+`"}}`), 0600); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if err := os.WriteFile(rulesFile, []byte(`{"rules":[{"name":"custom ({cluster_name})","metric_name":"foo","metric_type":"numeric","rules":[{"criteria":"max value","severity":1,"value":"10"}]}]}`), 0600); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		before := len(api.RuleSets())
+		initializeAlerting(client, zerolog.Nop(), alertsFile, rulesFile, clusterName, clusterTag, clusterVers, checkCID, checkUUID)
+
+		if n := api.RequestCount("GET " + cg.CID); n != 1 {
+			t.Fatalf("expected contact group to be fetched, got %d", n)
+		}
+		found := false
+		for _, rs := range api.RuleSets() {
+			if rs.Name == "custom (test-cluster)" {
+				found = true
+				if rs.CheckCID != checkCID {
+					t.Fatalf("expected check %s, got %s", checkCID, rs.CheckCID)
+				}
+			}
+		}
+		if !found {
+			t.Fatal("expected custom rule to be created")
+		}
+		// the custom rule and the previously disabled default rule
+		if n := len(api.RuleSets()); n != before+2 {
+			t.Fatalf("expected %d rule sets, got %d", before+2, n)
+		}
+	}
+}
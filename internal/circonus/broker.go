@@ -16,9 +16,11 @@ import (
 	"strings"
 
 	apiclient "github.com/circonus-labs/go-apiclient"
+	apiclicfg "github.com/circonus-labs/go-apiclient/config"
 )
 
-// initializeBroker fetches broker from circonus api and sets up broker tls config
+// initializeBroker fetches the broker(s) from circonus api and sets up a
+// submission target, with its own tls config, for each broker in the bundle
 func (c *Check) initializeBroker(client *apiclient.API, bundle *apiclient.CheckBundle) error {
 	if client == nil {
 		return fmt.Errorf("invalid state (nil api client)")
@@ -30,31 +32,96 @@ func (c *Check) initializeBroker(client *apiclient.API, bundle *apiclient.CheckB
 		return fmt.Errorf("invalid bundle, 0 brokers")
 	}
 
-	if strings.Contains(c.submissionURL, "api.circonus.com") {
-		return nil // api.circonus.com uses a public certificate, no tls config needed
+	targets := []*brokerTarget{{cid: bundle.Brokers[0], submissionURL: c.submissionURL, checkUUID: c.checkUUID}}
+	if len(bundle.Brokers) > 1 {
+		t, err := c.brokerTargets(client, bundle)
+		if err != nil {
+			return err
+		}
+		targets = t
 	}
 
-	cid := bundle.Brokers[0]
-	broker, err := client.FetchBroker(apiclient.CIDType(&cid))
-	if err != nil {
-		return fmt.Errorf("fetching broker: %w", err)
+	var caCert []byte
+	for _, target := range targets {
+		if strings.Contains(target.submissionURL, "api.circonus.com") {
+			continue // api.circonus.com uses a public certificate, no tls config needed
+		}
+
+		cid := target.cid
+		broker, err := client.FetchBroker(apiclient.CIDType(&cid))
+		if err != nil {
+			return fmt.Errorf("fetching broker: %w", err)
+		}
+
+		cn, cnList, err := getBrokerCNList(broker, target.submissionURL)
+		if err != nil {
+			return err
+		}
+
+		if caCert == nil {
+			data, err := getCACert(c.config.Check.BrokerCAFile, client)
+			if err != nil {
+				return err
+			}
+			caCert = data
+		}
+
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caCert) {
+			return fmt.Errorf("unable to add Broker CA Certificate to x509 cert pool")
+		}
+		target.tlsConfig = c.newBrokerTLSConfig(cn, cnList, certPool)
 	}
 
-	cn, cnList, err := getBrokerCNList(broker, c.submissionURL)
-	if err != nil {
-		return err
+	c.brokerTLSConfig = primaryBrokerTarget(targets, bundle.Brokers[0], c.submissionURL).tlsConfig
+	c.setBrokerTargets(targets)
+
+	return nil
+}
+
+// primaryBrokerTarget returns the target for the bundle's primary broker, the
+// one submissionURL belongs to, falling back to the first target
+func primaryBrokerTarget(targets []*brokerTarget, brokerCID, submissionURL string) *brokerTarget {
+	for _, target := range targets {
+		if target.cid == brokerCID {
+			return target
+		}
 	}
+	for _, target := range targets {
+		if target.submissionURL == submissionURL {
+			return target
+		}
+	}
+	return targets[0]
+}
 
-	data, err := getCACert(c.config.Check.BrokerCAFile, client)
-	if err != nil {
-		return err
+// brokerTargets returns a submission target for each check (one per broker) in a bundle
+func (c *Check) brokerTargets(client *apiclient.API, bundle *apiclient.CheckBundle) ([]*brokerTarget, error) {
+	targets := make([]*brokerTarget, 0, len(bundle.Checks))
+	for _, checkCID := range bundle.Checks {
+		cid := checkCID
+		check, err := client.FetchCheck(apiclient.CIDType(&cid))
+		if err != nil {
+			return nil, fmt.Errorf("fetching check (%s): %w", cid, err)
+		}
+		surl := check.Details[apiclicfg.SubmissionURL]
+		if surl == "" {
+			c.log.Warn().Str("check", cid).Str("broker", check.BrokerCID).Msg("check has no submission url, skipping broker")
+			continue
+		}
+		targets = append(targets, &brokerTarget{cid: check.BrokerCID, submissionURL: surl, checkUUID: check.CheckUUID})
 	}
 
-	certPool := x509.NewCertPool()
-	if !certPool.AppendCertsFromPEM(data) {
-		return fmt.Errorf("unable to add Broker CA Certificate to x509 cert pool")
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("invalid bundle, no checks with submission urls")
 	}
-	c.brokerTLSConfig = &tls.Config{
+
+	return targets, nil
+}
+
+// newBrokerTLSConfig creates a tls config verifying the broker certificate against the ca and broker cn list
+func (c *Check) newBrokerTLSConfig(cn, cnList string, certPool *x509.CertPool) *tls.Config {
+	return &tls.Config{
 		MinVersion: tls.VersionTLS12,
 		ServerName: cn,
 		// go1.15+ see VerifyConnection below - until CN added to SAN in broker certs
@@ -85,8 +152,6 @@ func (c *Check) initializeBroker(client *apiclient.API, bundle *apiclient.CheckB
 			return nil
 		},
 	}
-
-	return nil
 }
 
 // getCACert will read from a file or fetch from API
@@ -0,0 +1,244 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package circonus
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cgm "github.com/circonus-labs/circonus-gometrics/v3"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config/defaults"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/release"
+)
+
+const (
+	// brokerHealthyScore is the minimum score for a broker to be considered healthy
+	brokerHealthyScore = 0.5
+	// brokerScoreDecay weights the previous score when updating a broker's health score
+	brokerScoreDecay = 0.7
+	// brokerMaxBackoff is the maximum time a failing broker is skipped
+	brokerMaxBackoff = 60 * time.Second
+)
+
+// brokerTarget is a broker submission url, with its tls config, client and health.
+// Each target has a shared, keep-alive, http client - connections are reused across
+// submissions and, when the broker negotiates it via ALPN, multiplexed over HTTP/2.
+type brokerTarget struct {
+	sync.Mutex
+	tlsConfig     *tls.Config
+	client        *http.Client
+	retryAfter    time.Time
+	cid           string
+	submissionURL string
+	checkUUID     string
+	score         float64
+	failures      int
+}
+
+// setBrokerTargets sets the brokers metrics are submitted to, the first target is the primary broker
+func (c *Check) setBrokerTargets(targets []*brokerTarget) {
+	c.clientmu.Lock()
+	defer c.clientmu.Unlock()
+
+	for _, t := range targets {
+		t.score = 1
+		t.client = &http.Client{
+			Transport: c.newBrokerTransport(t.tlsConfig),
+			Timeout:   60 * time.Second, // hard 60s timeout
+		}
+	}
+	c.brokers = targets
+}
+
+// brokerTargetList returns the configured broker targets, creating a single target
+// from the submission url if brokers have not been initialized
+func (c *Check) brokerTargetList() []*brokerTarget {
+	c.clientmu.Lock()
+	targets := c.brokers
+	c.clientmu.Unlock()
+
+	if len(targets) == 0 {
+		c.setBrokerTargets([]*brokerTarget{{submissionURL: c.submissionURL, checkUUID: c.checkUUID, tlsConfig: c.brokerTLSConfig}})
+		c.clientmu.Lock()
+		targets = c.brokers
+		c.clientmu.Unlock()
+	}
+
+	return targets
+}
+
+// brokerOrder returns the broker targets in the order submissions should be attempted.
+// Brokers in backoff are tried last. Healthy brokers are ordered by health score or,
+// when round robin is enabled, rotated so flushes are spread across them.
+func (c *Check) brokerOrder() []*brokerTarget {
+	targets := c.brokerTargetList()
+	if len(targets) == 1 {
+		return targets
+	}
+
+	now := time.Now()
+	var healthy, degraded, backoff []*brokerTarget
+	for _, t := range targets {
+		t.Lock()
+		switch {
+		case now.Before(t.retryAfter):
+			backoff = append(backoff, t)
+		case t.score >= brokerHealthyScore:
+			healthy = append(healthy, t)
+		default:
+			degraded = append(degraded, t)
+		}
+		t.Unlock()
+	}
+
+	if c.config.Broker.RoundRobin && len(healthy) > 1 {
+		n := int(atomic.AddUint64(&c.brokerNext, 1)-1) % len(healthy)
+		healthy = append(healthy[n:], healthy[:n]...)
+	} else {
+		sortBrokersByScore(healthy)
+	}
+	sortBrokersByScore(degraded)
+	sort.SliceStable(backoff, func(i, j int) bool {
+		backoff[i].Lock()
+		defer backoff[i].Unlock()
+		backoff[j].Lock()
+		defer backoff[j].Unlock()
+		return backoff[i].retryAfter.Before(backoff[j].retryAfter)
+	})
+
+	order := make([]*brokerTarget, 0, len(targets))
+	order = append(order, healthy...)
+	order = append(order, degraded...)
+	order = append(order, backoff...)
+
+	return order
+}
+
+// tags returns the tags used for the broker metrics
+func (t *brokerTarget) tags() cgm.Tags {
+	return cgm.Tags{
+		cgm.Tag{Category: "source", Value: release.NAME},
+		cgm.Tag{Category: "broker", Value: t.cid},
+	}
+}
+
+// sortBrokersByScore sorts brokers by descending health score, preserving the configured order for ties
+func sortBrokersByScore(targets []*brokerTarget) {
+	sort.SliceStable(targets, func(i, j int) bool {
+		targets[i].Lock()
+		defer targets[i].Unlock()
+		targets[j].Lock()
+		defer targets[j].Unlock()
+		return targets[i].score > targets[j].score
+	})
+}
+
+// success records a successful submission to the broker
+func (t *brokerTarget) success() float64 {
+	t.Lock()
+	defer t.Unlock()
+	t.score = t.score*brokerScoreDecay + (1 - brokerScoreDecay)
+	t.failures = 0
+	t.retryAfter = time.Time{}
+	return t.score
+}
+
+// failure records a failed submission to the broker, the broker is skipped
+// (unless no other brokers are available) for an exponentially increasing backoff
+func (t *brokerTarget) failure() float64 {
+	t.Lock()
+	defer t.Unlock()
+	t.score *= brokerScoreDecay
+	t.failures++
+	backoff := brokerMaxBackoff
+	if t.failures < 7 {
+		backoff = time.Duration(1<<uint(t.failures-1)) * time.Second
+	}
+	t.retryAfter = time.Now().Add(backoff)
+	return t.score
+}
+
+// newBrokerTransport creates a keep-alive transport for submissions to a broker
+func (c *Check) newBrokerTransport(tlsConfig *tls.Config) *http.Transport {
+	maxIdle := int(c.config.Broker.MaxIdleConns)
+	if maxIdle == 0 {
+		maxIdle = defaults.BrokerMaxIdleConns
+	}
+	idleTimeout := c.brokerIdleTimeout
+	if idleTimeout == 0 {
+		idleTimeout, _ = time.ParseDuration(defaults.BrokerIdleConnTimeout)
+	}
+
+	t := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:       10 * time.Second,
+			KeepAlive:     30 * time.Second,
+			FallbackDelay: -1 * time.Millisecond,
+		}).DialContext,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: 10 * time.Second,
+		DisableCompression:  false,
+		MaxIdleConns:        maxIdle,
+		MaxIdleConnsPerHost: maxIdle,
+		IdleConnTimeout:     idleTimeout,
+		ForceAttemptHTTP2:   !c.config.Broker.DisableHTTP2,
+	}
+	if c.config.Broker.DisableHTTP2 {
+		// a non-nil, empty, map disables http/2
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	return t
+}
+
+// brokerClientTrace tracks connection reuse and tls handshakes for broker submissions
+func (c *Check) brokerClientTrace() *httptrace.ClientTrace {
+	var handshakeStart time.Time
+	return &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			state := "new"
+			if info.Reused {
+				state = "reused"
+			}
+			c.IncrementCounter("collect_broker_conns", cgm.Tags{
+				cgm.Tag{Category: "source", Value: release.NAME},
+				cgm.Tag{Category: "state", Value: state},
+			})
+		},
+		TLSHandshakeStart: func() {
+			handshakeStart = time.Now()
+		},
+		TLSHandshakeDone: func(cs tls.ConnectionState, err error) {
+			result := "ok"
+			if err != nil {
+				result = "error"
+			}
+			proto := cs.NegotiatedProtocol
+			if proto == "" {
+				proto = "http/1.1"
+			}
+			c.IncrementCounter("collect_broker_tls_handshakes", cgm.Tags{
+				cgm.Tag{Category: "source", Value: release.NAME},
+				cgm.Tag{Category: "result", Value: result},
+				cgm.Tag{Category: "proto", Value: proto},
+			})
+			if !handshakeStart.IsZero() {
+				c.AddHistSample("collect_latency", cgm.Tags{
+					cgm.Tag{Category: "type", Value: "tls_handshake"},
+					cgm.Tag{Category: "source", Value: release.NAME},
+					cgm.Tag{Category: "units", Value: "milliseconds"},
+				}, float64(time.Since(handshakeStart).Milliseconds()))
+			}
+		},
+	}
+}
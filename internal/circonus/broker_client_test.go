@@ -0,0 +1,200 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package circonus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+	"github.com/rs/zerolog"
+)
+
+// brokerServer is a fake broker counting submissions, failing while down is set
+type brokerServer struct {
+	*httptest.Server
+	sync.Mutex
+	submits int
+	down    bool
+	status  int
+}
+
+func newBrokerServer() *brokerServer {
+	b := &brokerServer{}
+	b.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b.Lock()
+		defer b.Unlock()
+		if b.down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if b.status != 0 {
+			w.WriteHeader(b.status)
+			return
+		}
+		b.submits++
+		fmt.Fprint(w, `{"stats":1}`)
+	}))
+	return b
+}
+
+func (b *brokerServer) count() int {
+	b.Lock()
+	defer b.Unlock()
+	return b.submits
+}
+
+func newBrokerTestCheck(roundRobin bool, brokers ...*brokerServer) *Check {
+	c := &Check{
+		config:         &config.Circonus{Broker: config.Broker{RoundRobin: roundRobin}},
+		log:            zerolog.Nop(),
+		submitDeadline: 5 * time.Second,
+		submissionURL:  brokers[0].URL,
+	}
+	targets := make([]*brokerTarget, len(brokers))
+	for i, b := range brokers {
+		targets[i] = &brokerTarget{cid: fmt.Sprintf("/broker/%d", i), submissionURL: b.URL}
+	}
+	c.setBrokerTargets(targets)
+	return c
+}
+
+func TestBrokerFailover(t *testing.T) {
+	t.Log("Testing broker failover")
+
+	b1 := newBrokerServer()
+	defer b1.Close()
+	b2 := newBrokerServer()
+	defer b2.Close()
+
+	b1.down = true
+	c := newBrokerTestCheck(false, b1, b2)
+
+	metrics := map[string]MetricSample{"foo": {Type: MetricTypeUint64, Value: uint64(1)}}
+
+	if err := c.submitMetrics(context.Background(), metrics, zerolog.Nop(), false); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if b2.count() != 1 {
+		t.Fatalf("expected submission to fail over to second broker, got %d", b2.count())
+	}
+
+	t.Log("failed broker in backoff")
+	{
+		order := c.brokerOrder()
+		if order[0].submissionURL != b2.URL {
+			t.Fatalf("expected second broker first, got %s", order[0].submissionURL)
+		}
+		if err := c.submitMetrics(context.Background(), metrics, zerolog.Nop(), false); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if b2.count() != 2 {
+			t.Fatalf("expected 2 submissions to second broker, got %d", b2.count())
+		}
+	}
+
+	t.Log("all brokers down")
+	{
+		b2.Lock()
+		b2.down = true
+		b2.Unlock()
+		if err := c.submitMetrics(context.Background(), metrics, zerolog.Nop(), false); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+}
+
+func TestBrokerFailoverAuth(t *testing.T) {
+	t.Log("Testing broker failover on auth/not found statuses")
+
+	for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound} {
+		b1 := newBrokerServer()
+		b2 := newBrokerServer()
+
+		b1.status = status
+		c := newBrokerTestCheck(false, b1, b2)
+
+		metrics := map[string]MetricSample{"foo": {Type: MetricTypeUint64, Value: uint64(1)}}
+
+		if err := c.submitMetrics(context.Background(), metrics, zerolog.Nop(), false); err != nil {
+			t.Fatalf("expected no error (%d), got %s", status, err)
+		}
+		if b2.count() != 1 {
+			t.Fatalf("expected submission to fail over to second broker (%d), got %d", status, b2.count())
+		}
+		if order := c.brokerOrder(); order[0].submissionURL != b2.URL {
+			t.Fatalf("expected first broker in backoff (%d)", status)
+		}
+
+		b1.Close()
+		b2.Close()
+	}
+}
+
+func TestBrokerRoundRobin(t *testing.T) {
+	t.Log("Testing broker round robin")
+
+	b1 := newBrokerServer()
+	defer b1.Close()
+	b2 := newBrokerServer()
+	defer b2.Close()
+
+	c := newBrokerTestCheck(true, b1, b2)
+
+	metrics := map[string]MetricSample{"foo": {Type: MetricTypeUint64, Value: uint64(1)}}
+	for i := 0; i < 4; i++ {
+		if err := c.submitMetrics(context.Background(), metrics, zerolog.Nop(), false); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+	}
+
+	if b1.count() != 2 || b2.count() != 2 {
+		t.Fatalf("expected 2 submissions to each broker, got %d/%d", b1.count(), b2.count())
+	}
+}
+
+func TestPrimaryBrokerTarget(t *testing.T) {
+	t.Log("Testing primary broker target selection")
+
+	targets := []*brokerTarget{
+		{cid: "/broker/2", submissionURL: "https://b2/trap"},
+		{cid: "/broker/1", submissionURL: "https://b1/trap"},
+	}
+
+	if bt := primaryBrokerTarget(targets, "/broker/1", "https://b1/trap"); bt != targets[1] {
+		t.Fatalf("expected /broker/1 target, got %s", bt.cid)
+	}
+	if bt := primaryBrokerTarget(targets, "/broker/3", "https://b1/trap"); bt != targets[1] {
+		t.Fatalf("expected target matching submission url, got %s", bt.cid)
+	}
+	if bt := primaryBrokerTarget(targets, "/broker/3", "https://b3/trap"); bt != targets[0] {
+		t.Fatalf("expected first target, got %s", bt.cid)
+	}
+}
+
+func TestBrokerHealthScore(t *testing.T) {
+	t.Log("Testing broker health score")
+
+	bt := &brokerTarget{score: 1}
+	if s := bt.failure(); s >= 1 {
+		t.Fatalf("expected score to drop, got %f", s)
+	}
+	if bt.retryAfter.IsZero() {
+		t.Fatal("expected backoff")
+	}
+	prev := bt.score
+	if s := bt.success(); s <= prev {
+		t.Fatalf("expected score to recover, got %f", s)
+	}
+	if !bt.retryAfter.IsZero() || bt.failures != 0 {
+		t.Fatal("expected backoff to be reset")
+	}
+}
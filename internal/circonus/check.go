@@ -15,13 +15,13 @@ import (
 	"encoding/json"
 	"fmt"
 	stdlog "log"
-	"net/http"
 	"os"
 	"regexp"
 	"strings"
 	"sync"
 	"time"
 
+	"code.cloudfoundry.org/bytefmt"
 	cgm "github.com/circonus-labs/circonus-gometrics/v3"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config/defaults"
@@ -46,12 +46,16 @@ type MetricFilter struct {
 }
 
 type Check struct {
+	ctx                  context.Context
 	statsmu              sync.Mutex
 	metricsmu            sync.Mutex
 	brokerTLSConfig      *tls.Config
 	config               *config.Circonus
-	client               *http.Client
+	clientmu             sync.Mutex
+	brokers              []*brokerTarget
+	brokerNext           uint64
 	metrics              *cgm.CirconusMetrics
+	metricCounters       map[string]bool // names of cgm counters since the last flush (cgm resets counters on flush)
 	checkUUID            string
 	checkCID             string
 	clusterName          string
@@ -60,10 +64,17 @@ type Check struct {
 	clusterVers          string
 	submissionURL        string
 	log                  zerolog.Logger
+	filtersmu            sync.RWMutex
 	metricFilters        []MetricFilter
+	sinks                []Sink
+	spools               map[string]*spool
 	defaultTags          cgm.Tags
 	stats                Stats
+	lastSubmit           time.Time
 	submitDeadline       time.Duration
+	maxChunkBytes        int
+	maxChunkMetrics      int
+	brokerIdleTimeout    time.Duration
 	filterDynamicMetrics bool
 }
 
@@ -75,6 +86,7 @@ func NewCheck(ctx context.Context, parentLogger zerolog.Logger, cfg *config.Circ
 		return nil, errors.New("invalid cluster config (nil)")
 	}
 	c := &Check{
+		ctx:         ctx,
 		config:      cfg,
 		clusterName: clusterCfg.Name,
 		clusterTag:  "cluster:" + clusterCfg.Name,
@@ -108,6 +120,24 @@ func NewCheck(ctx context.Context, parentLogger zerolog.Logger, cfg *config.Circ
 	c.submitDeadline = d
 	c.log.Debug().Str("deadline", d.String()).Msg("using submit deadline")
 
+	if cfg.SubmitMaxBytes != "" {
+		sz, err := bytefmt.ToBytes(cfg.SubmitMaxBytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing submit max bytes %s: %w", cfg.SubmitMaxBytes, err)
+		}
+		c.maxChunkBytes = int(sz)
+	}
+	c.maxChunkMetrics = int(cfg.SubmitMaxMetrics)
+
+	if cfg.Broker.IdleConnTimeout != "" {
+		d, err := time.ParseDuration(cfg.Broker.IdleConnTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("parsing broker idle conn timeout %s: %w", cfg.Broker.IdleConnTimeout, err)
+		}
+		c.brokerIdleTimeout = d
+	}
+	c.log.Debug().Int("max_bytes", c.maxChunkBytes).Int("max_metrics", c.maxChunkMetrics).Uint("parallelism", cfg.SubmitParallelism).Msg("using submit chunking")
+
 	if cfg.DefaultStreamtags != "" {
 		tagList := strings.Split(cfg.DefaultStreamtags, ",")
 		ctags := make(cgm.Tags, len(tagList))
@@ -125,11 +155,20 @@ func NewCheck(ctx context.Context, parentLogger zerolog.Logger, cfg *config.Circ
 		c.defaultTags = ctags
 	}
 
+	if err := c.initializeSinks(); err != nil {
+		return nil, err
+	}
+
 	if cfg.DryRun {
 		c.log.Info().Msg("dry run enabled, no check required")
 		return c, nil // not sending metrics to circonus
 	}
 
+	if !c.hasSink(SinkCirconusTrap) {
+		c.log.Info().Strs("sinks", c.sinkNames()).Msg("circonus trap sink not enabled, no check required")
+		return c, nil // not sending metrics to circonus
+	}
+
 	client, err := c.createAPIClient()
 	if err != nil {
 		return nil, errors.Wrap(err, "setting up circonus api client")
@@ -139,7 +178,7 @@ func NewCheck(ctx context.Context, parentLogger zerolog.Logger, cfg *config.Circ
 		return nil, err
 	}
 
-	initializeAlerting(client, c.log, c.clusterName, c.clusterTag, c.clusterVers, c.checkCID, c.checkUUID)
+	initializeAlerting(client, c.log, c.config.DefaultAlertsFile, c.config.CustomRulesFile, c.clusterName, c.clusterTag, c.clusterVers, c.checkCID, c.checkUUID)
 
 	{
 		cfg := &cgm.Config{
@@ -181,7 +220,7 @@ func (c *Check) createAPIClient() (*apiclient.API, error) {
 		TokenApp: c.config.API.App,
 		URL:      c.config.API.URL,
 		Debug:    c.config.API.Debug,
-		Log:      apiLogshim{logh: c.log.With().Str("pkg", "apicli").Logger()},
+		Log:      apiLogshim{logh: c.log.With().Str("pkg", "apicli").Logger(), debug: c.config.API.Debug},
 	}
 	if c.config.API.CAFile != "" {
 		c.log.Debug().Str("file", c.config.API.CAFile).Msg("adding CA cert to api client")
@@ -258,17 +297,28 @@ func (c *Check) setSubmissionURL(client *apiclient.API, checkBundle *apiclient.C
 		return errors.Wrap(err, "unable to initialize broker TLS configuration")
 	}
 
-	c.metricFilters = make([]MetricFilter, len(bundle.MetricFilters))
-	for idx, filter := range bundle.MetricFilters {
-		if len(filter) == 0 {
-			return errors.Errorf("invalid (empty) metric filter configured (%d:%v)", idx, filter)
+	return c.setMetricFilters(bundle.MetricFilters)
+}
+
+// setMetricFilters replaces the filters applied locally to metrics before submission
+func (c *Check) setMetricFilters(filters [][]string) error {
+	metricFilters := make([]MetricFilter, len(filters))
+	filterDynamicMetrics := c.filterDynamicMetrics
+	for idx, filter := range filters {
+		if len(filter) < 2 {
+			return errors.Errorf("invalid metric filter configured (%d:%v)", idx, filter)
+		}
+
+		re, err := regexp.Compile(filter[1])
+		if err != nil {
+			return errors.Wrapf(err, "invalid metric filter configured (%d:%v)", idx, filter)
 		}
 
 		c.log.Debug().Strs("filter", filter).Msg("adding metric filter")
-		c.metricFilters[idx] = MetricFilter{
+		metricFilters[idx] = MetricFilter{
 			Enabled: true,
 			Allow:   strings.ToLower(filter[0]) == "allow",
-			Filter:  regexp.MustCompile(filter[1]),
+			Filter:  re,
 		}
 
 		fs := strings.Join(filter, ",")
@@ -281,12 +331,17 @@ func (c *Check) setSubmissionURL(client *apiclient.API, checkBundle *apiclient.C
 		// ["allow", "^.+$", "tags", "and(collector:dynamic)", "NO_LOCAL_FILTER dynamically collected metrics"],
 		if strings.Contains(fs, "and(collector:dynamic)") {
 			if strings.Contains(fs, "NO_LOCAL_FILTER") {
-				c.metricFilters[idx].Enabled = false
-				c.filterDynamicMetrics = false
+				metricFilters[idx].Enabled = false
+				filterDynamicMetrics = false
 			}
 		}
 	}
 
+	c.filtersmu.Lock()
+	c.metricFilters = metricFilters
+	c.filterDynamicMetrics = filterDynamicMetrics
+	c.filtersmu.Unlock()
+
 	return nil
 }
 
@@ -354,6 +409,15 @@ func (c *Check) updateMetricFilters(client *apiclient.API, cfg *config.Circonus,
 		checkMetricFilters = filters
 	}
 
+	for idx, filter := range checkMetricFilters {
+		if len(filter) < 2 {
+			return nil, errors.Errorf("invalid metric filter (%d:%v)", idx, filter)
+		}
+		if _, err := regexp.Compile(filter[1]); err != nil {
+			return nil, errors.Wrapf(err, "invalid metric filter (%d:%v)", idx, filter)
+		}
+	}
+
 	if !strings.Contains(strings.Join(b.Tags, ","), c.clusterTag) {
 		b.Tags = append(b.Tags, c.clusterTag)
 	}
@@ -0,0 +1,305 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package circonus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/testsupport"
+	apiclient "github.com/circonus-labs/go-apiclient"
+	apiclicfg "github.com/circonus-labs/go-apiclient/config"
+	"github.com/rs/zerolog"
+)
+
+// newVersionServer starts a fake kubernetes api server which only serves /version
+func newVersionServer(gitVersion string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"major":"1","minor":"24","gitVersion":%q,"platform":"linux/amd64"}`, gitVersion)
+	}))
+}
+
+// newTestCirconusConfig returns a circonus config using the fake api and its broker
+func newTestCirconusConfig(api *testsupport.API) *config.Circonus {
+	cfg := api.CirconusConfig("test-cluster")
+	cfg.UseGZIP = true
+	return cfg
+}
+
+func TestNewCheck(t *testing.T) {
+	t.Log("Testing NewCheck")
+
+	api, err := testsupport.NewAPI()
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	defer api.Close()
+
+	k8s := newVersionServer("v1.24.3")
+	defer k8s.Close()
+
+	clusterCfg := &config.Cluster{Name: "test-cluster", URL: k8s.URL}
+
+	t.Log("invalid configs")
+	{
+		if _, err := NewCheck(context.Background(), zerolog.Nop(), nil, clusterCfg); err == nil {
+			t.Fatal("expected error")
+		}
+		if _, err := NewCheck(context.Background(), zerolog.Nop(), newTestCirconusConfig(api), nil); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+
+	t.Log("create check bundle")
+	{
+		c, err := NewCheck(context.Background(), zerolog.Nop(), newTestCirconusConfig(api), clusterCfg)
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+
+		bundles := api.CheckBundles()
+		if len(bundles) != 1 {
+			t.Fatalf("expected 1 check bundle, got %d", len(bundles))
+		}
+		bundle := bundles[0]
+		if bundle.Type != checkType {
+			t.Fatalf("expected type %s, got %s", checkType, bundle.Type)
+		}
+		if !strings.Contains(strings.Join(bundle.Tags, ","), "cluster:test-cluster") {
+			t.Fatalf("expected cluster tag, got %v", bundle.Tags)
+		}
+		if len(bundle.MetricFilters) == 0 {
+			t.Fatal("expected metric filters")
+		}
+		if c.checkBundleCID != bundle.CID {
+			t.Fatalf("expected bundle %s, got %s", bundle.CID, c.checkBundleCID)
+		}
+		if c.checkUUID != bundle.CheckUUIDs[0] {
+			t.Fatalf("expected check uuid %s, got %s", bundle.CheckUUIDs[0], c.checkUUID)
+		}
+		if c.submissionURL != bundle.Config[apiclicfg.SubmissionURL] {
+			t.Fatalf("expected submission url %s, got %s", bundle.Config[apiclicfg.SubmissionURL], c.submissionURL)
+		}
+		if c.brokerTLSConfig == nil {
+			t.Fatal("expected broker tls config")
+		}
+		if n := api.RequestCount("GET /pki/ca.crt"); n != 1 {
+			t.Fatalf("expected 1 ca cert request, got %d", n)
+		}
+	}
+
+	t.Log("find existing check bundle")
+	{
+		c, err := NewCheck(context.Background(), zerolog.Nop(), newTestCirconusConfig(api), clusterCfg)
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if n := len(api.CheckBundles()); n != 1 {
+			t.Fatalf("expected 1 check bundle, got %d", n)
+		}
+		if n := api.RequestCount("POST /check_bundle"); n != 1 {
+			t.Fatalf("expected 1 create request, got %d", n)
+		}
+		if c.checkBundleCID != api.CheckBundles()[0].CID {
+			t.Fatalf("expected bundle %s, got %s", api.CheckBundles()[0].CID, c.checkBundleCID)
+		}
+	}
+
+	t.Log("configured check bundle")
+	{
+		bundle, err := api.AddCheckBundle(apiclient.CheckBundle{
+			Brokers: []string{api.Broker.CID},
+			Status:  checkStatusActive,
+			Target:  "other-cluster",
+			Type:    altCheckType,
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		cfg := newTestCirconusConfig(api)
+		cfg.Check.BundleCID = bundle.CID
+		c, err := NewCheck(context.Background(), zerolog.Nop(), cfg, clusterCfg)
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if c.checkBundleCID != bundle.CID {
+			t.Fatalf("expected bundle %s, got %s", bundle.CID, c.checkBundleCID)
+		}
+	}
+
+	t.Log("unknown broker")
+	{
+		cfg := newTestCirconusConfig(api)
+		cfg.Check.Target = "unknown-broker"
+		cfg.Check.BrokerCID = "/broker/1"
+		if _, err := NewCheck(context.Background(), zerolog.Nop(), cfg, clusterCfg); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+}
+
+func TestNewCheckSubmit(t *testing.T) {
+	t.Log("Testing NewCheck submission to broker")
+
+	api, err := testsupport.NewAPI()
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	defer api.Close()
+
+	k8s := newVersionServer("v1.24.3")
+	defer k8s.Close()
+
+	c, err := NewCheck(context.Background(), zerolog.Nop(), newTestCirconusConfig(api), &config.Cluster{Name: "test-cluster", URL: k8s.URL})
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	metrics := map[string]MetricSample{
+		"foo": {Type: MetricTypeUint64, Value: uint64(1)},
+		"bar": {Type: MetricTypeFloat64, Value: 1.5},
+	}
+	if err := c.submitMetrics(context.Background(), metrics, zerolog.Nop(), true); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	received := api.Broker.Metrics()
+	if len(received) != len(metrics) {
+		t.Fatalf("expected %d metrics, got %d", len(metrics), len(received))
+	}
+	if m := received["bar"]; m.Type != MetricTypeFloat64 || m.Value.(float64) != 1.5 {
+		t.Fatalf("unexpected metric %#v", m)
+	}
+	if stats := c.SubmitStats(); stats.RecvMetrics != uint64(len(metrics)) {
+		t.Fatalf("expected %d received metrics, got %d", len(metrics), stats.RecvMetrics)
+	}
+
+	t.Log("broker error")
+	{
+		api.Broker.SetStatus(http.StatusBadRequest)
+		if err := c.submitMetrics(context.Background(), metrics, zerolog.Nop(), false); err != nil {
+			t.Fatalf("expected no error (rejected metrics dropped), got %s", err)
+		}
+		if n := api.Broker.Submissions(); n != 1 {
+			t.Fatalf("expected 1 accepted submission, got %d", n)
+		}
+	}
+}
+
+func TestNewCheckMultipleBrokers(t *testing.T) {
+	t.Log("Testing NewCheck with multiple brokers")
+
+	api, err := testsupport.NewAPI()
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	defer api.Close()
+
+	broker2, err := api.AddBroker()
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	k8s := newVersionServer("v1.24.3")
+	defer k8s.Close()
+
+	bundle, err := api.AddCheckBundle(apiclient.CheckBundle{
+		Brokers: []string{api.Broker.CID, broker2.CID},
+		Status:  checkStatusActive,
+		Target:  "test-cluster",
+		Type:    checkType,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	c, err := NewCheck(context.Background(), zerolog.Nop(), newTestCirconusConfig(api), &config.Cluster{Name: "test-cluster", URL: k8s.URL})
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if c.checkBundleCID != bundle.CID {
+		t.Fatalf("expected bundle %s, got %s", bundle.CID, c.checkBundleCID)
+	}
+	if n := len(c.brokerTargetList()); n != 2 {
+		t.Fatalf("expected 2 broker targets, got %d", n)
+	}
+
+	api.Broker.SetStatus(http.StatusServiceUnavailable)
+
+	metrics := map[string]MetricSample{"foo": {Type: MetricTypeUint64, Value: uint64(1)}}
+	if err := c.submitMetrics(context.Background(), metrics, zerolog.Nop(), false); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if n := broker2.Submissions(); n != 1 {
+		t.Fatalf("expected failover to second broker, got %d submissions", n)
+	}
+}
+
+func TestNewCheckClusterMetricFilters(t *testing.T) {
+	t.Log("Testing NewCheck per-cluster metric filters file")
+
+	api, err := testsupport.NewAPI()
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	defer api.Close()
+
+	k8s := newVersionServer("v1.24.3")
+	defer k8s.Close()
+
+	dir := t.TempDir()
+	filtersA := filepath.Join(dir, "filters-a.json")
+	filtersB := filepath.Join(dir, "filters-b.json")
+	if err := os.WriteFile(filtersA, []byte(`{"metric_filters":[["allow","^a_.*$"],["deny","^.+$"]]}`), 0600); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if err := os.WriteFile(filtersB, []byte(`{"metric_filters":[["allow","^b_.*$"],["deny","^.+$"]]}`), 0600); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	base := newTestCirconusConfig(api)
+	base.MetricFiltersFile = filtersA
+
+	clusters := []config.Cluster{
+		{Name: "cluster-a", URL: k8s.URL},
+		{Name: "cluster-b", URL: k8s.URL, Circonus: map[string]interface{}{
+			"metric_filters_file": filtersB,
+			"check":               map[string]interface{}{"target": "cluster-b"},
+		}},
+	}
+	for i := range clusters {
+		cc, err := config.ClusterCirconus(*base, clusters[i])
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if _, err := NewCheck(context.Background(), zerolog.Nop(), &cc, &clusters[i]); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+	}
+
+	bundles := api.CheckBundles()
+	if len(bundles) != 2 {
+		t.Fatalf("expected 2 check bundles, got %d", len(bundles))
+	}
+	expect := map[string]string{"test-cluster": "^a_.*$", "cluster-b": "^b_.*$"}
+	for _, bundle := range bundles {
+		if len(bundle.MetricFilters) == 0 || bundle.MetricFilters[0][1] != expect[bundle.Target] {
+			t.Fatalf("expected %s filters for %s, got %v", expect[bundle.Target], bundle.Target, bundle.MetricFilters)
+		}
+	}
+}
@@ -9,19 +9,18 @@ package circonus
 import (
 	"strings"
 
-	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config/keys"
 	"github.com/rs/zerolog"
-	"github.com/spf13/viper"
 )
 
 // apiLogshim is used to satisfy apiclient Logger interface for retryable-http (avoiding ptr receiver issue)
 type apiLogshim struct {
-	logh zerolog.Logger
+	logh  zerolog.Logger
+	debug bool
 }
 
 func (l apiLogshim) Printf(fmt string, v ...interface{}) {
 	if strings.HasPrefix(fmt, "[DEBUG]") {
-		if !viper.GetBool(keys.APIDebug) {
+		if !l.debug {
 			return
 		}
 	}
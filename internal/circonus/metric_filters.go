@@ -10,9 +10,7 @@ import (
 	"os"
 	"strings"
 
-	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config/keys"
 	"github.com/hashicorp/go-version"
-	"github.com/spf13/viper"
 )
 
 const (
@@ -147,8 +145,7 @@ type metricFilters struct {
 }
 
 func (c *Check) loadMetricFilters() [][]string {
-	mfConfigFile := viper.GetString(keys.MetricFiltersFile)
-	data, err := os.ReadFile(mfConfigFile)
+	data, err := os.ReadFile(c.config.MetricFiltersFile)
 	if err != nil {
 		c.log.Warn().Err(err).Msg("using defaults")
 		return c.defaultFilters()
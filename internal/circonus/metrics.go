@@ -6,10 +6,13 @@
 package circonus
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -66,8 +69,21 @@ type MetricSample struct {
 	Value     interface{} `json:"_value"`
 	Type      string      `json:"_type"`
 	Timestamp uint64      `json:"_ts,omitempty"`
+	Kind      SampleKind  `json:"-"` // not submitted, used by sinks distinguishing counters and gauges
 }
 
+// SampleKind is the origin of a numeric metric sample
+type SampleKind uint8
+
+const (
+	// KindGauge a point in time value (default)
+	KindGauge SampleKind = iota
+	// KindCounter a cumulative counter, e.g. a prometheus counter
+	KindCounter
+	// KindDeltaCounter a counter reset on each flush, the agent's own counters (e.g. IncrementCounter)
+	KindDeltaCounter
+)
+
 var metricTypeRx = regexp.MustCompile(`^[` + strings.Join([]string{
 	MetricTypeInt32,
 	MetricTypeUint32,
@@ -81,6 +97,7 @@ var metricTypeRx = regexp.MustCompile(`^[` + strings.Join([]string{
 
 // AddGauge to queue for submission
 func (c *Check) AddGauge(metricName string, tags cgm.Tags, value interface{}) {
+	telemetry.setGauge(metricName, c.clusterName, tags, value)
 	c.metricsmu.Lock()
 	defer c.metricsmu.Unlock()
 	if c.metrics != nil {
@@ -91,6 +108,7 @@ func (c *Check) AddGauge(metricName string, tags cgm.Tags, value interface{}) {
 
 // AddHistSample to queue for submission
 func (c *Check) AddHistSample(metricName string, tags cgm.Tags, value float64) {
+	telemetry.observe(metricName, c.clusterName, tags, value)
 	c.metricsmu.Lock()
 	defer c.metricsmu.Unlock()
 	if c.metrics != nil {
@@ -111,34 +129,48 @@ func (c *Check) AddText(metricName string, tags cgm.Tags, value string) {
 
 // IncrementCounter to queue for submission
 func (c *Check) IncrementCounter(metricName string, tags cgm.Tags) {
+	telemetry.addCounter(metricName, c.clusterName, tags, 1)
 	c.metricsmu.Lock()
 	defer c.metricsmu.Unlock()
 	if c.metrics != nil {
 		tags = append(tags, c.defaultTags...)
 		c.metrics.IncrementWithTags(metricName, tags)
+		c.addMetricCounter(metricName, tags)
 	}
 }
 
 // IncrementCounterByValue to queue for submission
 func (c *Check) IncrementCounterByValue(metricName string, tags cgm.Tags, val uint64) {
+	telemetry.addCounter(metricName, c.clusterName, tags, val)
 	c.metricsmu.Lock()
 	defer c.metricsmu.Unlock()
 	if c.metrics != nil {
 		tags = append(tags, c.defaultTags...)
 		c.metrics.IncrementByValueWithTags(metricName, tags, val)
+		c.addMetricCounter(metricName, tags)
 	}
 }
 
 // SetCounter to queue for submission
 func (c *Check) SetCounter(metricName string, tags cgm.Tags, value uint64) {
+	telemetry.setCounter(metricName, c.clusterName, tags, value)
 	c.metricsmu.Lock()
 	defer c.metricsmu.Unlock()
 	if c.metrics != nil {
 		tags = append(tags, c.defaultTags...)
 		c.metrics.SetWithTags(metricName, tags, value)
+		c.addMetricCounter(metricName, tags)
 	}
 }
 
+// addMetricCounter records a cgm counter so it is flushed as a counter, metricsmu must be held
+func (c *Check) addMetricCounter(metricName string, tags cgm.Tags) {
+	if c.metricCounters == nil {
+		c.metricCounters = make(map[string]bool)
+	}
+	c.metricCounters[c.metrics.MetricNameWithStreamTags(metricName, tags)] = true
+}
+
 // QueueMetricSample to queue for submission
 func (c *Check) QueueMetricSample(
 	metrics map[string]MetricSample,
@@ -148,6 +180,32 @@ func (c *Check) QueueMetricSample(
 	measurementTags []string,
 	value interface{},
 	timestamp *time.Time,
+) error {
+	return c.queueMetricSample(metrics, metricName, metricType, streamTags, measurementTags, value, timestamp, KindGauge)
+}
+
+// QueueCounterSample to queue a cumulative counter (e.g. a prometheus counter) for submission
+func (c *Check) QueueCounterSample(
+	metrics map[string]MetricSample,
+	metricName,
+	metricType string,
+	streamTags,
+	measurementTags []string,
+	value interface{},
+	timestamp *time.Time,
+) error {
+	return c.queueMetricSample(metrics, metricName, metricType, streamTags, measurementTags, value, timestamp, KindCounter)
+}
+
+func (c *Check) queueMetricSample(
+	metrics map[string]MetricSample,
+	metricName,
+	metricType string,
+	streamTags,
+	measurementTags []string,
+	value interface{},
+	timestamp *time.Time,
+	kind SampleKind,
 ) error {
 	if metrics == nil {
 		return errors.New("invalid metrics queue (nil)")
@@ -171,12 +229,15 @@ func (c *Check) QueueMetricSample(
 		}
 	}
 
+	c.filtersmu.RLock()
+	metricFilters := c.metricFilters
 	applyFilters := true
 	if strings.Contains(strings.Join(streamTags, ","), "collector:dynamic") {
 		applyFilters = c.filterDynamicMetrics
 	}
+	c.filtersmu.RUnlock()
 
-	if applyFilters && len(c.metricFilters) > 0 {
+	if applyFilters && len(metricFilters) > 0 {
 		rejectMetric := true
 		origName := metricName
 		if strings.Contains(metricName, "|ST") {
@@ -185,7 +246,7 @@ func (c *Check) QueueMetricSample(
 				metricName = parts[0]
 			}
 		}
-		for _, mf := range c.metricFilters {
+		for _, mf := range metricFilters {
 			if !mf.Enabled {
 				continue
 			}
@@ -260,6 +321,7 @@ func (c *Check) QueueMetricSample(
 	metricSample := MetricSample{
 		Type:  metricType,
 		Value: val,
+		Kind:  kind,
 	}
 
 	if timestamp != nil && (metricType != MetricTypeHistogram && metricType != MetricTypeCumulativeHistogram) {
@@ -275,3 +337,156 @@ func (c *Check) QueueMetricSample(
 func makeTimestamp(ts *time.Time) uint64 {
 	return uint64(ts.UTC().UnixNano() / (int64(time.Millisecond) / int64(time.Nanosecond)))
 }
+
+// histogramBin is a single decoded H[bound]=count histogram bin
+type histogramBin struct {
+	Bound float64
+	Count uint64
+}
+
+// maxHistogramBound is the upper bound used for prometheus +Inf buckets (see promtext)
+const maxHistogramBound = 10e+127
+
+// sampleValueFloat64 converts a numeric metric sample value to a float64
+func sampleValueFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// sampleHistogramBins decodes the bins of a histogram metric sample
+// value (e.g. []string{"H[1.0e+00]=3",...}), sorted by bound
+func sampleHistogramBins(value interface{}) ([]histogramBin, bool) {
+	var encoded []string
+	switch v := value.(type) {
+	case []string:
+		encoded = v
+	case []interface{}:
+		encoded = make([]string, 0, len(v))
+		for _, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return nil, false
+			}
+			encoded = append(encoded, s)
+		}
+	default:
+		return nil, false
+	}
+
+	bins := make([]histogramBin, 0, len(encoded))
+	for _, e := range encoded {
+		if !strings.HasPrefix(e, "H[") {
+			return nil, false
+		}
+		b, c, found := strings.Cut(e[2:], "]=")
+		if !found {
+			return nil, false
+		}
+		bound, err := strconv.ParseFloat(b, 64)
+		if err != nil {
+			return nil, false
+		}
+		count, err := strconv.ParseUint(c, 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		bins = append(bins, histogramBin{Bound: bound, Count: count})
+	}
+
+	sort.Slice(bins, func(i, j int) bool { return bins[i].Bound < bins[j].Bound })
+
+	return bins, true
+}
+
+// histogramBucket is a histogram bucket with its (inclusive) upper bound
+type histogramBucket struct {
+	UpperBound float64
+	Count      uint64
+}
+
+// histogramBuckets converts decoded histogram bins into buckets bounded by the upper
+// edge of the log-linear (two significant digit) bin each bin value falls in. Bins from
+// prometheus histograms carry the bucket upper bound scaled by 0.999 (see promtext), so
+// bounds with up to two significant digits (e.g. 0.005, 0.25, 10) are recovered exactly,
+// other bounds are rounded up to two significant digits (e.g. 0.125 becomes 0.13). Bins
+// at or above maxHistogramBound are the +Inf bucket. Counts are per bucket, not cumulative.
+func histogramBuckets(bins []histogramBin) []histogramBucket {
+	buckets := make([]histogramBucket, 0, len(bins))
+	for _, bin := range bins {
+		ub := math.Inf(+1)
+		if bin.Bound < maxHistogramBound {
+			ub = binUpperBound(bin.Bound)
+		}
+		if n := len(buckets); n > 0 && buckets[n-1].UpperBound == ub {
+			buckets[n-1].Count += bin.Count
+			continue
+		}
+		buckets = append(buckets, histogramBucket{UpperBound: ub, Count: bin.Count})
+	}
+	return buckets
+}
+
+// binUpperBound returns the upper edge of the log-linear bin v falls in
+func binUpperBound(v float64) float64 {
+	if v == 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return v
+	}
+
+	// shortest representation, so the leading digits are not rounded up
+	mantissa, exponent, _ := strings.Cut(strconv.FormatFloat(math.Abs(v), 'e', -1, 64), "e")
+	exp, err := strconv.Atoi(exponent)
+	if err != nil {
+		return v
+	}
+	digits, err := strconv.Atoi((strings.Replace(mantissa, ".", "", 1) + "0")[:2])
+	if err != nil {
+		return v
+	}
+
+	if v < 0 {
+		// bins of negative values are bounded above by their smaller magnitude edge
+		return -scalePow10(digits, exp-1)
+	}
+	return scalePow10(digits+1, exp-1)
+}
+
+// scalePow10 returns n*10^exp, dividing for negative exponents so that
+// e.g. 5*10^-1 is exactly 0.5
+func scalePow10(n, exp int) float64 {
+	if exp >= 0 {
+		return float64(n) * math.Pow10(exp)
+	}
+	return float64(n) / math.Pow10(-exp)
+}
@@ -0,0 +1,46 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package circonus
+
+import (
+	apiclient "github.com/circonus-labs/go-apiclient"
+	"github.com/pkg/errors"
+)
+
+// Reload re-applies the metric filters (metricFilters, or the metric filters file when
+// empty) to the check bundle and the local filters, then re-applies the default alerts
+// and custom rules. On error the current metric filters remain in use.
+func (c *Check) Reload(metricFilters string) error {
+	if c.checkBundleCID == "" {
+		return nil // no check (dry run or circonus trap sink not enabled)
+	}
+
+	client, err := c.createAPIClient()
+	if err != nil {
+		return errors.Wrap(err, "setting up circonus api client")
+	}
+
+	cid := c.checkBundleCID
+	bundle, err := client.FetchCheckBundle(apiclient.CIDType(&cid))
+	if err != nil {
+		return errors.Wrap(err, "fetching check bundle")
+	}
+
+	cfg := *c.config
+	cfg.Check.MetricFilters = metricFilters
+	bundle, err = c.updateMetricFilters(client, &cfg, bundle)
+	if err != nil {
+		return errors.Wrap(err, "updating metric filters")
+	}
+	if err := c.setMetricFilters(bundle.MetricFilters); err != nil {
+		return err
+	}
+	c.log.Info().Int("filters", len(bundle.MetricFilters)).Msg("reloaded metric filters")
+
+	initializeAlerting(client, c.log, c.config.DefaultAlertsFile, c.config.CustomRulesFile, c.clusterName, c.clusterTag, c.clusterVers, c.checkCID, c.checkUUID)
+
+	return nil
+}
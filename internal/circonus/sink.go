@@ -0,0 +1,464 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package circonus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/bytefmt"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/release"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// SinkCirconusTrap sends metrics to the circonus httptrap check
+	SinkCirconusTrap = "circonus-trap"
+	// SinkStdoutJSON writes metrics, as json, to stdout (used by dry-run)
+	SinkStdoutJSON = "stdout-json"
+	// SinkFile appends metrics, as json (one batch per line), to a file
+	SinkFile = "file"
+)
+
+// Sink defines a destination for batches of metrics queued by collectors
+type Sink interface {
+	// Name returns the name the sink was registered with
+	Name() string
+	// Submit sends a batch of metrics to the destination, when only part of the
+	// batch is delivered a *PartialSubmitError with the undelivered metrics is returned
+	Submit(ctx context.Context, metrics map[string]MetricSample, resultLogger zerolog.Logger, includeStats bool) error
+}
+
+// PartialSubmitError is returned by a sink when part of a batch was delivered
+// and part failed, only the undelivered metrics are retried or spooled
+type PartialSubmitError struct {
+	Err         error
+	Undelivered map[string]MetricSample
+}
+
+func (e *PartialSubmitError) Error() string {
+	return fmt.Sprintf("partial submission, %d metrics undelivered: %s", len(e.Undelivered), e.Err)
+}
+
+func (e *PartialSubmitError) Unwrap() error {
+	return e.Err
+}
+
+// undeliveredMetrics returns the metrics of a batch which were not delivered when
+// submitting it failed with err
+func undeliveredMetrics(err error, metrics map[string]MetricSample) map[string]MetricSample {
+	var partial *PartialSubmitError
+	if errors.As(err, &partial) {
+		return partial.Undelivered
+	}
+	return metrics
+}
+
+// submitResults combines the results of submitting the parts of a batch
+type submitResults struct {
+	sync.Mutex
+	err         error
+	delivered   bool
+	undelivered map[string]MetricSample
+}
+
+// add records the result of submitting part of a batch
+func (r *submitResults) add(err error, part map[string]MetricSample) {
+	r.Lock()
+	defer r.Unlock()
+	if err == nil {
+		r.delivered = true
+		return
+	}
+	if r.err == nil {
+		r.err = err
+	}
+	undelivered := undeliveredMetrics(err, part)
+	if len(undelivered) < len(part) {
+		r.delivered = true
+	}
+	if r.undelivered == nil {
+		r.undelivered = make(map[string]MetricSample)
+	}
+	for name, sample := range undelivered {
+		r.undelivered[name] = sample
+	}
+}
+
+// result returns the error for the batch, a *PartialSubmitError when some parts were delivered
+func (r *submitResults) result() error {
+	r.Lock()
+	defer r.Unlock()
+	if r.err == nil {
+		return nil
+	}
+	if !r.delivered {
+		return r.err
+	}
+	return &PartialSubmitError{Err: r.err, Undelivered: r.undelivered}
+}
+
+// SinkFactory creates a new sink instance for a check
+type SinkFactory func(c *Check) (Sink, error)
+
+var (
+	sinksmu sync.RWMutex
+	sinks   = map[string]SinkFactory{}
+)
+
+func init() {
+	RegisterSink(SinkCirconusTrap, newTrapSink)
+	RegisterSink(SinkStdoutJSON, newStdoutSink)
+	RegisterSink(SinkFile, newFileSink)
+}
+
+// RegisterSink makes a sink available by name, registering an
+// existing name will replace the previous factory
+func RegisterSink(name string, factory SinkFactory) {
+	sinksmu.Lock()
+	defer sinksmu.Unlock()
+	sinks[name] = factory
+}
+
+// RegisteredSinks returns a sorted list of the registered sink names
+func RegisteredSinks() []string {
+	sinksmu.RLock()
+	defer sinksmu.RUnlock()
+	names := make([]string, 0, len(sinks))
+	for name := range sinks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sinkNames returns the list of sink names configured for the check
+func (c *Check) sinkNames() []string {
+	if c.config.DryRun {
+		return []string{SinkStdoutJSON}
+	}
+
+	var names []string
+	for _, name := range strings.Split(c.config.Sinks, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	if len(names) == 0 {
+		return []string{SinkCirconusTrap}
+	}
+
+	return names
+}
+
+// initializeSinks creates the sinks configured for the check
+func (c *Check) initializeSinks() error {
+	names := c.sinkNames()
+	c.sinks = make([]Sink, 0, len(names))
+
+	for _, name := range names {
+		sinksmu.RLock()
+		factory, ok := sinks[name]
+		sinksmu.RUnlock()
+		if !ok {
+			return errors.Errorf("unknown sink (%s), registered: %s", name, strings.Join(RegisteredSinks(), ","))
+		}
+		s, err := factory(c)
+		if err != nil {
+			return errors.Wrapf(err, "initializing sink (%s)", name)
+		}
+		c.sinks = append(c.sinks, s)
+	}
+
+	c.log.Debug().Strs("sinks", names).Msg("using sinks")
+
+	if c.config.Spool.Dir != "" && !c.config.DryRun {
+		c.spools = make(map[string]*spool, len(c.sinks))
+		for _, s := range c.sinks {
+			sp, err := newSpool(c, s)
+			if err != nil {
+				return errors.Wrapf(err, "initializing spool for sink (%s)", s.Name())
+			}
+			c.spools[s.Name()] = sp
+		}
+		c.log.Debug().Str("dir", c.config.Spool.Dir).Msg("spooling failed submissions")
+	}
+
+	return nil
+}
+
+// hasSink indicates whether a sink is configured for the check
+func (c *Check) hasSink(name string) bool {
+	for _, s := range c.sinks {
+		if s.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// flushSinks sends metrics to each configured sink, retrying a sink's
+// submission when the submit deadline is reached. Submission stats are
+// only tracked for the first (primary) sink so they are not counted
+// multiple times.
+func (c *Check) flushSinks(ctx context.Context, metrics map[string]MetricSample, resultLogger zerolog.Logger, includeStats bool) error {
+	if metrics == nil {
+		return errors.New("invalid metrics (nil)")
+	}
+	if len(metrics) == 0 {
+		return nil
+	}
+	if len(c.sinks) == 0 {
+		return errors.New("invalid state (zero sinks)")
+	}
+
+	var err error
+	for idx, s := range c.sinks {
+		if e := c.flushSink(ctx, s, metrics, resultLogger, includeStats && idx == 0); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	return err
+}
+
+// flushSink sends metrics to a single sink. When spooling is enabled, failed
+// batches are written to the sink's spool and, after a successful submission,
+// any spooled batches are replayed in the background.
+func (c *Check) flushSink(ctx context.Context, s Sink, metrics map[string]MetricSample, resultLogger zerolog.Logger, includeStats bool) error {
+	var err error
+	sp := c.spools[s.Name()]
+	flushStart := time.Now()
+
+	for {
+		submitCtx, submitCtxCancel := context.WithDeadline(ctx, time.Now().Add(c.submitDeadline))
+		err = s.Submit(submitCtx, metrics, resultLogger, includeStats)
+		if err == nil {
+			submitCtxCancel()
+			break
+		}
+
+		// only retry (or spool) what the sink did not deliver
+		metrics = undeliveredMetrics(err, metrics)
+
+		// retry when the submit deadline was reached, unless the caller's context is done
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			c.log.Warn().Err(err).Str("sink", s.Name()).Str("deadline", c.submitDeadline.String()).Msg("deadline reached submitting metrics, retrying")
+			submitCtxCancel()
+			continue
+		}
+
+		submitCtxCancel()
+		c.log.Error().Err(err).Str("sink", s.Name()).Msg("submitting metrics")
+		break
+	}
+
+	if err == nil {
+		c.statsmu.Lock()
+		c.lastSubmit = time.Now()
+		c.statsmu.Unlock()
+	}
+
+	if sp != nil {
+		if err != nil {
+			if e := sp.add(metrics, batchTimestamp(metrics, flushStart)); e != nil {
+				c.log.Error().Err(e).Str("sink", s.Name()).Msg("spooling failed batch")
+			}
+		} else if sp.pending() {
+			sp.startReplay(c.backgroundContext())
+		}
+	}
+
+	return err
+}
+
+// backgroundContext returns the context used for work outliving a collection cycle
+func (c *Check) backgroundContext() context.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+	return context.Background()
+}
+
+// addSubmitStats updates the submission stats for sinks which do not receive broker results
+func (c *Check) addSubmitStats(numMetrics, dataLen int) {
+	c.statsmu.Lock()
+	c.stats.SentMetrics += uint64(numMetrics)
+	c.stats.SentBytes += uint64(dataLen)
+	c.stats.SentSize += uint64(dataLen)
+	c.statsmu.Unlock()
+}
+
+// newSinkHTTPClient creates the http client used by sinks posting to http endpoints
+func newSinkHTTPClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			TLSHandshakeTimeout: 10 * time.Second,
+			MaxIdleConnsPerHost: 2,
+		},
+		Timeout: 60 * time.Second, // hard 60s timeout
+	}
+}
+
+// postSinkData posts an encoded batch of metrics to an http endpoint, retrying
+// transient failures. Any 2xx response is considered success.
+func (c *Check) postSinkData(ctx context.Context, client *http.Client, url string, headers http.Header, data []byte) error {
+	req, err := retryablehttp.NewRequest("POST", url, bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrap(err, "creating request")
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("User-Agent", release.NAME+"/"+release.VERSION)
+	for k, v := range headers {
+		req.Header[k] = v
+	}
+
+	retryClient := retryablehttp.NewClient()
+	retryClient.HTTPClient = client
+	retryClient.Logger = submitLogshim{logh: c.log.With().Str("pkg", "retryablehttp").Logger()}
+	retryClient.RetryWaitMin = 50 * time.Millisecond
+	retryClient.RetryWaitMax = 1 * time.Second
+	retryClient.RetryMax = 3
+
+	resp, err := retryClient.Do(req)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return errors.Wrap(err, "making request")
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "reading body")
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("%s %s (%s)", url, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+// trapSink sends metrics to the circonus httptrap check
+type trapSink struct {
+	c *Check
+}
+
+func newTrapSink(c *Check) (Sink, error) {
+	return &trapSink{c: c}, nil
+}
+
+func (s *trapSink) Name() string {
+	return SinkCirconusTrap
+}
+
+func (s *trapSink) Submit(ctx context.Context, metrics map[string]MetricSample, resultLogger zerolog.Logger, includeStats bool) error {
+	return s.c.submitMetrics(ctx, metrics, resultLogger, includeStats)
+}
+
+// stdoutSink writes metrics, as json, to stdout
+type stdoutSink struct {
+	sync.Mutex
+	c *Check
+}
+
+func newStdoutSink(c *Check) (Sink, error) {
+	return &stdoutSink{c: c}, nil
+}
+
+func (s *stdoutSink) Name() string {
+	return SinkStdoutJSON
+}
+
+func (s *stdoutSink) Submit(ctx context.Context, metrics map[string]MetricSample, resultLogger zerolog.Logger, includeStats bool) error {
+	data, err := json.Marshal(metrics)
+	if err != nil {
+		resultLogger.Error().Err(err).Msg("json encoding metrics")
+		return errors.Wrap(err, "marshaling metrics")
+	}
+
+	s.Lock()
+	_, err = os.Stdout.Write(append(data, '\n'))
+	s.Unlock()
+	if err != nil {
+		return errors.Wrap(err, "writing metrics to stdout")
+	}
+
+	if includeStats {
+		s.c.addSubmitStats(len(metrics), len(data))
+	}
+
+	return nil
+}
+
+// fileSink appends metrics, as json, to a file - one batch per line
+type fileSink struct {
+	sync.Mutex
+	c    *Check
+	file string
+}
+
+func newFileSink(c *Check) (Sink, error) {
+	if c.config.SinkFile == "" {
+		return nil, errors.New("invalid sink file (empty)")
+	}
+	return &fileSink{c: c, file: c.config.SinkFile}, nil
+}
+
+func (s *fileSink) Name() string {
+	return SinkFile
+}
+
+func (s *fileSink) Submit(ctx context.Context, metrics map[string]MetricSample, resultLogger zerolog.Logger, includeStats bool) error {
+	data, err := json.Marshal(metrics)
+	if err != nil {
+		resultLogger.Error().Err(err).Msg("json encoding metrics")
+		return errors.Wrap(err, "marshaling metrics")
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	fh, err := os.OpenFile(s.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644) //nolint:gosec
+	if err != nil {
+		return errors.Wrap(err, "opening sink file")
+	}
+	if _, err := fh.Write(append(data, '\n')); err != nil {
+		_ = fh.Close()
+		return errors.Wrap(err, "writing sink file")
+	}
+	if err := fh.Close(); err != nil {
+		return errors.Wrap(err, "closing sink file")
+	}
+
+	resultLogger.Debug().
+		Str("file", s.file).
+		Int("sent_metrics", len(metrics)).
+		Str("bytes_sent", bytefmt.ByteSize(uint64(len(data)))).
+		Msg("submitted")
+
+	if includeStats {
+		s.c.addSubmitStats(len(metrics), len(data))
+	}
+
+	return nil
+}
@@ -0,0 +1,622 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package circonus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/bytefmt"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/release"
+	"github.com/klauspost/compress/gzip"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	// SinkOTLP sends metrics to an OpenTelemetry OTLP/HTTP endpoint (e.g. collector /v1/metrics)
+	SinkOTLP = "otlp"
+
+	// OTLPEncodingProtobuf sends binary protobuf encoded requests
+	OTLPEncodingProtobuf = "protobuf"
+	// OTLPEncodingJSON sends json encoded requests
+	OTLPEncodingJSON = "json"
+
+	// otlpTemporalityDelta is AGGREGATION_TEMPORALITY_DELTA
+	otlpTemporalityDelta = 1
+	// otlpTemporalityCumulative is AGGREGATION_TEMPORALITY_CUMULATIVE
+	otlpTemporalityCumulative = 2
+)
+
+// otlpResourceTags maps stream tag categories to the resource attributes
+// (k8s semantic conventions) samples are grouped by. These tags are also kept,
+// as all other tags, as data point attributes.
+var otlpResourceTags = map[string]string{
+	"cluster":   "k8s.cluster.name",
+	"namespace": "k8s.namespace.name",
+	"pod":       "k8s.pod.name",
+	"node":      "k8s.node.name",
+}
+
+func init() {
+	RegisterSink(SinkOTLP, newOTLPSink)
+}
+
+// otlp request model, field names follow the OTLP/JSON mapping (lowerCamelCase,
+// 64 bit integers as strings) so the same structures are used for both encodings.
+
+type otlpMetricsRequest struct {
+	ResourceMetrics []*otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource        `json:"resource"`
+	ScopeMetrics []*otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope     `json:"scope"`
+	Metrics []*otlpMetric `json:"metrics"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpMetric struct {
+	Name      string         `json:"name"`
+	Gauge     *otlpGauge     `json:"gauge,omitempty"`
+	Sum       *otlpSum       `json:"sum,omitempty"`
+	Histogram *otlpHistogram `json:"histogram,omitempty"`
+}
+
+type otlpGauge struct {
+	DataPoints []otlpNumberDataPoint `json:"dataPoints"`
+}
+
+type otlpSum struct {
+	DataPoints             []otlpNumberDataPoint `json:"dataPoints"`
+	AggregationTemporality int                   `json:"aggregationTemporality"`
+	IsMonotonic            bool                  `json:"isMonotonic"`
+}
+
+type otlpHistogram struct {
+	DataPoints             []otlpHistogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                      `json:"aggregationTemporality"`
+}
+
+type otlpNumberDataPoint struct {
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano uint64         `json:"startTimeUnixNano,omitempty,string"`
+	TimeUnixNano      uint64         `json:"timeUnixNano,string"`
+	AsDouble          *float64       `json:"asDouble,omitempty"`
+	AsInt             *int64         `json:"asInt,omitempty,string"`
+}
+
+type otlpHistogramDataPoint struct {
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano uint64         `json:"startTimeUnixNano,omitempty,string"`
+	TimeUnixNano      uint64         `json:"timeUnixNano,string"`
+	Count             uint64         `json:"count,string"`
+	BucketCounts      otlpUint64s    `json:"bucketCounts"`
+	ExplicitBounds    []float64      `json:"explicitBounds"`
+}
+
+// otlpUint64s encodes as a list of strings per the OTLP/JSON mapping
+type otlpUint64s []uint64
+
+func (u otlpUint64s) MarshalJSON() ([]byte, error) {
+	s := make([]string, len(u))
+	for i, v := range u {
+		s[i] = strconv.FormatUint(v, 10)
+	}
+	return json.Marshal(s)
+}
+
+// otlpSink encodes metrics as OTLP ExportMetricsServiceRequests
+// and posts them to an OTLP/HTTP endpoint
+type otlpSink struct {
+	c          *Check
+	client     *http.Client
+	url        string
+	encoding   string
+	headers    http.Header
+	startTime  time.Time
+	deltaStart time.Time // start of the current delta counter interval (last export of delta counters)
+	sync.Mutex
+}
+
+func newOTLPSink(c *Check) (Sink, error) {
+	if c.config.OTLP.URL == "" {
+		return nil, errors.New("invalid otlp url (empty)")
+	}
+
+	encoding := strings.ToLower(c.config.OTLP.Encoding)
+	switch encoding {
+	case "":
+		encoding = OTLPEncodingProtobuf
+	case OTLPEncodingProtobuf, OTLPEncodingJSON:
+	default:
+		return nil, errors.Errorf("invalid otlp encoding (%s), must be %s or %s", c.config.OTLP.Encoding, OTLPEncodingProtobuf, OTLPEncodingJSON)
+	}
+
+	headers := http.Header{}
+	for _, hdr := range strings.Split(c.config.OTLP.Headers, ",") {
+		hdr = strings.TrimSpace(hdr)
+		if hdr == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(hdr, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, errors.Errorf("invalid otlp header (%s), must be key=value", hdr)
+		}
+		headers.Add(strings.TrimSpace(k), strings.TrimSpace(v))
+	}
+	if encoding == OTLPEncodingJSON {
+		headers.Set("Content-Type", "application/json")
+	} else {
+		headers.Set("Content-Type", "application/x-protobuf")
+	}
+
+	start := time.Now()
+
+	return &otlpSink{
+		c:          c,
+		url:        c.config.OTLP.URL,
+		encoding:   encoding,
+		headers:    headers,
+		startTime:  start,
+		deltaStart: start,
+		client:     newSinkHTTPClient(),
+	}, nil
+}
+
+func (s *otlpSink) Name() string {
+	return SinkOTLP
+}
+
+func (s *otlpSink) Submit(ctx context.Context, metrics map[string]MetricSample, resultLogger zerolog.Logger, includeStats bool) error {
+	now := time.Now()
+	s.Lock()
+	deltaStart := s.deltaStart
+	s.Unlock()
+
+	req, numPoints := otlpRequest(metrics, s.startTime, deltaStart, now)
+	if numPoints == 0 {
+		resultLogger.Debug().Int("metrics", len(metrics)).Msg("no metrics convertible to otlp data points")
+		return nil
+	}
+
+	var rawData []byte
+	if s.encoding == OTLPEncodingJSON {
+		data, err := json.Marshal(req)
+		if err != nil {
+			resultLogger.Error().Err(err).Msg("json encoding otlp request")
+			return errors.Wrap(err, "marshaling otlp request")
+		}
+		rawData = data
+	} else {
+		rawData = encodeOTLPRequest(req)
+	}
+
+	headers := s.headers.Clone()
+	subData := rawData
+	if s.c.UseCompression() && len(rawData) > compressionThreshold {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(rawData); err != nil {
+			resultLogger.Error().Err(err).Msg("compressing metrics")
+			return errors.Wrap(err, "compressing metrics")
+		}
+		if err := zw.Close(); err != nil {
+			resultLogger.Error().Err(err).Msg("closing gzip writer")
+			return errors.Wrap(err, "closing gzip writer")
+		}
+		subData = buf.Bytes()
+		headers.Set("Content-Encoding", "gzip")
+	}
+
+	start := time.Now()
+
+	if err := s.c.postSinkData(ctx, s.client, s.url, headers, subData); err != nil {
+		resultLogger.Error().Err(err).Msg("otlp export")
+		return errors.Wrap(err, "otlp export")
+	}
+
+	for _, sample := range metrics {
+		if sample.Kind == KindDeltaCounter {
+			s.Lock()
+			s.deltaStart = now
+			s.Unlock()
+			break
+		}
+	}
+
+	resultLogger.Debug().
+		Str("duration", time.Since(start).String()).
+		Int("sent_metrics", len(metrics)).
+		Int("sent_points", numPoints).
+		Str("bytes_sent", bytefmt.ByteSize(uint64(len(subData)))).
+		Msg("submitted")
+
+	if includeStats {
+		s.c.statsmu.Lock()
+		s.c.stats.SentMetrics += uint64(len(metrics))
+		s.c.stats.SentBytes += uint64(len(rawData))
+		s.c.stats.SentSize += uint64(len(subData))
+		s.c.statsmu.Unlock()
+	}
+
+	return nil
+}
+
+// otlpRequest converts circonus metric samples into an OTLP metrics request, returning
+// the request and the number of data points it contains. Samples are grouped into
+// resources by their k8s stream tags (cluster, namespace, pod, node), all tags are
+// data point attributes. Counters (see SampleKind) become monotonic sums, cumulative
+// from startTime or, for the agent's own counters, delta from deltaStart. Other numeric
+// samples become gauges and cumulative histograms (H) become explicit bucket histograms
+// (see histogramBuckets). Text and interval (h) histogram metrics are skipped.
+func otlpRequest(metrics map[string]MetricSample, startTime, deltaStart, now time.Time) (*otlpMetricsRequest, int) {
+	type resourceGroup struct {
+		rm      *otlpResourceMetrics
+		metrics map[string]*otlpMetric
+	}
+
+	req := &otlpMetricsRequest{}
+	resources := make(map[string]*resourceGroup)
+	numPoints := 0
+
+	// sorted so requests (and data point order) are deterministic
+	names := make([]string, 0, len(metrics))
+	for taggedName := range metrics {
+		names = append(names, taggedName)
+	}
+	sort.Strings(names)
+
+	for _, taggedName := range names {
+		sample := metrics[taggedName]
+		name, streamTags, measurementTags := parseTaggedName(taggedName)
+
+		var resAttrs, dpAttrs []otlpKeyValue
+		seen := make(map[string]bool)
+		for _, tags := range []Tags{streamTags, measurementTags} {
+			for _, tag := range tags {
+				key := tag.Category
+				if rk, ok := otlpResourceTags[key]; ok {
+					key = rk
+				}
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				if key != tag.Category {
+					resAttrs = append(resAttrs, otlpKeyValue{Key: key, Value: otlpAnyValue{StringValue: tag.Value}})
+				}
+				dpAttrs = append(dpAttrs, otlpKeyValue{Key: tag.Category, Value: otlpAnyValue{StringValue: tag.Value}})
+			}
+		}
+		sort.Slice(resAttrs, func(i, j int) bool { return resAttrs[i].Key < resAttrs[j].Key })
+		sort.Slice(dpAttrs, func(i, j int) bool { return dpAttrs[i].Key < dpAttrs[j].Key })
+
+		ts := uint64(now.UnixNano())
+		if sample.Timestamp != 0 {
+			ts = sample.Timestamp * uint64(time.Millisecond)
+		}
+		start := uint64(startTime.UnixNano())
+		if start > ts {
+			start = ts
+		}
+
+		var metric *otlpMetric
+		getMetric := func(kind string) *otlpMetric {
+			resKey := otlpAttrsKey(resAttrs)
+			rg, ok := resources[resKey]
+			if !ok {
+				rg = &resourceGroup{
+					rm: &otlpResourceMetrics{
+						Resource: otlpResource{Attributes: append([]otlpKeyValue{{Key: "service.name", Value: otlpAnyValue{StringValue: release.NAME}}}, resAttrs...)},
+						ScopeMetrics: []*otlpScopeMetrics{{
+							Scope: otlpScope{Name: release.NAME, Version: release.VERSION},
+						}},
+					},
+					metrics: make(map[string]*otlpMetric),
+				}
+				resources[resKey] = rg
+				req.ResourceMetrics = append(req.ResourceMetrics, rg.rm)
+			}
+			m, ok := rg.metrics[kind+name]
+			if !ok {
+				m = &otlpMetric{Name: name}
+				rg.metrics[kind+name] = m
+				rg.rm.ScopeMetrics[0].Metrics = append(rg.rm.ScopeMetrics[0].Metrics, m)
+			}
+			return m
+		}
+
+		switch sample.Type {
+		case MetricTypeCumulativeHistogram:
+			bins, ok := sampleHistogramBins(sample.Value)
+			if !ok {
+				continue
+			}
+			dp := otlpHistogramDataPoint{
+				Attributes:        dpAttrs,
+				StartTimeUnixNano: start,
+				TimeUnixNano:      ts,
+				BucketCounts:      make(otlpUint64s, 0, len(bins)+1),
+				ExplicitBounds:    make([]float64, 0, len(bins)),
+			}
+			overflow := uint64(0)
+			for _, bucket := range histogramBuckets(bins) {
+				dp.Count += bucket.Count
+				if math.IsInf(bucket.UpperBound, +1) {
+					overflow += bucket.Count
+					continue
+				}
+				dp.ExplicitBounds = append(dp.ExplicitBounds, bucket.UpperBound)
+				dp.BucketCounts = append(dp.BucketCounts, bucket.Count)
+			}
+			dp.BucketCounts = append(dp.BucketCounts, overflow)
+			metric = getMetric("histogram")
+			if metric.Histogram == nil {
+				metric.Histogram = &otlpHistogram{AggregationTemporality: otlpTemporalityCumulative}
+			}
+			metric.Histogram.DataPoints = append(metric.Histogram.DataPoints, dp)
+		case MetricTypeString, MetricTypeHistogram:
+			continue
+		default:
+			dp := otlpNumberDataPoint{Attributes: dpAttrs, TimeUnixNano: ts}
+			switch v := sample.Value.(type) {
+			case int32:
+				iv := int64(v)
+				dp.AsInt = &iv
+			case uint32:
+				iv := int64(v)
+				dp.AsInt = &iv
+			case int64:
+				dp.AsInt = &v
+			case int:
+				iv := int64(v)
+				dp.AsInt = &iv
+			case uint64:
+				if v <= math.MaxInt64 {
+					iv := int64(v)
+					dp.AsInt = &iv
+				}
+			}
+			if dp.AsInt == nil {
+				v, ok := sampleValueFloat64(sample.Value)
+				if !ok || math.IsNaN(v) || math.IsInf(v, 0) {
+					continue
+				}
+				dp.AsDouble = &v
+			}
+			switch sample.Kind {
+			case KindCounter:
+				dp.StartTimeUnixNano = start
+				metric = getMetric("sum")
+				if metric.Sum == nil {
+					metric.Sum = &otlpSum{AggregationTemporality: otlpTemporalityCumulative, IsMonotonic: true}
+				}
+				metric.Sum.DataPoints = append(metric.Sum.DataPoints, dp)
+			case KindDeltaCounter:
+				dp.StartTimeUnixNano = uint64(deltaStart.UnixNano())
+				if dp.StartTimeUnixNano > ts {
+					dp.StartTimeUnixNano = ts
+				}
+				metric = getMetric("delta")
+				if metric.Sum == nil {
+					metric.Sum = &otlpSum{AggregationTemporality: otlpTemporalityDelta, IsMonotonic: true}
+				}
+				metric.Sum.DataPoints = append(metric.Sum.DataPoints, dp)
+			default:
+				metric = getMetric("gauge")
+				if metric.Gauge == nil {
+					metric.Gauge = &otlpGauge{}
+				}
+				metric.Gauge.DataPoints = append(metric.Gauge.DataPoints, dp)
+			}
+		}
+		numPoints++
+	}
+
+	return req, numPoints
+}
+
+// otlpAttrsKey returns a key uniquely identifying a (sorted) set of attributes
+func otlpAttrsKey(attrs []otlpKeyValue) string {
+	var sb strings.Builder
+	for _, a := range attrs {
+		sb.WriteString(a.Key)
+		sb.WriteByte('=')
+		sb.WriteString(a.Value.StringValue)
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
+
+// encodeOTLPRequest encodes a request as a binary protobuf ExportMetricsServiceRequest
+// (opentelemetry-proto collector/metrics/v1 and metrics/v1):
+//
+//	ExportMetricsServiceRequest { repeated ResourceMetrics resource_metrics = 1; }
+//	ResourceMetrics      { Resource resource = 1; repeated ScopeMetrics scope_metrics = 2; }
+//	Resource             { repeated KeyValue attributes = 1; }
+//	ScopeMetrics         { InstrumentationScope scope = 1; repeated Metric metrics = 2; }
+//	InstrumentationScope { string name = 1; string version = 2; }
+//	Metric               { string name = 1; Gauge gauge = 5; Sum sum = 7; Histogram histogram = 9; }
+//	Gauge                { repeated NumberDataPoint data_points = 1; }
+//	Sum                  { repeated NumberDataPoint data_points = 1; AggregationTemporality aggregation_temporality = 2; bool is_monotonic = 3; }
+//	Histogram            { repeated HistogramDataPoint data_points = 1; AggregationTemporality aggregation_temporality = 2; }
+//	NumberDataPoint      { fixed64 start_time_unix_nano = 2; fixed64 time_unix_nano = 3; double as_double = 4; sfixed64 as_int = 6; repeated KeyValue attributes = 7; }
+//	HistogramDataPoint   { fixed64 start_time_unix_nano = 2; fixed64 time_unix_nano = 3; fixed64 count = 4; repeated fixed64 bucket_counts = 6; repeated double explicit_bounds = 7; repeated KeyValue attributes = 9; }
+//	KeyValue             { string key = 1; AnyValue value = 2; }
+//	AnyValue             { string string_value = 1; }
+func encodeOTLPRequest(req *otlpMetricsRequest) []byte {
+	var buf []byte
+	for _, rm := range req.ResourceMetrics {
+		buf = appendMessage(buf, 1, encodeOTLPResourceMetrics(rm))
+	}
+	return buf
+}
+
+func encodeOTLPResourceMetrics(rm *otlpResourceMetrics) []byte {
+	var res []byte
+	for _, kv := range rm.Resource.Attributes {
+		res = appendMessage(res, 1, encodeOTLPKeyValue(kv))
+	}
+
+	var buf []byte
+	buf = appendMessage(buf, 1, res)
+	for _, sm := range rm.ScopeMetrics {
+		var scope []byte
+		scope = protowire.AppendTag(scope, 1, protowire.BytesType)
+		scope = protowire.AppendString(scope, sm.Scope.Name)
+		if sm.Scope.Version != "" {
+			scope = protowire.AppendTag(scope, 2, protowire.BytesType)
+			scope = protowire.AppendString(scope, sm.Scope.Version)
+		}
+
+		var smBuf []byte
+		smBuf = appendMessage(smBuf, 1, scope)
+		for _, m := range sm.Metrics {
+			smBuf = appendMessage(smBuf, 2, encodeOTLPMetric(m))
+		}
+		buf = appendMessage(buf, 2, smBuf)
+	}
+	return buf
+}
+
+func encodeOTLPMetric(m *otlpMetric) []byte {
+	var buf []byte
+	buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+	buf = protowire.AppendString(buf, m.Name)
+
+	switch {
+	case m.Gauge != nil:
+		var g []byte
+		for _, dp := range m.Gauge.DataPoints {
+			g = appendMessage(g, 1, encodeOTLPNumberDataPoint(dp))
+		}
+		buf = appendMessage(buf, 5, g)
+	case m.Sum != nil:
+		var s []byte
+		for _, dp := range m.Sum.DataPoints {
+			s = appendMessage(s, 1, encodeOTLPNumberDataPoint(dp))
+		}
+		s = protowire.AppendTag(s, 2, protowire.VarintType)
+		s = protowire.AppendVarint(s, uint64(m.Sum.AggregationTemporality))
+		if m.Sum.IsMonotonic {
+			s = protowire.AppendTag(s, 3, protowire.VarintType)
+			s = protowire.AppendVarint(s, 1)
+		}
+		buf = appendMessage(buf, 7, s)
+	case m.Histogram != nil:
+		var h []byte
+		for _, dp := range m.Histogram.DataPoints {
+			h = appendMessage(h, 1, encodeOTLPHistogramDataPoint(dp))
+		}
+		h = protowire.AppendTag(h, 2, protowire.VarintType)
+		h = protowire.AppendVarint(h, uint64(m.Histogram.AggregationTemporality))
+		buf = appendMessage(buf, 9, h)
+	}
+
+	return buf
+}
+
+func encodeOTLPNumberDataPoint(dp otlpNumberDataPoint) []byte {
+	var buf []byte
+	if dp.StartTimeUnixNano != 0 {
+		buf = protowire.AppendTag(buf, 2, protowire.Fixed64Type)
+		buf = protowire.AppendFixed64(buf, dp.StartTimeUnixNano)
+	}
+	buf = protowire.AppendTag(buf, 3, protowire.Fixed64Type)
+	buf = protowire.AppendFixed64(buf, dp.TimeUnixNano)
+	if dp.AsDouble != nil {
+		buf = protowire.AppendTag(buf, 4, protowire.Fixed64Type)
+		buf = protowire.AppendFixed64(buf, math.Float64bits(*dp.AsDouble))
+	}
+	if dp.AsInt != nil {
+		buf = protowire.AppendTag(buf, 6, protowire.Fixed64Type)
+		buf = protowire.AppendFixed64(buf, uint64(*dp.AsInt))
+	}
+	for _, kv := range dp.Attributes {
+		buf = appendMessage(buf, 7, encodeOTLPKeyValue(kv))
+	}
+	return buf
+}
+
+func encodeOTLPHistogramDataPoint(dp otlpHistogramDataPoint) []byte {
+	var buf []byte
+	if dp.StartTimeUnixNano != 0 {
+		buf = protowire.AppendTag(buf, 2, protowire.Fixed64Type)
+		buf = protowire.AppendFixed64(buf, dp.StartTimeUnixNano)
+	}
+	buf = protowire.AppendTag(buf, 3, protowire.Fixed64Type)
+	buf = protowire.AppendFixed64(buf, dp.TimeUnixNano)
+	buf = protowire.AppendTag(buf, 4, protowire.Fixed64Type)
+	buf = protowire.AppendFixed64(buf, dp.Count)
+
+	// packed repeated fields
+	packed := make([]byte, 0, 8*len(dp.BucketCounts))
+	for _, c := range dp.BucketCounts {
+		packed = protowire.AppendFixed64(packed, c)
+	}
+	buf = appendMessage(buf, 6, packed)
+	if len(dp.ExplicitBounds) > 0 {
+		packed = packed[:0]
+		for _, b := range dp.ExplicitBounds {
+			packed = protowire.AppendFixed64(packed, math.Float64bits(b))
+		}
+		buf = appendMessage(buf, 7, packed)
+	}
+
+	for _, kv := range dp.Attributes {
+		buf = appendMessage(buf, 9, encodeOTLPKeyValue(kv))
+	}
+	return buf
+}
+
+func encodeOTLPKeyValue(kv otlpKeyValue) []byte {
+	var val []byte
+	val = protowire.AppendTag(val, 1, protowire.BytesType)
+	val = protowire.AppendString(val, kv.Value.StringValue)
+
+	var buf []byte
+	buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+	buf = protowire.AppendString(buf, kv.Key)
+	buf = appendMessage(buf, 2, val)
+	return buf
+}
+
+// appendMessage appends a length delimited (embedded message or packed) field
+func appendMessage(buf []byte, num protowire.Number, msg []byte) []byte {
+	buf = protowire.AppendTag(buf, num, protowire.BytesType)
+	return protowire.AppendBytes(buf, msg)
+}
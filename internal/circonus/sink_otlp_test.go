@@ -0,0 +1,283 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package circonus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+	"github.com/rs/zerolog"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func otlpTestMetrics(t *testing.T, c *Check, ts time.Time) map[string]MetricSample {
+	t.Helper()
+
+	streamTags := []string{"cluster:test", "namespace:default", "pod:p1", "container:app"}
+	metrics := make(map[string]MetricSample)
+	if err := c.QueueMetricSample(metrics, "cpu_usage", MetricTypeFloat64, streamTags, nil, 1.5, &ts); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if err := c.QueueCounterSample(metrics, "requests_total", MetricTypeUint64, streamTags, nil, uint64(10), &ts); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	// a gauge, regardless of its name
+	if err := c.QueueMetricSample(metrics, "pods_count", MetricTypeUint64, streamTags, nil, uint64(3), &ts); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	// as encoded by promtext, bucket upper bounds scaled by 0.999
+	if err := c.QueueMetricSample(metrics, "request_duration", MetricTypeCumulativeHistogram, streamTags, nil, []string{"H[9.990000e-02]=2", "H[4.995000e-01]=3", "H[1.000000e+128]=1"}, &ts); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if err := c.QueueMetricSample(metrics, "events", MetricTypeString, streamTags, nil, "text", &ts); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	// an agent counter, reset on each flush
+	metrics[c.taggedName("collect_submit_retries", c.NewTagList(streamTags), nil)] = MetricSample{Type: MetricTypeUint64, Value: uint64(2), Timestamp: makeTimestamp(&ts), Kind: KindDeltaCounter}
+	return metrics
+}
+
+func TestOTLPSinkJSON(t *testing.T) {
+	t.Log("Testing otlp sink (json)")
+
+	var received otlpJSONRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("expected json content type, got %q", r.Header.Get("Content-Type"))
+		}
+		if r.Header.Get("X-Api-Key") != "foo" {
+			t.Errorf("expected api key header, got %q", r.Header.Get("X-Api-Key"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("decoding request: %s", err)
+		}
+	}))
+	defer srv.Close()
+
+	c := &Check{
+		config: &config.Circonus{
+			Sinks:      SinkOTLP,
+			Base64Tags: true,
+			OTLP:       config.OTLP{URL: srv.URL, Encoding: OTLPEncodingJSON, Headers: "X-Api-Key=foo"},
+		},
+		log:            zerolog.Nop(),
+		submitDeadline: 5 * time.Second,
+	}
+	if err := c.initializeSinks(); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	ts := time.Unix(1700000000, 0)
+	if err := c.FlushCollectorMetrics(context.Background(), otlpTestMetrics(t, c, ts), zerolog.Nop(), true); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	if len(received.ResourceMetrics) != 1 {
+		t.Fatalf("expected 1 resource, got %d", len(received.ResourceMetrics))
+	}
+	rm := received.ResourceMetrics[0]
+	resAttrs := map[string]string{}
+	for _, a := range rm.Resource.Attributes {
+		resAttrs[a.Key] = a.Value.StringValue
+	}
+	for k, v := range map[string]string{"k8s.cluster.name": "test", "k8s.namespace.name": "default", "k8s.pod.name": "p1"} {
+		if resAttrs[k] != v {
+			t.Fatalf("expected resource attribute %s=%s, got %v", k, v, resAttrs)
+		}
+	}
+	if len(rm.ScopeMetrics) != 1 || len(rm.ScopeMetrics[0].Metrics) != 5 {
+		t.Fatalf("expected 5 metrics, got %+v", rm.ScopeMetrics)
+	}
+
+	dpAttrs := func(attrs []otlpKeyValue) string {
+		kv := make([]string, 0, len(attrs))
+		for _, a := range attrs {
+			kv = append(kv, a.Key+"="+a.Value.StringValue)
+		}
+		return strings.Join(kv, ",")
+	}
+
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		switch m.Name {
+		case "cpu_usage":
+			if m.Gauge == nil || len(m.Gauge.DataPoints) != 1 {
+				t.Fatalf("expected gauge, got %+v", m)
+			}
+			dp := m.Gauge.DataPoints[0]
+			if dp.AsDouble == nil || *dp.AsDouble != 1.5 {
+				t.Fatalf("expected 1.5, got %+v", dp)
+			}
+			if dp.TimeUnixNano != "1700000000000000000" {
+				t.Fatalf("expected timestamp 1700000000000000000, got %s", dp.TimeUnixNano)
+			}
+			// k8s tags are resource attributes and data point attributes
+			if a := dpAttrs(dp.Attributes); a != "cluster=test,container=app,namespace=default,pod=p1" {
+				t.Fatalf("expected all tags as attributes, got %s", a)
+			}
+		case "pods_count":
+			if m.Gauge == nil || len(m.Gauge.DataPoints) != 1 || m.Gauge.DataPoints[0].AsInt != "3" {
+				t.Fatalf("expected gauge, got %+v", m)
+			}
+		case "requests_total":
+			if m.Sum == nil || !m.Sum.IsMonotonic || m.Sum.AggregationTemporality != otlpTemporalityCumulative {
+				t.Fatalf("expected cumulative monotonic sum, got %+v", m)
+			}
+			if dp := m.Sum.DataPoints[0]; dp.AsInt != "10" {
+				t.Fatalf("expected 10, got %+v", dp)
+			}
+		case "collect_submit_retries":
+			if m.Sum == nil || !m.Sum.IsMonotonic || m.Sum.AggregationTemporality != otlpTemporalityDelta {
+				t.Fatalf("expected delta monotonic sum, got %+v", m)
+			}
+			if dp := m.Sum.DataPoints[0]; dp.AsInt != "2" || dp.StartTimeUnixNano == "" {
+				t.Fatalf("expected 2 with start time, got %+v", dp)
+			}
+		case "request_duration":
+			if m.Histogram == nil || len(m.Histogram.DataPoints) != 1 {
+				t.Fatalf("expected histogram, got %+v", m)
+			}
+			dp := m.Histogram.DataPoints[0]
+			if dp.Count != "6" {
+				t.Fatalf("expected count 6, got %s", dp.Count)
+			}
+			if len(dp.ExplicitBounds) != 2 || dp.ExplicitBounds[0] != 0.1 || dp.ExplicitBounds[1] != 0.5 {
+				t.Fatalf("expected bounds [0.1 0.5], got %v", dp.ExplicitBounds)
+			}
+			if len(dp.BucketCounts) != 3 || dp.BucketCounts[0] != "2" || dp.BucketCounts[1] != "3" || dp.BucketCounts[2] != "1" {
+				t.Fatalf("expected bucket counts [2 3 1], got %v", dp.BucketCounts)
+			}
+		default:
+			t.Fatalf("unexpected metric %s", m.Name)
+		}
+	}
+}
+
+func TestOTLPSinkProtobuf(t *testing.T) {
+	t.Log("Testing otlp sink (protobuf)")
+
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/x-protobuf" {
+			t.Errorf("expected protobuf content type, got %q", r.Header.Get("Content-Type"))
+		}
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			t.Errorf("reading body: %s", err)
+		}
+	}))
+	defer srv.Close()
+
+	c := &Check{
+		config: &config.Circonus{
+			Sinks:      SinkOTLP,
+			Base64Tags: true,
+			OTLP:       config.OTLP{URL: srv.URL},
+		},
+		log:            zerolog.Nop(),
+		submitDeadline: 5 * time.Second,
+	}
+	if err := c.initializeSinks(); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	ts := time.Unix(1700000000, 0)
+	metrics := otlpTestMetrics(t, c, ts)
+	if err := c.FlushCollectorMetrics(context.Background(), metrics, zerolog.Nop(), true); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	if len(body) == 0 {
+		t.Fatal("expected request body")
+	}
+	for b := body; len(b) > 0; {
+		num, typ, n := protowire.ConsumeField(b)
+		if n < 0 {
+			t.Fatalf("consuming field: %s", protowire.ParseError(n))
+		}
+		if num != 1 || typ != protowire.BytesType {
+			t.Fatalf("expected resource_metrics (1), got %d (%d)", num, typ)
+		}
+		b = b[n:]
+	}
+	for _, s := range []string{"k8s.cluster.name", "cpu_usage", "requests_total", "request_duration"} {
+		if !bytes.Contains(body, []byte(s)) {
+			t.Fatalf("expected %s in request", s)
+		}
+	}
+}
+
+func TestOTLPSinkConfig(t *testing.T) {
+	t.Log("Testing otlp sink config")
+
+	t.Log("no url")
+	{
+		c := &Check{config: &config.Circonus{Sinks: SinkOTLP}, log: zerolog.Nop()}
+		if err := c.initializeSinks(); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+
+	t.Log("invalid encoding")
+	{
+		c := &Check{config: &config.Circonus{Sinks: SinkOTLP, OTLP: config.OTLP{URL: "http://localhost", Encoding: "xml"}}, log: zerolog.Nop()}
+		if err := c.initializeSinks(); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+
+	t.Log("invalid header")
+	{
+		c := &Check{config: &config.Circonus{Sinks: SinkOTLP, OTLP: config.OTLP{URL: "http://localhost", Headers: "foo"}}, log: zerolog.Nop()}
+		if err := c.initializeSinks(); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+}
+
+// otlpJSONRequest decodes the OTLP/JSON encoding as a collector would (64 bit integers as strings)
+type otlpJSONRequest struct {
+	ResourceMetrics []struct {
+		Resource struct {
+			Attributes []otlpKeyValue `json:"attributes"`
+		} `json:"resource"`
+		ScopeMetrics []struct {
+			Metrics []struct {
+				Name  string `json:"name"`
+				Gauge *struct {
+					DataPoints []otlpJSONNumberDataPoint `json:"dataPoints"`
+				} `json:"gauge"`
+				Sum *struct {
+					DataPoints             []otlpJSONNumberDataPoint `json:"dataPoints"`
+					AggregationTemporality int                       `json:"aggregationTemporality"`
+					IsMonotonic            bool                      `json:"isMonotonic"`
+				} `json:"sum"`
+				Histogram *struct {
+					DataPoints []struct {
+						Count          string    `json:"count"`
+						BucketCounts   []string  `json:"bucketCounts"`
+						ExplicitBounds []float64 `json:"explicitBounds"`
+					} `json:"dataPoints"`
+				} `json:"histogram"`
+			} `json:"metrics"`
+		} `json:"scopeMetrics"`
+	} `json:"resourceMetrics"`
+}
+
+type otlpJSONNumberDataPoint struct {
+	Attributes        []otlpKeyValue `json:"attributes"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	TimeUnixNano      string         `json:"timeUnixNano"`
+	AsDouble          *float64       `json:"asDouble"`
+	AsInt             string         `json:"asInt"`
+}
@@ -0,0 +1,289 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package circonus
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/bytefmt"
+	"github.com/klauspost/compress/s2"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// SinkPromRemoteWrite sends metrics to a prometheus remote-write endpoint
+const SinkPromRemoteWrite = "prometheus-remote-write"
+
+func init() {
+	RegisterSink(SinkPromRemoteWrite, newRemoteWriteSink)
+}
+
+// promLabel is a prometheus remote-write label (prompb.Label)
+type promLabel struct {
+	Name  string
+	Value string
+}
+
+// promSeries is a prometheus remote-write time series with a single sample (prompb.TimeSeries)
+type promSeries struct {
+	Labels    []promLabel
+	Value     float64
+	Timestamp int64
+}
+
+// remoteWriteSink encodes metrics as snappy compressed protobuf
+// prometheus remote-write (v1) requests
+type remoteWriteSink struct {
+	c           *Check
+	client      *http.Client
+	url         string
+	bearerToken string
+}
+
+func newRemoteWriteSink(c *Check) (Sink, error) {
+	if c.config.RemoteWrite.URL == "" {
+		return nil, errors.New("invalid remote write url (empty)")
+	}
+
+	return &remoteWriteSink{
+		c:           c,
+		url:         c.config.RemoteWrite.URL,
+		bearerToken: c.config.RemoteWrite.BearerToken,
+		client:      newSinkHTTPClient(),
+	}, nil
+}
+
+func (s *remoteWriteSink) Name() string {
+	return SinkPromRemoteWrite
+}
+
+func (s *remoteWriteSink) Submit(ctx context.Context, metrics map[string]MetricSample, resultLogger zerolog.Logger, includeStats bool) error {
+	series, skipped := remoteWriteSeries(metrics, time.Now())
+	if skipped > 0 {
+		resultLogger.Debug().Int("skipped", skipped).Msg("metrics not representable as remote write series (text, interval histogram or invalid value)")
+	}
+	if len(series) == 0 {
+		resultLogger.Debug().Int("metrics", len(metrics)).Msg("no metrics convertible to remote write series")
+		return nil
+	}
+
+	rawData := encodeWriteRequest(series)
+	subData := s2.EncodeSnappy(nil, rawData)
+
+	start := time.Now()
+
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/x-protobuf")
+	headers.Set("Content-Encoding", "snappy")
+	headers.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if s.bearerToken != "" {
+		headers.Set("Authorization", "Bearer "+s.bearerToken)
+	}
+
+	if err := s.c.postSinkData(ctx, s.client, s.url, headers, subData); err != nil {
+		resultLogger.Error().Err(err).Msg("remote write")
+		return errors.Wrap(err, "remote write")
+	}
+
+	resultLogger.Debug().
+		Str("duration", time.Since(start).String()).
+		Int("sent_metrics", len(metrics)).
+		Int("sent_series", len(series)).
+		Str("bytes_sent", bytefmt.ByteSize(uint64(len(subData)))).
+		Msg("submitted")
+
+	if includeStats {
+		s.c.statsmu.Lock()
+		s.c.stats.SentMetrics += uint64(len(metrics))
+		s.c.stats.SentBytes += uint64(len(rawData))
+		s.c.stats.SentSize += uint64(len(subData))
+		s.c.statsmu.Unlock()
+	}
+
+	return nil
+}
+
+// remoteWriteSeries converts circonus metric samples into prometheus time series.
+// Stream and measurement tags become labels, cumulative histograms (H) become
+// _bucket series with an le label (see histogramBuckets, bounds which are not
+// recoverable from the circonus bins are approximate). Text and interval (h)
+// histogram metrics are not representable, they are skipped and counted.
+func remoteWriteSeries(metrics map[string]MetricSample, now time.Time) ([]promSeries, int) {
+	series := make([]promSeries, 0, len(metrics))
+	skipped := 0
+
+	for taggedName, sample := range metrics {
+		name, streamTags, measurementTags := parseTaggedName(taggedName)
+		name = promName(name)
+
+		labels := make([]promLabel, 0, len(streamTags)+len(measurementTags)+2)
+		seen := make(map[string]bool)
+		for _, tags := range []Tags{streamTags, measurementTags} {
+			for _, tag := range tags {
+				ln := promLabelName(tag.Category)
+				if ln == "__name__" || seen[ln] {
+					continue
+				}
+				seen[ln] = true
+				labels = append(labels, promLabel{Name: ln, Value: tag.Value})
+			}
+		}
+
+		ts := int64(sample.Timestamp)
+		if ts == 0 {
+			ts = now.UnixMilli()
+		}
+
+		switch sample.Type {
+		case MetricTypeCumulativeHistogram:
+			bins, ok := sampleHistogramBins(sample.Value)
+			if !ok {
+				skipped++
+				continue
+			}
+			cumulative := uint64(0)
+			sawInf := false
+			for _, bucket := range histogramBuckets(bins) {
+				cumulative += bucket.Count
+				le := "+Inf"
+				if math.IsInf(bucket.UpperBound, +1) {
+					sawInf = true
+				} else {
+					le = strconv.FormatFloat(bucket.UpperBound, 'g', -1, 64)
+				}
+				series = append(series, newPromSeries(name+"_bucket", labels, promLabel{Name: "le", Value: le}, float64(cumulative), ts))
+			}
+			if !sawInf {
+				series = append(series, newPromSeries(name+"_bucket", labels, promLabel{Name: "le", Value: "+Inf"}, float64(cumulative), ts))
+			}
+		case MetricTypeString, MetricTypeHistogram:
+			skipped++
+		default:
+			v, ok := sampleValueFloat64(sample.Value)
+			if !ok || math.IsNaN(v) {
+				skipped++
+				continue
+			}
+			series = append(series, newPromSeries(name, labels, promLabel{}, v, ts))
+		}
+	}
+
+	return series, skipped
+}
+
+// newPromSeries creates a series with the metric name and (optional) extra label, labels sorted by name
+func newPromSeries(name string, labels []promLabel, extra promLabel, value float64, ts int64) promSeries {
+	ls := make([]promLabel, 0, len(labels)+2)
+	ls = append(ls, promLabel{Name: "__name__", Value: name})
+	for _, l := range labels {
+		if extra.Name != "" && l.Name == extra.Name {
+			continue
+		}
+		ls = append(ls, l)
+	}
+	if extra.Name != "" {
+		ls = append(ls, extra)
+	}
+	sort.Slice(ls, func(i, j int) bool { return ls[i].Name < ls[j].Name })
+
+	return promSeries{Labels: ls, Value: value, Timestamp: ts}
+}
+
+// promName converts a circonus metric name into a valid prometheus metric name
+func promName(name string) string {
+	return sanitizePromName(name, true)
+}
+
+// promLabelName converts a circonus tag category into a valid prometheus label name
+func promLabelName(name string) string {
+	return sanitizePromName(name, false)
+}
+
+func sanitizePromName(name string, allowColon bool) string {
+	var sb strings.Builder
+	sb.Grow(len(name))
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			sb.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				sb.WriteRune('_')
+			}
+			sb.WriteRune(r)
+		case r == ':' && allowColon:
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('_')
+		}
+	}
+	if sb.Len() == 0 {
+		return "_"
+	}
+	return sb.String()
+}
+
+// encodeWriteRequest encodes time series as a prometheus remote-write
+// protobuf WriteRequest (prompb):
+//
+//	WriteRequest { repeated TimeSeries timeseries = 1; }
+//	TimeSeries   { repeated Label labels = 1; repeated Sample samples = 2; }
+//	Label        { string name = 1; string value = 2; }
+//	Sample       { double value = 1; int64 timestamp = 2; }
+func encodeWriteRequest(series []promSeries) []byte {
+	var buf []byte
+	var tsBuf, lblBuf, smplBuf []byte
+
+	for _, s := range series {
+		tsBuf = tsBuf[:0]
+		for _, l := range s.Labels {
+			lblBuf = lblBuf[:0]
+			lblBuf = protowire.AppendTag(lblBuf, 1, protowire.BytesType)
+			lblBuf = protowire.AppendString(lblBuf, l.Name)
+			lblBuf = protowire.AppendTag(lblBuf, 2, protowire.BytesType)
+			lblBuf = protowire.AppendString(lblBuf, l.Value)
+
+			tsBuf = protowire.AppendTag(tsBuf, 1, protowire.BytesType)
+			tsBuf = protowire.AppendBytes(tsBuf, lblBuf)
+		}
+
+		smplBuf = smplBuf[:0]
+		smplBuf = protowire.AppendTag(smplBuf, 1, protowire.Fixed64Type)
+		smplBuf = protowire.AppendFixed64(smplBuf, math.Float64bits(s.Value))
+		smplBuf = protowire.AppendTag(smplBuf, 2, protowire.VarintType)
+		smplBuf = protowire.AppendVarint(smplBuf, uint64(s.Timestamp))
+
+		tsBuf = protowire.AppendTag(tsBuf, 2, protowire.BytesType)
+		tsBuf = protowire.AppendBytes(tsBuf, smplBuf)
+
+		buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, tsBuf)
+	}
+
+	return buf
+}
+
+// String returns the series in prometheus text exposition form (debugging)
+func (s promSeries) String() string {
+	name := ""
+	lbls := make([]string, 0, len(s.Labels))
+	for _, l := range s.Labels {
+		if l.Name == "__name__" {
+			name = l.Value
+			continue
+		}
+		lbls = append(lbls, fmt.Sprintf("%s=%q", l.Name, l.Value))
+	}
+	return fmt.Sprintf("%s{%s} %v %d", name, strings.Join(lbls, ","), s.Value, s.Timestamp)
+}
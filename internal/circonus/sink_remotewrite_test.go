@@ -0,0 +1,284 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package circonus
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+	"github.com/klauspost/compress/s2"
+	"github.com/rs/zerolog"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// decodeWriteRequest is a minimal prompb.WriteRequest decoder for verifying encoded requests
+func decodeWriteRequest(t *testing.T, data []byte) []promSeries {
+	t.Helper()
+
+	consume := func(b []byte) (protowire.Number, protowire.Type, []byte, uint64, []byte) {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatalf("consuming tag: %s", protowire.ParseError(n))
+		}
+		b = b[n:]
+		switch typ {
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				t.Fatalf("consuming bytes: %s", protowire.ParseError(n))
+			}
+			return num, typ, v, 0, b[n:]
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				t.Fatalf("consuming varint: %s", protowire.ParseError(n))
+			}
+			return num, typ, nil, v, b[n:]
+		case protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			if n < 0 {
+				t.Fatalf("consuming fixed64: %s", protowire.ParseError(n))
+			}
+			return num, typ, nil, v, b[n:]
+		default:
+			t.Fatalf("unexpected wire type %d", typ)
+		}
+		return 0, 0, nil, 0, nil
+	}
+
+	var series []promSeries
+	for len(data) > 0 {
+		_, _, tsData, _, rest := consume(data)
+		data = rest
+		var s promSeries
+		for len(tsData) > 0 {
+			num, _, msg, _, rest := consume(tsData)
+			tsData = rest
+			switch num {
+			case 1:
+				var l promLabel
+				for len(msg) > 0 {
+					n, _, v, _, r := consume(msg)
+					msg = r
+					if n == 1 {
+						l.Name = string(v)
+					} else {
+						l.Value = string(v)
+					}
+				}
+				s.Labels = append(s.Labels, l)
+			case 2:
+				for len(msg) > 0 {
+					n, _, _, v, r := consume(msg)
+					msg = r
+					if n == 1 {
+						s.Value = math.Float64frombits(v)
+					} else {
+						s.Timestamp = int64(v)
+					}
+				}
+			}
+		}
+		series = append(series, s)
+	}
+
+	return series
+}
+
+func TestRemoteWriteSink(t *testing.T) {
+	t.Log("Testing prometheus remote write sink")
+
+	var mu sync.Mutex
+	var received []promSeries
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "snappy" {
+			t.Errorf("expected snappy encoding, got %q", r.Header.Get("Content-Encoding"))
+		}
+		if r.Header.Get("Authorization") != "Bearer foo" {
+			t.Errorf("expected bearer token, got %q", r.Header.Get("Authorization"))
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("reading body: %s", err)
+		}
+		data, err := s2.Decode(nil, body)
+		if err != nil {
+			t.Errorf("decoding snappy: %s", err)
+		}
+		mu.Lock()
+		received = append(received, decodeWriteRequest(t, data)...)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := &Check{
+		config: &config.Circonus{
+			Sinks:       SinkPromRemoteWrite,
+			Base64Tags:  true,
+			RemoteWrite: config.RemoteWrite{URL: srv.URL, BearerToken: "foo"},
+		},
+		log:            zerolog.Nop(),
+		submitDeadline: 5 * time.Second,
+	}
+	if err := c.initializeSinks(); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	ts := time.Unix(1700000000, 0)
+	streamTags := []string{"cluster:test", "node:n1", "pod.name:foo"}
+	metrics := make(map[string]MetricSample)
+	if err := c.QueueMetricSample(metrics, "node_load", MetricTypeFloat64, streamTags, nil, 1.5, &ts); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	// as encoded by promtext, bucket upper bounds scaled by 0.999
+	if err := c.QueueMetricSample(metrics, "request_duration", MetricTypeCumulativeHistogram, streamTags, nil, []string{"H[9.990000e-02]=2", "H[4.995000e-01]=3", "H[1.000000e+128]=1"}, &ts); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if err := c.QueueMetricSample(metrics, "events", MetricTypeString, streamTags, nil, "text", &ts); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	if err := c.FlushCollectorMetrics(context.Background(), metrics, zerolog.Nop(), true); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	got := make([]string, 0, len(received))
+	for _, s := range received {
+		if s.Timestamp != ts.UnixMilli() {
+			// histograms are not timestamped, they are sent with the current time
+			if s.Timestamp < ts.UnixMilli() {
+				t.Fatalf("unexpected timestamp %s", s)
+			}
+			s.Timestamp = 0
+		}
+		got = append(got, s.String())
+	}
+	sort.Strings(got)
+
+	expect := []string{
+		`node_load{cluster="test",node="n1",pod_name="foo"} 1.5 1700000000000`,
+		`request_duration_bucket{cluster="test",le="+Inf",node="n1",pod_name="foo"} 6 0`,
+		`request_duration_bucket{cluster="test",le="0.1",node="n1",pod_name="foo"} 2 0`,
+		`request_duration_bucket{cluster="test",le="0.5",node="n1",pod_name="foo"} 5 0`,
+	}
+
+	if len(got) != len(expect) {
+		t.Fatalf("expected %d series, got %d (%v)", len(expect), len(got), got)
+	}
+	for i := range expect {
+		if got[i] != expect[i] {
+			t.Fatalf("expected %s, got %s", expect[i], got[i])
+		}
+	}
+}
+
+func TestRemoteWriteSeriesSkipped(t *testing.T) {
+	t.Log("Testing remoteWriteSeries skipped metrics")
+
+	metrics := map[string]MetricSample{
+		"gauge":     {Type: MetricTypeFloat64, Value: 1.5},
+		"text":      {Type: MetricTypeString, Value: "text"},
+		"interval":  {Type: MetricTypeHistogram, Value: []string{"H[1.0e+00]=1"}},
+		"invalid":   {Type: MetricTypeCumulativeHistogram, Value: "invalid"},
+		"histogram": {Type: MetricTypeCumulativeHistogram, Value: []string{"H[1.0e+00]=1"}},
+	}
+
+	series, skipped := remoteWriteSeries(metrics, time.Now())
+	if skipped != 3 {
+		t.Fatalf("expected 3 skipped, got %d", skipped)
+	}
+	// gauge, histogram le=1.1 and le=+Inf
+	if len(series) != 3 {
+		t.Fatalf("expected 3 series, got %d", len(series))
+	}
+}
+
+func TestHistogramBuckets(t *testing.T) {
+	t.Log("Testing histogramBuckets")
+
+	t.Log("promtext bounds (scaled by 0.999)")
+	{
+		bounds := []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 60, 300, 1e6}
+		bins := make([]histogramBin, 0, len(bounds))
+		for _, b := range bounds {
+			bins = append(bins, histogramBin{Bound: b * 0.999, Count: 1})
+		}
+		buckets := histogramBuckets(bins)
+		if len(buckets) != len(bounds) {
+			t.Fatalf("expected %d buckets, got %d", len(bounds), len(buckets))
+		}
+		for i, b := range bounds {
+			if buckets[i].UpperBound != b {
+				t.Fatalf("expected bound %g, got %g", b, buckets[i].UpperBound)
+			}
+		}
+	}
+
+	t.Log("approximate bounds (more than two significant digits)")
+	{
+		buckets := histogramBuckets([]histogramBin{{Bound: 0.125 * 0.999, Count: 1}, {Bound: 0.126 * 0.999, Count: 2}})
+		if len(buckets) != 1 || buckets[0].UpperBound != 0.13 || buckets[0].Count != 3 {
+			t.Fatalf("expected single 0.13 bucket with 3, got %+v", buckets)
+		}
+	}
+
+	t.Log("circonus bin values")
+	{
+		bins := []histogramBin{
+			{Bound: -0.5, Count: 1},
+			{Bound: -0.4995, Count: 1},
+			{Bound: 0, Count: 2},
+			{Bound: 0.3, Count: 3},
+			{Bound: 1, Count: 4},
+			{Bound: 1e+128, Count: 5},
+		}
+		expect := []histogramBucket{
+			{UpperBound: -0.5, Count: 1},
+			{UpperBound: -0.49, Count: 1},
+			{UpperBound: 0, Count: 2},
+			{UpperBound: 0.31, Count: 3},
+			{UpperBound: 1.1, Count: 4},
+			{UpperBound: math.Inf(+1), Count: 5},
+		}
+		buckets := histogramBuckets(bins)
+		if len(buckets) != len(expect) {
+			t.Fatalf("expected %v, got %v", expect, buckets)
+		}
+		for i := range expect {
+			if buckets[i] != expect[i] {
+				t.Fatalf("expected %+v, got %+v", expect[i], buckets[i])
+			}
+		}
+	}
+}
+
+func TestParseTaggedName(t *testing.T) {
+	t.Log("Testing parseTaggedName")
+
+	for _, useBase64 := range []bool{true, false} {
+		c := &Check{config: &config.Circonus{Base64Tags: useBase64}}
+		tn := c.taggedName("foo", []string{"a:1", "b:x:y"}, []string{"c:2"})
+		name, st, mt := parseTaggedName(tn)
+		if name != "foo" {
+			t.Fatalf("expected foo, got %s", name)
+		}
+		if len(st) != 2 || st[0] != (Tag{Category: "a", Value: "1"}) || st[1] != (Tag{Category: "b", Value: "x:y"}) {
+			t.Fatalf("unexpected stream tags %v (%s)", st, tn)
+		}
+		if len(mt) != 1 || mt[0] != (Tag{Category: "c", Value: "2"}) {
+			t.Fatalf("unexpected measurement tags %v (%s)", mt, tn)
+		}
+	}
+}
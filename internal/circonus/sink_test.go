@@ -0,0 +1,173 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package circonus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	cgm "github.com/circonus-labs/circonus-gometrics/v3"
+	"github.com/circonus-labs/circonus-gometrics/v3/checkmgr"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+	"github.com/rs/zerolog"
+)
+
+func TestSinkNames(t *testing.T) {
+	t.Log("Testing sinkNames")
+
+	t.Log("default")
+	{
+		c := &Check{config: &config.Circonus{}}
+		names := c.sinkNames()
+		if len(names) != 1 || names[0] != SinkCirconusTrap {
+			t.Fatalf("expected [%s], got %v", SinkCirconusTrap, names)
+		}
+	}
+
+	t.Log("dry run")
+	{
+		c := &Check{config: &config.Circonus{DryRun: true, Sinks: SinkCirconusTrap + "," + SinkFile}}
+		names := c.sinkNames()
+		if len(names) != 1 || names[0] != SinkStdoutJSON {
+			t.Fatalf("expected [%s], got %v", SinkStdoutJSON, names)
+		}
+	}
+
+	t.Log("list")
+	{
+		c := &Check{config: &config.Circonus{Sinks: " file, stdout-json ,"}}
+		names := c.sinkNames()
+		if len(names) != 2 || names[0] != SinkFile || names[1] != SinkStdoutJSON {
+			t.Fatalf("expected [%s %s], got %v", SinkFile, SinkStdoutJSON, names)
+		}
+	}
+}
+
+func TestInitializeSinks(t *testing.T) {
+	t.Log("Testing initializeSinks")
+
+	t.Log("unknown sink")
+	{
+		c := &Check{config: &config.Circonus{Sinks: "foo"}, log: zerolog.Nop()}
+		if err := c.initializeSinks(); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+
+	t.Log("file sink, no file")
+	{
+		c := &Check{config: &config.Circonus{Sinks: SinkFile}, log: zerolog.Nop()}
+		if err := c.initializeSinks(); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	t.Log("Testing file sink")
+
+	fn := filepath.Join(t.TempDir(), "metrics.json")
+	c := &Check{
+		config:         &config.Circonus{Sinks: SinkFile, SinkFile: fn},
+		log:            zerolog.Nop(),
+		submitDeadline: 5 * time.Second,
+	}
+	if err := c.initializeSinks(); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		metrics := map[string]MetricSample{
+			"foo": {Type: MetricTypeUint64, Value: uint64(i)},
+		}
+		if err := c.FlushCollectorMetrics(context.Background(), metrics, zerolog.Nop(), true); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+	}
+
+	fh, err := os.Open(fn)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	defer fh.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(fh)
+	for scanner.Scan() {
+		var metrics map[string]MetricSample
+		if err := json.Unmarshal(scanner.Bytes(), &metrics); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if _, ok := metrics["foo"]; !ok {
+			t.Fatalf("expected metric foo, got %v", metrics)
+		}
+		lines++
+	}
+	if lines != 2 {
+		t.Fatalf("expected 2 batches, got %d", lines)
+	}
+
+	if stats := c.SubmitStats(); stats.SentMetrics != 2 {
+		t.Fatalf("expected 2 sent metrics, got %d", stats.SentMetrics)
+	}
+}
+
+func TestFlushCGMKinds(t *testing.T) {
+	t.Log("Testing FlushCGM sample kinds")
+
+	m, err := cgm.New(&cgm.Config{Interval: "0", CheckManager: checkmgr.Config{Check: checkmgr.CheckConfig{SubmissionURL: "http://127.0.0.1:1"}}})
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	ts := &testSink{}
+	c := &Check{
+		config:         &config.Circonus{},
+		log:            zerolog.Nop(),
+		submitDeadline: 5 * time.Second,
+		sinks:          []Sink{ts},
+		metrics:        m,
+	}
+
+	tags := cgm.Tags{cgm.Tag{Category: "source", Value: "test"}}
+	c.IncrementCounter("requests", tags)
+	c.SetCounter("retries", tags, 2)
+	c.AddGauge("goroutines", tags, uint64(10))
+
+	now := time.Now()
+	c.FlushCGM(context.Background(), &now, zerolog.Nop(), false)
+
+	batches := ts.received()
+	if len(batches) != 1 || len(batches[0]) != 3 {
+		t.Fatalf("expected 1 batch of 3 metrics, got %v", batches)
+	}
+	for name, sample := range batches[0] {
+		expect := KindDeltaCounter
+		if strings.HasPrefix(name, "goroutines") {
+			expect = KindGauge
+		}
+		if sample.Kind != expect {
+			t.Fatalf("expected %s kind %d, got %d", name, expect, sample.Kind)
+		}
+	}
+
+	t.Log("counters are reset on flush")
+	{
+		c.AddGauge("goroutines", tags, uint64(10))
+		c.FlushCGM(context.Background(), &now, zerolog.Nop(), false)
+		batches := ts.received()
+		for name, sample := range batches[len(batches)-1] {
+			if sample.Kind != KindGauge {
+				t.Fatalf("expected %s gauge, got %d", name, sample.Kind)
+			}
+		}
+	}
+}
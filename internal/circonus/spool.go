@@ -0,0 +1,358 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package circonus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/bytefmt"
+	cgm "github.com/circonus-labs/circonus-gometrics/v3"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const spoolFileExt = ".json"
+
+var spoolDirRx = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// spool is a disk backed write-ahead buffer for batches a sink failed to
+// submit. Batches are written one per file, named so that lexical order is
+// submission order, and replayed oldest first once the sink accepts a
+// submission again. Samples retain their original _ts timestamps, samples
+// without one are stamped with the batch's collection time when spooled
+// (except histograms, which do not carry a timestamp).
+type spool struct {
+	sync.Mutex
+	c              *Check
+	sink           Sink
+	logger         zerolog.Logger
+	dir            string
+	maxSize        uint64
+	maxAge         time.Duration
+	replayInterval time.Duration
+	seq            uint64
+	replaying      bool
+}
+
+// spoolFile is a spooled batch
+type spoolFile struct {
+	name    string
+	created time.Time
+	size    uint64
+}
+
+// newSpool creates the spool for a sink, the directory is
+// <spool dir>/<cluster name>/<sink name>
+func newSpool(c *Check, s Sink) (*spool, error) {
+	cfg := c.config.Spool
+
+	dir := filepath.Join(cfg.Dir, spoolDirRx.ReplaceAllString(c.clusterName, "_"), spoolDirRx.ReplaceAllString(s.Name(), "_"))
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, errors.Wrap(err, "creating spool directory")
+	}
+
+	sp := &spool{
+		c:      c,
+		sink:   s,
+		dir:    dir,
+		logger: c.log.With().Str("pkg", "spool").Str("sink", s.Name()).Logger(),
+	}
+
+	if cfg.MaxSize != "" {
+		sz, err := bytefmt.ToBytes(cfg.MaxSize)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing spool max size (%s)", cfg.MaxSize)
+		}
+		sp.maxSize = sz
+	}
+
+	if cfg.MaxAge != "" {
+		d, err := time.ParseDuration(cfg.MaxAge)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing spool max age (%s)", cfg.MaxAge)
+		}
+		sp.maxAge = d
+	}
+
+	if cfg.MaxReplayRate > 0 {
+		sp.replayInterval = time.Second / time.Duration(cfg.MaxReplayRate)
+	}
+
+	files, size, err := sp.files()
+	if err != nil {
+		return nil, err
+	}
+	if len(files) > 0 {
+		sp.logger.Info().Int("batches", len(files)).Str("size", bytefmt.ByteSize(size)).Msg("found spooled batches")
+	}
+
+	return sp, nil
+}
+
+// add writes a failed batch to the spool and enforces the size and age limits, samples
+// without a timestamp are stamped with ts (ms) so they are replayed at collection time
+func (sp *spool) add(metrics map[string]MetricSample, ts uint64) error {
+	stamped := make(map[string]spooledSample, len(metrics))
+	untimestamped := 0
+	for name, sample := range metrics {
+		if sample.Timestamp == 0 {
+			if sample.Type == MetricTypeHistogram || sample.Type == MetricTypeCumulativeHistogram {
+				untimestamped++
+			} else {
+				sample.Timestamp = ts
+			}
+		}
+		stamped[name] = spooledSample{MetricSample: sample, Kind: sample.Kind}
+	}
+	if untimestamped > 0 {
+		sp.logger.Debug().Int("histograms", untimestamped).Msg("spooled histograms have no timestamp, replayed at replay time")
+		sp.c.IncrementCounterByValue("collect_spool_untimestamped", sp.tags(), uint64(untimestamped))
+	}
+
+	data, err := json.Marshal(stamped)
+	if err != nil {
+		return errors.Wrap(err, "marshaling metrics")
+	}
+
+	sp.Lock()
+	defer sp.Unlock()
+
+	sp.seq++
+	fn := filepath.Join(sp.dir, fmt.Sprintf("%020d-%010d%s", time.Now().UnixNano(), sp.seq, spoolFileExt))
+	tmp := fn + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		_ = os.Remove(tmp)
+		return errors.Wrap(err, "writing spool file")
+	}
+	if err := os.Rename(tmp, fn); err != nil {
+		_ = os.Remove(tmp)
+		return errors.Wrap(err, "renaming spool file")
+	}
+
+	sp.c.IncrementCounter("collect_spool_batches", sp.tags())
+	sp.c.IncrementCounterByValue("collect_spool_metrics", sp.tags(), uint64(len(metrics)))
+	sp.logger.Warn().Int("metrics", len(metrics)).Str("file", filepath.Base(fn)).Msg("spooled failed batch")
+
+	return sp.enforceLimits()
+}
+
+// enforceLimits removes expired batches and the oldest batches exceeding the size limit,
+// it must be called with the spool locked
+func (sp *spool) enforceLimits() error {
+	files, size, err := sp.files()
+	if err != nil {
+		return err
+	}
+
+	for len(files) > 0 {
+		f := files[0]
+		reason := ""
+		switch {
+		case sp.maxAge > 0 && time.Since(f.created) > sp.maxAge:
+			reason = "age"
+		case sp.maxSize > 0 && size > sp.maxSize:
+			reason = "size"
+		}
+		if reason == "" {
+			break
+		}
+		if err := os.Remove(filepath.Join(sp.dir, f.name)); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "removing spool file")
+		}
+		sp.logger.Warn().Str("file", f.name).Str("reason", reason).Msg("dropped spooled batch")
+		sp.c.IncrementCounter("collect_spool_dropped", append(sp.tags(), cgm.Tag{Category: "reason", Value: reason}))
+		size -= f.size
+		files = files[1:]
+	}
+
+	sp.c.AddGauge("collect_spool_bytes", sp.tags(), size)
+	sp.c.AddGauge("collect_spool_pending", sp.tags(), uint64(len(files)))
+
+	return nil
+}
+
+// files returns the spooled batches, oldest first, and their total size
+func (sp *spool) files() ([]spoolFile, uint64, error) {
+	entries, err := os.ReadDir(sp.dir)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "reading spool directory")
+	}
+
+	files := make([]spoolFile, 0, len(entries))
+	total := uint64(0)
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), spoolFileExt) {
+			continue
+		}
+		ts, _, ok := strings.Cut(e.Name(), "-")
+		if !ok {
+			continue
+		}
+		ns, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue // removed by replay
+		}
+		files = append(files, spoolFile{name: e.Name(), created: time.Unix(0, ns), size: uint64(info.Size())})
+		total += uint64(info.Size())
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+
+	return files, total, nil
+}
+
+// pending indicates whether there are spooled batches waiting to be replayed
+func (sp *spool) pending() bool {
+	sp.Lock()
+	defer sp.Unlock()
+	files, _, err := sp.files()
+	return err == nil && len(files) > 0
+}
+
+// startReplay replays spooled batches in the background if a replay is not already running
+func (sp *spool) startReplay(ctx context.Context) {
+	sp.Lock()
+	if sp.replaying {
+		sp.Unlock()
+		return
+	}
+	sp.replaying = true
+	sp.Unlock()
+
+	go func() {
+		defer func() {
+			sp.Lock()
+			sp.replaying = false
+			sp.Unlock()
+		}()
+		if err := sp.replay(ctx); err != nil {
+			sp.logger.Warn().Err(err).Msg("replaying spooled batches, will retry after next successful submission")
+		}
+	}()
+}
+
+// replay submits spooled batches, oldest first, at no more than the max replay
+// rate. Replay stops at the first failure so that batches are delivered in order.
+func (sp *spool) replay(ctx context.Context) error {
+	replayed := 0
+	for {
+		sp.Lock()
+		if err := sp.enforceLimits(); err != nil {
+			sp.Unlock()
+			return err
+		}
+		files, _, err := sp.files()
+		sp.Unlock()
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			if replayed > 0 {
+				sp.logger.Info().Int("batches", replayed).Msg("spool replay complete")
+			}
+			return nil
+		}
+
+		fn := filepath.Join(sp.dir, files[0].name)
+		metrics, err := readSpoolFile(fn)
+		if err != nil {
+			// unreadable batch would block the spool, remove it
+			sp.logger.Error().Err(err).Str("file", files[0].name).Msg("removing invalid spool file")
+			sp.c.IncrementCounter("collect_spool_dropped", append(sp.tags(), cgm.Tag{Category: "reason", Value: "invalid"}))
+			_ = os.Remove(fn)
+			continue
+		}
+
+		submitCtx, cancel := context.WithTimeout(ctx, sp.c.submitDeadline)
+		err = sp.sink.Submit(submitCtx, metrics, sp.logger, false)
+		cancel()
+		if err != nil {
+			sp.c.IncrementCounter("collect_spool_replay_errors", sp.tags())
+			return errors.Wrapf(err, "replaying %s", files[0].name)
+		}
+
+		if err := os.Remove(fn); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "removing replayed spool file")
+		}
+		replayed++
+		sp.c.IncrementCounter("collect_spool_replayed", sp.tags())
+		sp.logger.Debug().Int("metrics", len(metrics)).Str("file", files[0].name).Msg("replayed spooled batch")
+
+		if sp.replayInterval > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(sp.replayInterval):
+			}
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// batchTimestamp returns the collection time (ms) of a batch, the earliest sample
+// timestamp in the batch or, when no sample has one, flushStart
+func batchTimestamp(metrics map[string]MetricSample, flushStart time.Time) uint64 {
+	ts := uint64(0)
+	for _, sample := range metrics {
+		if sample.Timestamp != 0 && (ts == 0 || sample.Timestamp < ts) {
+			ts = sample.Timestamp
+		}
+	}
+	if ts == 0 {
+		ts = makeTimestamp(&flushStart)
+	}
+	return ts
+}
+
+// tags returns the tags used for the spool metrics
+func (sp *spool) tags() cgm.Tags {
+	return cgm.Tags{cgm.Tag{Category: "sink", Value: sp.sink.Name()}}
+}
+
+// spooledSample is a metric sample as spooled, including its (not submitted) kind
+type spooledSample struct {
+	MetricSample
+	Kind SampleKind `json:"kind,omitempty"`
+}
+
+// readSpoolFile decodes a spooled batch, numbers are decoded as
+// json.Number so values are resubmitted exactly as they were spooled
+func readSpoolFile(fn string) (map[string]MetricSample, error) {
+	data, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading spool file")
+	}
+
+	var spooled map[string]spooledSample
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&spooled); err != nil {
+		return nil, errors.Wrap(err, "decoding spool file")
+	}
+
+	metrics := make(map[string]MetricSample, len(spooled))
+	for name, sample := range spooled {
+		sample.MetricSample.Kind = sample.Kind
+		metrics[name] = sample.MetricSample
+	}
+
+	return metrics, nil
+}
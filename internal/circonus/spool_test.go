@@ -0,0 +1,323 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package circonus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+	"github.com/rs/zerolog"
+)
+
+// testSink records submitted batches, failing while fail is set
+type testSink struct {
+	sync.Mutex
+	batches []map[string]MetricSample
+	fail    bool
+}
+
+func (s *testSink) Name() string { return "test" }
+
+func (s *testSink) Submit(ctx context.Context, metrics map[string]MetricSample, resultLogger zerolog.Logger, includeStats bool) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.fail {
+		return errors.New("unavailable")
+	}
+	s.batches = append(s.batches, metrics)
+	return nil
+}
+
+func (s *testSink) received() []map[string]MetricSample {
+	s.Lock()
+	defer s.Unlock()
+	return append([]map[string]MetricSample{}, s.batches...)
+}
+
+func newSpoolTestCheck(t *testing.T, spoolCfg config.Spool) (*Check, *testSink, *spool) {
+	t.Helper()
+
+	ts := &testSink{fail: true}
+	c := &Check{
+		config:         &config.Circonus{Spool: spoolCfg},
+		clusterName:    "test cluster",
+		log:            zerolog.Nop(),
+		submitDeadline: 5 * time.Second,
+		sinks:          []Sink{ts},
+	}
+	sp, err := newSpool(c, ts)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	c.spools = map[string]*spool{ts.Name(): sp}
+
+	return c, ts, sp
+}
+
+func TestSpoolReplay(t *testing.T) {
+	t.Log("Testing spool replay")
+
+	c, ts, sp := newSpoolTestCheck(t, config.Spool{Dir: t.TempDir()})
+
+	for i := 0; i < 3; i++ {
+		metrics := map[string]MetricSample{
+			"foo": {Type: MetricTypeUint64, Value: math.MaxUint64 - uint64(i), Timestamp: uint64(1700000000000 + i)},
+		}
+		if err := c.FlushCollectorMetrics(context.Background(), metrics, zerolog.Nop(), false); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+
+	files, _, err := sp.files()
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("expected 3 spooled batches, got %d", len(files))
+	}
+
+	ts.Lock()
+	ts.fail = false
+	ts.Unlock()
+
+	live := map[string]MetricSample{"bar": {Type: MetricTypeUint64, Value: uint64(1)}}
+	if err := c.FlushCollectorMetrics(context.Background(), live, zerolog.Nop(), false); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for sp.pending() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if sp.pending() {
+		t.Fatal("expected spool to be empty")
+	}
+
+	batches := ts.received()
+	if len(batches) != 4 {
+		t.Fatalf("expected 4 batches, got %d", len(batches))
+	}
+	for i, b := range batches[1:] {
+		m, ok := b["foo"]
+		if !ok {
+			t.Fatalf("expected metric foo, got %v", b)
+		}
+		if m.Timestamp != uint64(1700000000000+i) {
+			t.Fatalf("expected original timestamp %d, got %d", 1700000000000+i, m.Timestamp)
+		}
+		data, err := json.Marshal(m.Value)
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if expect := math.MaxUint64 - uint64(i); string(data) != jsonUint(expect) {
+			t.Fatalf("expected value %d, got %s", expect, string(data))
+		}
+	}
+}
+
+func TestSpoolLimits(t *testing.T) {
+	t.Log("Testing spool limits")
+
+	t.Log("max size")
+	{
+		c, _, sp := newSpoolTestCheck(t, config.Spool{Dir: t.TempDir(), MaxSize: "100B"})
+		for i := 0; i < 5; i++ {
+			metrics := map[string]MetricSample{"foo": {Type: MetricTypeUint64, Value: uint64(i)}}
+			_ = c.FlushCollectorMetrics(context.Background(), metrics, zerolog.Nop(), false)
+		}
+		files, size, err := sp.files()
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if size > 100 || len(files) == 0 || len(files) == 5 {
+			t.Fatalf("expected size <= 100 with oldest dropped, got %d files %d bytes", len(files), size)
+		}
+		m, err := readSpoolFile(sp.dir + "/" + files[len(files)-1].name)
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if v := m["foo"].Value.(json.Number).String(); v != "4" {
+			t.Fatalf("expected newest batch to be kept, got %s", v)
+		}
+	}
+
+	t.Log("max age")
+	{
+		c, _, sp := newSpoolTestCheck(t, config.Spool{Dir: t.TempDir(), MaxAge: "1ns"})
+		metrics := map[string]MetricSample{"foo": {Type: MetricTypeUint64, Value: uint64(1)}}
+		_ = c.FlushCollectorMetrics(context.Background(), metrics, zerolog.Nop(), false)
+		if sp.pending() {
+			t.Fatal("expected expired batch to be dropped")
+		}
+	}
+
+	t.Log("invalid settings")
+	{
+		c := &Check{config: &config.Circonus{Spool: config.Spool{Dir: t.TempDir(), MaxSize: "foo"}}, log: zerolog.Nop()}
+		if _, err := newSpool(c, &testSink{}); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+}
+
+func TestSpoolPartialSubmit(t *testing.T) {
+	t.Log("Testing spooling only the undelivered chunks of a batch")
+
+	var mu sync.Mutex
+	down := true
+	var replayed []map[string]MetricSample
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var metrics map[string]MetricSample
+		if err := json.NewDecoder(r.Body).Decode(&metrics); err != nil {
+			t.Errorf("decoding submission: %s", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if _, ok := metrics["metric_042"]; ok && down {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if !down {
+			replayed = append(replayed, metrics)
+		}
+		fmt.Fprintf(w, `{"stats":%d}`, len(metrics))
+	}))
+	defer srv.Close()
+
+	c := &Check{
+		config:          &config.Circonus{Spool: config.Spool{Dir: t.TempDir()}, SubmitParallelism: 2},
+		clusterName:     "test cluster",
+		log:             zerolog.Nop(),
+		submitDeadline:  5 * time.Second,
+		submissionURL:   srv.URL,
+		maxChunkMetrics: 20,
+	}
+	trap, err := newTrapSink(c)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	c.sinks = []Sink{trap}
+	sp, err := newSpool(c, trap)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	c.spools = map[string]*spool{trap.Name(): sp}
+
+	metrics := make(map[string]MetricSample)
+	for i := 0; i < 100; i++ {
+		metrics[fmt.Sprintf("metric_%03d", i)] = MetricSample{Type: MetricTypeUint64, Value: uint64(i), Timestamp: 1700000000000}
+	}
+
+	err = c.FlushCollectorMetrics(context.Background(), metrics, zerolog.Nop(), false)
+	var partial *PartialSubmitError
+	if !errors.As(err, &partial) {
+		t.Fatalf("expected partial submit error, got %v", err)
+	}
+
+	files, _, err := sp.files()
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected 1 spooled batch, got %d", len(files))
+	}
+	spooled, err := readSpoolFile(sp.dir + "/" + files[0].name)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if len(spooled) == 0 || len(spooled) > 20 {
+		t.Fatalf("expected only the failed chunk (<= 20 metrics) to be spooled, got %d", len(spooled))
+	}
+	if _, ok := spooled["metric_042"]; !ok {
+		t.Fatal("expected failed chunk to contain metric_042")
+	}
+
+	mu.Lock()
+	down = false
+	mu.Unlock()
+
+	if err := sp.replay(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(replayed) != 1 {
+		t.Fatalf("expected 1 replayed batch, got %d", len(replayed))
+	}
+	if len(replayed[0]) != len(spooled) {
+		t.Fatalf("expected %d replayed metrics, got %d", len(spooled), len(replayed[0]))
+	}
+	for name := range replayed[0] {
+		if _, ok := spooled[name]; !ok {
+			t.Fatalf("expected only undelivered metrics to be replayed, got %s", name)
+		}
+	}
+}
+
+func TestSpoolTimestamps(t *testing.T) {
+	t.Log("Testing spooled samples without a timestamp")
+
+	c, ts, sp := newSpoolTestCheck(t, config.Spool{Dir: t.TempDir()})
+
+	metrics := map[string]MetricSample{
+		"gauge":     {Type: MetricTypeUint64, Value: uint64(1)},
+		"timed":     {Type: MetricTypeUint64, Value: uint64(2), Timestamp: 1700000000000},
+		"histogram": {Type: MetricTypeHistogram, Value: []string{"H[1.0e+00]=1"}},
+		"counter":   {Type: MetricTypeUint64, Value: uint64(3), Timestamp: 1700000000000, Kind: KindCounter},
+	}
+	if err := c.FlushCollectorMetrics(context.Background(), metrics, zerolog.Nop(), false); err == nil {
+		t.Fatal("expected error")
+	}
+
+	ts.Lock()
+	ts.fail = false
+	ts.Unlock()
+
+	if err := sp.replay(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	batches := ts.received()
+	if len(batches) != 1 {
+		t.Fatalf("expected 1 replayed batch, got %d", len(batches))
+	}
+	if m := batches[0]["gauge"]; m.Timestamp != 1700000000000 {
+		t.Fatalf("expected gauge stamped with batch collection time, got %d", m.Timestamp)
+	}
+	if m := batches[0]["timed"]; m.Timestamp != 1700000000000 {
+		t.Fatalf("expected original timestamp, got %d", m.Timestamp)
+	}
+	if m := batches[0]["histogram"]; m.Timestamp != 0 {
+		t.Fatalf("expected histogram without timestamp, got %d", m.Timestamp)
+	}
+	if m := batches[0]["counter"]; m.Kind != KindCounter {
+		t.Fatalf("expected counter kind preserved, got %d", m.Kind)
+	}
+
+	t.Log("batch without timestamps")
+	{
+		start := time.Now()
+		got := batchTimestamp(map[string]MetricSample{"gauge": {Type: MetricTypeUint64, Value: uint64(1)}}, start)
+		if got != makeTimestamp(&start) {
+			t.Fatalf("expected flush start %d, got %d", makeTimestamp(&start), got)
+		}
+	}
+}
+
+func jsonUint(v uint64) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
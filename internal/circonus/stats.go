@@ -5,7 +5,11 @@
 
 package circonus
 
-import "code.cloudfoundry.org/bytefmt"
+import (
+	"time"
+
+	"code.cloudfoundry.org/bytefmt"
+)
 
 // Stats defines the submission stats tracked across metric submissions to broker
 type Stats struct {
@@ -44,3 +48,24 @@ func (c *Check) ResetSubmitStats() {
 	c.stats.LocFiltered = 0
 	c.stats.BkrFiltered = 0
 }
+
+// LastSubmit returns the time of the last successful submission to a sink (zero if none)
+func (c *Check) LastSubmit() time.Time {
+	c.statsmu.Lock()
+	defer c.statsmu.Unlock()
+	return c.lastSubmit
+}
+
+// Initialized indicates whether the check is ready to submit metrics, a check is
+// required only when submitting to the circonus trap sink (and not a dry run)
+func (c *Check) Initialized() bool {
+	if c.config.DryRun || !c.hasSink(SinkCirconusTrap) {
+		return true
+	}
+	return c.submissionURL != "" && c.metrics != nil
+}
+
+// DryRun indicates metrics are not being submitted
+func (c *Check) DryRun() bool {
+	return c.config.DryRun
+}
@@ -0,0 +1,196 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package circonus
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const streamBufferSize = 32 * 1024
+
+// metricStream encodes metrics, entry by entry, through an (optional) gzip
+// writer directly into a request body via an io.Pipe. The full serialized
+// (and compressed) payload is never held in memory.
+type metricStream struct {
+	sync.Mutex
+	traceMu   sync.Mutex // serializes trace file writes across attempts
+	metrics   map[string]MetricSample
+	logger    zerolog.Logger
+	traceFile string
+	compress  bool
+	rawLen    int
+	sentLen   int
+}
+
+func newMetricStream(metrics map[string]MetricSample, compress bool, traceFile string, logger zerolog.Logger) *metricStream {
+	if compress && traceFile != "" {
+		traceFile += ".gz"
+	}
+	return &metricStream{
+		metrics:   metrics,
+		compress:  compress,
+		traceFile: traceFile,
+		logger:    logger,
+	}
+}
+
+// reader returns a new reader for the request body, each call starts a new
+// encoding of the metrics so it can be used for request retries. Encoding (and
+// tracing) is deferred until the body is first read, retryablehttp calls the
+// reader func once without reading it to probe the body length.
+func (s *metricStream) reader() (io.Reader, error) {
+	return &streamBody{stream: s}, nil
+}
+
+// sizes returns the raw (json) and sent (compressed) sizes of the last complete encoding
+func (s *metricStream) sizes() (int, int) {
+	s.Lock()
+	defer s.Unlock()
+	return s.rawLen, s.sentLen
+}
+
+// encode writes the metrics as a json object to w
+func (s *metricStream) encode(w io.Writer) error {
+	sent := &countingWriter{w: w}
+	var out io.Writer = sent
+
+	if s.traceFile != "" {
+		s.traceMu.Lock()
+		defer s.traceMu.Unlock()
+		fh, err := os.Create(s.traceFile)
+		if err != nil {
+			s.logger.Error().Err(err).Str("file", s.traceFile).Msg("skipping submit trace")
+		} else {
+			defer func() {
+				if err := fh.Close(); err != nil {
+					s.logger.Error().Err(err).Str("file", s.traceFile).Msg("closing metric trace")
+				}
+			}()
+			out = io.MultiWriter(sent, fh)
+		}
+	}
+
+	var zw *gzip.Writer
+	if s.compress {
+		zw = gzip.NewWriter(out)
+		out = zw
+	}
+
+	raw := &countingWriter{w: out}
+	if err := writeMetricsJSON(raw, s.metrics); err != nil {
+		return err
+	}
+
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return errors.Wrap(err, "closing gzip writer")
+		}
+	}
+
+	s.Lock()
+	s.rawLen = raw.n
+	s.sentLen = sent.n
+	s.Unlock()
+
+	return nil
+}
+
+// writeMetricsJSON writes metrics to w as a json object, one entry at a time
+func writeMetricsJSON(w io.Writer, metrics map[string]MetricSample) error {
+	bw := bufio.NewWriterSize(w, streamBufferSize)
+
+	if err := bw.WriteByte('{'); err != nil {
+		return errors.Wrap(err, "writing metrics")
+	}
+	first := true
+	for name, sample := range metrics {
+		key, err := json.Marshal(name)
+		if err != nil {
+			return errors.Wrapf(err, "marshaling metric name (%s)", name)
+		}
+		val, err := json.Marshal(sample)
+		if err != nil {
+			return errors.Wrapf(err, "marshaling metric (%s)", name)
+		}
+		if !first {
+			if err := bw.WriteByte(','); err != nil {
+				return errors.Wrap(err, "writing metrics")
+			}
+		}
+		first = false
+		if _, err := bw.Write(key); err != nil {
+			return errors.Wrap(err, "writing metrics")
+		}
+		if err := bw.WriteByte(':'); err != nil {
+			return errors.Wrap(err, "writing metrics")
+		}
+		if _, err := bw.Write(val); err != nil {
+			return errors.Wrap(err, "writing metrics")
+		}
+	}
+	if err := bw.WriteByte('}'); err != nil {
+		return errors.Wrap(err, "writing metrics")
+	}
+
+	return errors.Wrap(bw.Flush(), "flushing metrics")
+}
+
+// streamBody is a request body which starts encoding metrics into a pipe on first read
+type streamBody struct {
+	sync.Mutex
+	stream *metricStream
+	pr     *io.PipeReader
+	closed bool
+}
+
+func (b *streamBody) Read(p []byte) (int, error) {
+	b.Lock()
+	if b.closed {
+		b.Unlock()
+		return 0, io.ErrClosedPipe
+	}
+	if b.pr == nil {
+		pr, pw := io.Pipe()
+		b.pr = pr
+		go func() {
+			_ = pw.CloseWithError(b.stream.encode(pw))
+		}()
+	}
+	pr := b.pr
+	b.Unlock()
+
+	return pr.Read(p)
+}
+
+func (b *streamBody) Close() error {
+	b.Lock()
+	defer b.Unlock()
+	b.closed = true
+	if b.pr != nil {
+		return b.pr.Close()
+	}
+	return nil
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += n
+	return n, err
+}
@@ -12,12 +12,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
+	"net/http/httptrace"
 	"os"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"code.cloudfoundry.org/bytefmt"
@@ -50,17 +52,26 @@ func (c *Check) FlushCGM(ctx context.Context, ts *time.Time, lg zerolog.Logger,
 		metrics := make(map[string]MetricSample)
 
 		c.metricsmu.Lock()
+		counters := c.metricCounters
+		c.metricCounters = nil
 		for mn, mv := range *(c.metrics.FlushMetrics()) {
 			ms := MetricSample{
 				Value: mv.Value,
 				Type:  mv.Type,
 			}
+			if counters[mn] {
+				ms.Kind = KindDeltaCounter
+			}
 			if ms.Type != MetricTypeHistogram {
 				ms.Timestamp = makeTimestamp(ts)
 			}
 			metrics[mn] = ms
 			if strings.HasPrefix(mn, "collect_k8s_event_count") {
 				c.metrics.Set(mn, 0) // reset event counter
+				if c.metricCounters == nil {
+					c.metricCounters = make(map[string]bool)
+				}
+				c.metricCounters[mn] = true
 			}
 		}
 		c.metricsmu.Unlock()
@@ -74,198 +85,312 @@ func (c *Check) FlushCGM(ctx context.Context, ts *time.Time, lg zerolog.Logger,
 			}
 		}
 
-		for {
-			submitCtx, submitCtxCancel := context.WithDeadline(ctx, time.Now().Add(c.submitDeadline))
-			err := c.submitMetrics(submitCtx, metrics, lg, !agentStats)
-			if err == nil {
-				submitCtxCancel()
-				break
-			}
-
-			if errors.Is(err, context.DeadlineExceeded) {
-				c.log.Warn().Err(err).Str("deadline", c.submitDeadline.String()).Msg("deadline reached submitting metrics, retrying")
-				submitCtxCancel()
-				continue
-			}
-
-			submitCtxCancel()
-			c.log.Error().Err(err).Msg("submitting metrics")
-			break
-		}
-
+		_ = c.flushSinks(ctx, metrics, lg, !agentStats) // errors logged by flushSink
 	}
 }
 
 // FlushCollectorMetrics sends metrics from discrete collectors and sub-collectors
 func (c *Check) FlushCollectorMetrics(ctx context.Context, metrics map[string]MetricSample, resultLogger zerolog.Logger, includeStats bool) error {
-	var err error
+	return c.flushSinks(ctx, metrics, resultLogger, includeStats)
+}
 
-	for {
-		submitCtx, submitCtxCancel := context.WithDeadline(ctx, time.Now().Add(c.submitDeadline))
-		err = c.submitMetrics(submitCtx, metrics, resultLogger, includeStats)
-		if err == nil {
-			submitCtxCancel()
-			break
-		}
+// submitMetrics sends metric batches to the trap check, batches exceeding the
+// configured size or metric count are split into chunks which are submitted
+// concurrently (up to the submit parallelism)
+func (c *Check) submitMetrics(ctx context.Context, metrics map[string]MetricSample, resultLogger zerolog.Logger, includeStats bool) error {
+	if metrics == nil {
+		return errors.New("invalid metrics (nil)")
+	}
+	if len(metrics) == 0 {
+		return nil
+	}
 
-		if errors.Is(err, context.DeadlineExceeded) {
-			c.log.Warn().Err(err).Str("deadline", c.submitDeadline.String()).Msg("deadline reached submitting metrics, retrying")
-			submitCtxCancel()
-			continue
-		}
+	if c.submissionURL == "" {
+		return errors.New("invalid state (empty submission url)")
+	}
 
-		submitCtxCancel()
-		c.log.Error().Err(err).Msg("submitting metrics")
-		break
+	chunks := chunkMetrics(metrics, c.maxChunkBytes, c.maxChunkMetrics)
+	if len(chunks) == 1 {
+		return c.submitBisect(ctx, chunks[0], resultLogger, includeStats)
 	}
 
-	return err
+	resultLogger.Debug().Int("metrics", len(metrics)).Int("chunks", len(chunks)).Msg("splitting submission")
+
+	parallelism := int(c.config.SubmitParallelism)
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	var wg sync.WaitGroup
+	var results submitResults
+	sem := make(chan struct{}, parallelism)
+	for idx, chunk := range chunks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(idx int, chunk map[string]MetricSample) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results.add(c.submitBisect(ctx, chunk, resultLogger.With().Int("chunk", idx).Logger(), includeStats), chunk)
+		}(idx, chunk)
+	}
+	wg.Wait()
+
+	// chunks accepted by a broker are not resubmitted, only the failed chunks are returned
+	return results.result()
 }
 
-// submitMetrics does the heavy lifting to send metric batches to the trap check
-func (c *Check) submitMetrics(ctx context.Context, metrics map[string]MetricSample, resultLogger zerolog.Logger, includeStats bool) error {
-	if metrics == nil {
-		return errors.New("invalid metrics (nil)")
+// submitRejectedError is returned when the broker rejects a submission
+// as invalid (e.g. a metric exceeding broker limits)
+type submitRejectedError struct {
+	status string
+	body   string
+}
+
+func (e *submitRejectedError) Error() string {
+	return fmt.Sprintf("submission rejected (%s) %s", e.status, e.body)
+}
+
+// isPayloadRejection reports whether a broker response status indicates a problem
+// with the submitted metrics themselves. Other 4xx statuses (e.g. 401/403/404 for a
+// bad check secret, deleted check or wrong URL) are treated as normal failures so
+// they are retried, failed over and spooled rather than bisected and dropped.
+func isPayloadRejection(statusCode int) bool {
+	switch statusCode {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return true
+	default:
+		return false
 	}
-	if len(metrics) == 0 {
+}
+
+// submitBisect submits a chunk, if the broker rejects it the chunk is split in half and
+// each half resubmitted so that only the offending metric(s) are dropped
+func (c *Check) submitBisect(ctx context.Context, metrics map[string]MetricSample, resultLogger zerolog.Logger, includeStats bool) error {
+	err := c.submitChunk(ctx, metrics, resultLogger, includeStats)
+	if err == nil {
 		return nil
 	}
 
-	baseTags := cgm.Tags{
-		cgm.Tag{Category: "source", Value: release.NAME},
+	var rejected *submitRejectedError
+	if !errors.As(err, &rejected) {
+		return err
+	}
+
+	if len(metrics) == 1 {
+		for name := range metrics {
+			resultLogger.Warn().Str("metric", name).Str("status", rejected.status).Str("body", rejected.body).Msg("dropping metric rejected by broker")
+		}
+		c.IncrementCounter("collect_submit_dropped", cgm.Tags{cgm.Tag{Category: "source", Value: release.NAME}})
+		return nil
+	}
+
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	resultLogger.Debug().Int("metrics", len(metrics)).Msg("chunk rejected, bisecting")
+
+	var results submitResults
+	for _, half := range [][]string{names[:len(names)/2], names[len(names)/2:]} {
+		chunk := make(map[string]MetricSample, len(half))
+		for _, name := range half {
+			chunk[name] = metrics[name]
+		}
+		results.add(c.submitBisect(ctx, chunk, resultLogger, includeStats), chunk)
+	}
+
+	return results.result()
+}
+
+// chunkMetrics splits metrics into chunks with at most maxBytes of serialized
+// metrics and maxMetrics metrics (zero disables the respective limit)
+func chunkMetrics(metrics map[string]MetricSample, maxBytes, maxMetrics int) []map[string]MetricSample {
+	if (maxBytes <= 0 || len(metrics) == 1) && (maxMetrics <= 0 || len(metrics) <= maxMetrics) {
+		return []map[string]MetricSample{metrics}
+	}
+
+	chunks := make([]map[string]MetricSample, 0, 1)
+	chunk := make(map[string]MetricSample)
+	chunkSize := 2 // {}
+	for name, sample := range metrics {
+		size := 0
+		if maxBytes > 0 {
+			size = metricEntrySize(name, sample)
+		}
+		if len(chunk) > 0 && ((maxMetrics > 0 && len(chunk) >= maxMetrics) || (maxBytes > 0 && chunkSize+size > maxBytes)) {
+			chunks = append(chunks, chunk)
+			chunk = make(map[string]MetricSample)
+			chunkSize = 2
+		}
+		chunk[name] = sample
+		chunkSize += size
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
 	}
-	baseTags = append(baseTags, c.defaultTags...)
 
-	rawData, err := json.Marshal(metrics)
+	return chunks
+}
+
+// metricEntrySize returns the serialized size of a metric entry ("name":{sample},)
+func metricEntrySize(name string, sample MetricSample) int {
+	key, _ := json.Marshal(name)
+	val, err := json.Marshal(sample)
 	if err != nil {
-		resultLogger.Error().Err(err).Msg("json encoding metrics")
-		return errors.Wrap(err, "marshaling metrics")
+		return len(key) + 2
 	}
+	return len(key) + len(val) + 2
+}
 
-	start := time.Now()
+// submitChunk sends a chunk of metrics to the trap check, failing over to the next
+// broker (in health/round robin order) when a broker is unavailable
+func (c *Check) submitChunk(ctx context.Context, metrics map[string]MetricSample, resultLogger zerolog.Logger, includeStats bool) error {
+	targets := c.brokerOrder()
 
-	if c.submissionURL == "" {
-		if c.config.DryRun {
-			_, err := io.Copy(os.Stdout, bytes.NewReader(rawData))
+	var err error
+	for idx, target := range targets {
+		if idx > 0 {
+			resultLogger.Warn().Str("broker", target.cid).Str("url", target.submissionURL).Msg("failing over to next broker")
+			c.IncrementCounter("collect_broker_failovers", cgm.Tags{cgm.Tag{Category: "source", Value: release.NAME}})
+		}
+
+		err = c.submitToBroker(ctx, target, len(targets), metrics, resultLogger, includeStats)
+
+		var rejected *submitRejectedError
+		if err == nil || errors.As(err, &rejected) {
+			// a rejected submission (400/413/422) is a problem with the metrics, not the
+			// broker - any other failure, including 401/403/404, marks the broker unhealthy
+			c.AddGauge("collect_broker_health", target.tags(), target.success())
 			return err
 		}
-		return errors.New("no submission url and not in dry-run mode")
-	}
-
-	if c.client == nil {
-		if c.brokerTLSConfig != nil {
-			c.client = &http.Client{
-				Transport: &http.Transport{
-					Proxy: http.ProxyFromEnvironment,
-					DialContext: (&net.Dialer{
-						Timeout:       10 * time.Second,
-						KeepAlive:     3 * time.Second,
-						FallbackDelay: -1 * time.Millisecond,
-					}).DialContext,
-					TLSClientConfig:     c.brokerTLSConfig,
-					TLSHandshakeTimeout: 10 * time.Second,
-					DisableKeepAlives:   true,
-					DisableCompression:  false,
-					MaxIdleConns:        1,
-					MaxIdleConnsPerHost: 0,
-				},
-				Timeout: 60 * time.Second, // hard 60s timeout
-			}
-		} else {
-			c.client = &http.Client{
-				Transport: &http.Transport{
-					Proxy: http.ProxyFromEnvironment,
-					DialContext: (&net.Dialer{
-						Timeout:       10 * time.Second,
-						KeepAlive:     3 * time.Second,
-						FallbackDelay: -1 * time.Millisecond,
-					}).DialContext,
-					DisableKeepAlives:   true,
-					DisableCompression:  false,
-					MaxIdleConns:        1,
-					MaxIdleConnsPerHost: 0,
-				},
-				Timeout: 60 * time.Second, // hard 60s timeout
-			}
+
+		c.AddGauge("collect_broker_health", target.tags(), target.failure())
+		if ctx.Err() != nil {
+			return err
 		}
 	}
 
+	return err
+}
+
+// submitToBroker does the heavy lifting to send a chunk of metrics to a broker
+func (c *Check) submitToBroker(ctx context.Context, target *brokerTarget, numBrokers int, metrics map[string]MetricSample, resultLogger zerolog.Logger, includeStats bool) error {
+	baseTags := cgm.Tags{
+		cgm.Tag{Category: "source", Value: release.NAME},
+	}
+
+	start := time.Now()
+
+	client := target.client
+
 	submitUUID, err := uuid.NewRandom()
 	if err != nil {
 		resultLogger.Error().Err(err).Msg("creating new submit ID")
 		return errors.Wrap(err, "creating new submit ID")
 	}
 
-	payloadIsCompressed := false
+	traceFile := ""
+	if dumpDir := c.config.TraceSubmits; dumpDir != "" {
+		traceFile = path.Join(dumpDir, time.Now().UTC().Format(traceTSFormat)+"_"+submitUUID.String()+".json")
+	}
 
+	var reqBody interface{}
+	var stream *metricStream
 	var subData *bytes.Buffer
-	if c.UseCompression() && len(rawData) > compressionThreshold {
-		subData = bytes.NewBuffer([]byte{})
-		zw := gzip.NewWriter(subData)
-		n, e1 := zw.Write(rawData)
-		if e1 != nil {
-			resultLogger.Error().Err(e1).Msg("compressing metrics")
-			return errors.Wrap(e1, "compressing metrics")
-		}
-		if n != len(rawData) {
-			resultLogger.Error().Int("data_len", len(rawData)).Int("written", n).Msg("gzip write length mismatch")
-			return errors.Errorf("write length mismatch data length %d != written length %d", len(rawData), n)
-		}
-		if e2 := zw.Close(); e2 != nil {
-			resultLogger.Error().Err(e2).Msg("closing gzip writer")
-			return errors.Wrap(e2, "closing gzip writer")
-		}
-		payloadIsCompressed = true
+	payloadIsCompressed := false
+	dataLen := 0
+
+	if streamMin := int(c.config.SubmitStreamMin); streamMin > 0 && len(metrics) >= streamMin {
+		// large chunks are encoded directly into the request body, re-encoded on each
+		// retry attempt, and sent without a Content-Length (chunked transfer encoding).
+		// They are well over the compression threshold, so compressed when enabled.
+		stream = newMetricStream(metrics, c.UseCompression(), traceFile, resultLogger)
+		payloadIsCompressed = c.UseCompression()
+		reqBody = retryablehttp.ReaderFunc(stream.reader)
 	} else {
-		subData = bytes.NewBuffer(rawData)
-	}
-
-	if dumpDir := c.config.TraceSubmits; dumpDir != "" {
-		fn := path.Join(dumpDir, time.Now().UTC().Format(traceTSFormat)+"_"+submitUUID.String()+".json")
-		if payloadIsCompressed {
-			fn += ".gz"
+		rawData, err := json.Marshal(metrics)
+		if err != nil {
+			resultLogger.Error().Err(err).Msg("json encoding metrics")
+			return errors.Wrap(err, "marshaling metrics")
 		}
 
-		if fh, e1 := os.Create(fn); e1 != nil {
-			c.log.Error().Err(e1).Str("file", fn).Msg("skipping submit trace")
+		if c.UseCompression() && len(rawData) > compressionThreshold {
+			subData = bytes.NewBuffer([]byte{})
+			zw := gzip.NewWriter(subData)
+			n, e1 := zw.Write(rawData)
+			if e1 != nil {
+				resultLogger.Error().Err(e1).Msg("compressing metrics")
+				return errors.Wrap(e1, "compressing metrics")
+			}
+			if n != len(rawData) {
+				resultLogger.Error().Int("data_len", len(rawData)).Int("written", n).Msg("gzip write length mismatch")
+				return errors.Errorf("write length mismatch data length %d != written length %d", len(rawData), n)
+			}
+			if e2 := zw.Close(); e2 != nil {
+				resultLogger.Error().Err(e2).Msg("closing gzip writer")
+				return errors.Wrap(e2, "closing gzip writer")
+			}
+			payloadIsCompressed = true
 		} else {
-			if _, e2 := fh.Write(subData.Bytes()); e2 != nil {
-				resultLogger.Error().Err(e2).Msg("writing metric trace")
+			subData = bytes.NewBuffer(rawData)
+		}
+
+		if traceFile != "" {
+			fn := traceFile
+			if payloadIsCompressed {
+				fn += ".gz"
 			}
-			if e3 := fh.Close(); e3 != nil {
-				resultLogger.Error().Err(e3).Str("file", fn).Msg("closing metric trace")
+
+			if fh, e1 := os.Create(fn); e1 != nil {
+				c.log.Error().Err(e1).Str("file", fn).Msg("skipping submit trace")
+			} else {
+				if _, e2 := fh.Write(subData.Bytes()); e2 != nil {
+					resultLogger.Error().Err(e2).Msg("writing metric trace")
+				}
+				if e3 := fh.Close(); e3 != nil {
+					resultLogger.Error().Err(e3).Str("file", fn).Msg("closing metric trace")
+				}
 			}
 		}
-	}
 
-	dataLen := len(rawData) // subData.Len()
+		dataLen = len(rawData)
+		reqBody = subData
+	}
 
 	reqStart := time.Now()
 
-	req, err := retryablehttp.NewRequest("PUT", c.submissionURL, subData)
+	req, err := retryablehttp.NewRequest("PUT", target.submissionURL, reqBody)
 	if err != nil {
 		resultLogger.Error().Err(err).Msg("creating submission request")
 		return err
 	}
-	req = req.WithContext(ctx)
+	req = req.WithContext(httptrace.WithClientTrace(ctx, c.brokerClientTrace()))
 	req.Header.Set("User-Agent", release.NAME+"/"+release.VERSION)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Connection", "close")
-	req.Header.Set("Content-Length", strconv.Itoa(dataLen))
+	if stream == nil {
+		req.Header.Set("Content-Length", strconv.Itoa(dataLen))
+	}
 	if payloadIsCompressed {
 		req.Header.Set("Content-Encoding", "gzip")
 	}
 
 	retryClient := retryablehttp.NewClient()
-	retryClient.HTTPClient = c.client
+	retryClient.HTTPClient = client
 	retryClient.Logger = submitLogshim{logh: c.log.With().Str("pkg", "retryablehttp").Logger()}
 	retryClient.RetryWaitMin = 50 * time.Millisecond
 	retryClient.RetryWaitMax = 1 * time.Second
 	retryClient.RetryMax = 10
+	if numBrokers > 1 {
+		retryClient.RetryMax = 3 // fail over to the next broker sooner
+	}
 	retryClient.RequestLogHook = func(l retryablehttp.Logger, r *http.Request, attempt int) {
 		if attempt > 0 {
-			c.metrics.IncrementWithTags("collect_submit_retries", baseTags)
+			c.IncrementCounter("collect_submit_retries", baseTags)
 			reqStart = time.Now()
 			resultLogger.Warn().Str("url", r.URL.String()).Int("retry", attempt).Msg("retrying...")
 		}
@@ -279,20 +404,18 @@ func (c *Check) submitMetrics(ctx context.Context, metrics map[string]MetricSamp
 		if r.StatusCode != http.StatusOK {
 			tags := cgm.Tags{cgm.Tag{Category: "code", Value: fmt.Sprintf("%d", r.StatusCode)}}
 			tags = append(tags, baseTags...)
-			c.metrics.IncrementWithTags("collect_submit_errors", tags)
+			c.IncrementCounter("collect_submit_errors", tags)
 			resultLogger.Warn().Str("url", r.Request.URL.String()).Str("status", r.Status).Msg("non-200 response...")
 		}
 	}
 
-	defer retryClient.HTTPClient.CloseIdleConnections()
-
 	resp, err := retryClient.Do(req)
 	if resp != nil {
 		defer resp.Body.Close()
 	}
 	if err != nil {
 		resultLogger.Error().Err(err).Msg("making request")
-		c.metrics.IncrementWithTags("collect_submit_fails", baseTags)
+		c.IncrementCounter("collect_submit_fails", baseTags)
 		return err
 	}
 
@@ -305,12 +428,22 @@ func (c *Check) submitMetrics(ctx context.Context, metrics map[string]MetricSamp
 	if resp.StatusCode != http.StatusOK {
 		tags := cgm.Tags{cgm.Tag{Category: "code", Value: fmt.Sprintf("%d", resp.StatusCode)}}
 		tags = append(tags, baseTags...)
-		c.metrics.IncrementWithTags("collect_submit_fails", tags)
-		resultLogger.Error().Str("url", c.submissionURL).Str("status", resp.Status).Str("body", string(body)).Msg("submitting telemetry")
-		return errors.Errorf("submitting metrics (%s %s)", c.submissionURL, resp.Status)
+		c.IncrementCounter("collect_submit_fails", tags)
+		resultLogger.Error().Str("url", target.submissionURL).Str("status", resp.Status).Str("body", string(body)).Msg("submitting telemetry")
+		if isPayloadRejection(resp.StatusCode) {
+			return &submitRejectedError{status: resp.Status, body: string(body)}
+		}
+		return errors.Errorf("submitting metrics (%s %s)", target.submissionURL, resp.Status)
 	}
 
-	c.metrics.IncrementWithTags("collect_submits", baseTags)
+	c.IncrementCounter("collect_submits", baseTags)
+
+	sentSize := 0
+	if stream != nil {
+		dataLen, sentSize = stream.sizes()
+	} else {
+		sentSize = subData.Len()
+	}
 
 	var result TrapResult
 	if err := json.Unmarshal(body, &result); err != nil {
@@ -318,7 +451,7 @@ func (c *Check) submitMetrics(ctx context.Context, metrics map[string]MetricSamp
 		return errors.Wrapf(err, "parsing response (%s)", string(body))
 	}
 
-	result.CheckUUID = c.checkUUID
+	result.CheckUUID = target.checkUUID
 	result.SubmitUUID = submitUUID
 
 	if result.Error != "" {
@@ -337,7 +470,7 @@ func (c *Check) submitMetrics(ctx context.Context, metrics map[string]MetricSamp
 		c.stats.RecvMetrics += result.Stats
 		c.stats.SentMetrics += uint64(len(metrics))
 		c.stats.SentBytes += uint64(dataLen)
-		c.stats.SentSize += uint64(subData.Len())
+		c.stats.SentSize += uint64(sentSize)
 		c.stats.BkrFiltered += result.Filtered
 		c.statsmu.Unlock()
 	}
@@ -0,0 +1,406 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package circonus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+	"github.com/klauspost/compress/gzip"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+func TestChunkMetrics(t *testing.T) {
+	t.Log("Testing chunkMetrics")
+
+	metrics := make(map[string]MetricSample)
+	for i := 0; i < 100; i++ {
+		metrics[fmt.Sprintf("metric_%03d", i)] = MetricSample{Type: MetricTypeUint64, Value: uint64(i)}
+	}
+
+	t.Log("no limits")
+	{
+		chunks := chunkMetrics(metrics, 0, 0)
+		if len(chunks) != 1 || len(chunks[0]) != 100 {
+			t.Fatalf("expected 1 chunk of 100, got %d", len(chunks))
+		}
+	}
+
+	t.Log("max metrics")
+	{
+		chunks := chunkMetrics(metrics, 0, 30)
+		if len(chunks) != 4 {
+			t.Fatalf("expected 4 chunks, got %d", len(chunks))
+		}
+		total := 0
+		for _, chunk := range chunks {
+			if len(chunk) > 30 {
+				t.Fatalf("expected <= 30 metrics, got %d", len(chunk))
+			}
+			total += len(chunk)
+		}
+		if total != 100 {
+			t.Fatalf("expected 100 metrics, got %d", total)
+		}
+	}
+
+	t.Log("max bytes")
+	{
+		chunks := chunkMetrics(metrics, 512, 0)
+		if len(chunks) < 2 {
+			t.Fatalf("expected multiple chunks, got %d", len(chunks))
+		}
+		total := 0
+		for _, chunk := range chunks {
+			data, err := json.Marshal(chunk)
+			if err != nil {
+				t.Fatalf("expected no error, got %s", err)
+			}
+			if len(data) > 512 {
+				t.Fatalf("expected <= 512 bytes, got %d", len(data))
+			}
+			total += len(chunk)
+		}
+		if total != 100 {
+			t.Fatalf("expected 100 metrics, got %d", total)
+		}
+	}
+}
+
+func TestSubmitMetricsChunked(t *testing.T) {
+	t.Log("Testing submitMetrics chunking and bisection")
+
+	var mu sync.Mutex
+	received := make(map[string]bool)
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var metrics map[string]MetricSample
+		if err := json.NewDecoder(r.Body).Decode(&metrics); err != nil {
+			t.Errorf("decoding submission: %s", err)
+		}
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		if _, ok := metrics["metric_042"]; ok {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid metric"}`)
+			return
+		}
+		mu.Lock()
+		for name := range metrics {
+			received[name] = true
+		}
+		mu.Unlock()
+		fmt.Fprintf(w, `{"stats":%d}`, len(metrics))
+	}))
+	defer srv.Close()
+
+	c := &Check{
+		config:          &config.Circonus{SubmitParallelism: 3},
+		log:             zerolog.Nop(),
+		submitDeadline:  5 * time.Second,
+		submissionURL:   srv.URL,
+		maxChunkMetrics: 20,
+	}
+
+	metrics := make(map[string]MetricSample)
+	for i := 0; i < 100; i++ {
+		metrics[fmt.Sprintf("metric_%03d", i)] = MetricSample{Type: MetricTypeUint64, Value: uint64(i)}
+	}
+
+	if err := c.submitMetrics(context.Background(), metrics, zerolog.Nop(), true); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	if len(received) != 99 {
+		t.Fatalf("expected 99 metrics, got %d", len(received))
+	}
+	if received["metric_042"] {
+		t.Fatal("expected metric_042 to be dropped")
+	}
+	if requests <= 5 {
+		t.Fatalf("expected rejected chunk to be bisected, got %d requests", requests)
+	}
+	if stats := c.SubmitStats(); stats.SentMetrics != 99 || stats.RecvMetrics != 99 {
+		t.Fatalf("expected 99 sent/received metrics, got %d/%d", stats.SentMetrics, stats.RecvMetrics)
+	}
+}
+
+func TestSubmitMetricsAuthFailure(t *testing.T) {
+	t.Log("Testing submitMetrics does not bisect auth failures")
+
+	var mu sync.Mutex
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"error":"forbidden"}`)
+	}))
+	defer srv.Close()
+
+	c := &Check{
+		config:          &config.Circonus{SubmitParallelism: 1},
+		log:             zerolog.Nop(),
+		submitDeadline:  5 * time.Second,
+		submissionURL:   srv.URL,
+		maxChunkMetrics: 20,
+	}
+
+	metrics := make(map[string]MetricSample)
+	for i := 0; i < 20; i++ {
+		metrics[fmt.Sprintf("metric_%03d", i)] = MetricSample{Type: MetricTypeUint64, Value: uint64(i)}
+	}
+
+	err := c.submitMetrics(context.Background(), metrics, zerolog.Nop(), true)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	var rejected *submitRejectedError
+	if errors.As(err, &rejected) {
+		t.Fatalf("expected non-rejection error, got %s", err)
+	}
+	if requests != 1 {
+		t.Fatalf("expected 1 request, got %d", requests)
+	}
+}
+
+func TestSubmitClientKeepAlive(t *testing.T) {
+	t.Log("Testing broker connection reuse")
+
+	var mu sync.Mutex
+	conns := 0
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"stats":1}`)
+	}))
+	srv.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			conns++
+			mu.Unlock()
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	c := &Check{
+		config:         &config.Circonus{},
+		log:            zerolog.Nop(),
+		submitDeadline: 5 * time.Second,
+		submissionURL:  srv.URL,
+	}
+
+	for i := 0; i < 3; i++ {
+		metrics := map[string]MetricSample{"foo": {Type: MetricTypeUint64, Value: uint64(i)}}
+		if err := c.submitMetrics(context.Background(), metrics, zerolog.Nop(), false); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if conns != 1 {
+		t.Fatalf("expected 1 connection, got %d", conns)
+	}
+}
+
+func TestSubmitMetricsStream(t *testing.T) {
+	t.Log("Testing streaming submission")
+
+	var mu sync.Mutex
+	attempts := 0
+	var received map[string]MetricSample
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Content-Encoding") != "gzip" {
+			t.Errorf("expected gzip encoding, got %q", r.Header.Get("Content-Encoding"))
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("creating gzip reader: %s", err)
+			return
+		}
+		if err := json.NewDecoder(zr).Decode(&received); err != nil {
+			t.Errorf("decoding submission: %s", err)
+		}
+		fmt.Fprintf(w, `{"stats":%d}`, len(received))
+	}))
+	defer srv.Close()
+
+	c := &Check{
+		config:         &config.Circonus{SubmitStreamMin: 100, UseGZIP: true},
+		log:            zerolog.Nop(),
+		submitDeadline: 5 * time.Second,
+		submissionURL:  srv.URL,
+	}
+
+	metrics := make(map[string]MetricSample)
+	for i := 0; i < 100; i++ {
+		metrics[fmt.Sprintf("metric_%03d", i)] = MetricSample{Type: MetricTypeUint64, Value: uint64(i), Timestamp: 1700000000000}
+	}
+
+	if err := c.submitMetrics(context.Background(), metrics, zerolog.Nop(), true); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	if attempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", attempts)
+	}
+	if len(received) != len(metrics) {
+		t.Fatalf("expected %d metrics, got %d", len(metrics), len(received))
+	}
+	if m := received["metric_042"]; m.Timestamp != 1700000000000 || m.Value.(float64) != 42 {
+		t.Fatalf("unexpected metric %#v", m)
+	}
+
+	expect, err := json.Marshal(metrics)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	stats := c.SubmitStats()
+	if stats.SentBytes != uint64(len(expect)) {
+		t.Fatalf("expected %d raw bytes, got %d", len(expect), stats.SentBytes)
+	}
+	if stats.SentSize == 0 || stats.SentSize >= stats.SentBytes {
+		t.Fatalf("expected compressed size < %d, got %d", stats.SentBytes, stats.SentSize)
+	}
+}
+
+func TestSubmitMetricsStreamMin(t *testing.T) {
+	t.Log("Testing streaming submission minimum chunk size")
+
+	type request struct {
+		contentLength int64
+		encoding      string
+		metrics       int
+	}
+	var mu sync.Mutex
+	var requests []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("creating gzip reader: %s", err)
+				return
+			}
+			body = zr
+		}
+		var received map[string]MetricSample
+		if err := json.NewDecoder(body).Decode(&received); err != nil {
+			t.Errorf("decoding submission: %s", err)
+		}
+		mu.Lock()
+		requests = append(requests, request{contentLength: r.ContentLength, encoding: r.Header.Get("Content-Encoding"), metrics: len(received)})
+		mu.Unlock()
+		fmt.Fprintf(w, `{"stats":%d}`, len(received))
+	}))
+	defer srv.Close()
+
+	c := &Check{
+		config:         &config.Circonus{SubmitStreamMin: 100, UseGZIP: true},
+		log:            zerolog.Nop(),
+		submitDeadline: 5 * time.Second,
+		submissionURL:  srv.URL,
+	}
+
+	for _, n := range []int{1, 99, 100} {
+		metrics := make(map[string]MetricSample)
+		for i := 0; i < n; i++ {
+			metrics[fmt.Sprintf("metric_%03d", i)] = MetricSample{Type: MetricTypeUint64, Value: uint64(i)}
+		}
+		if err := c.submitMetrics(context.Background(), metrics, zerolog.Nop(), true); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+	}
+
+	if len(requests) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(requests))
+	}
+
+	t.Log("below compression threshold, not streamed")
+	if r := requests[0]; r.contentLength <= 0 || r.encoding != "" || r.metrics != 1 {
+		t.Fatalf("expected uncompressed request with content length, got %+v", r)
+	}
+
+	t.Log("below stream minimum, not streamed")
+	if r := requests[1]; r.contentLength <= 0 || r.encoding != "gzip" || r.metrics != 99 {
+		t.Fatalf("expected compressed request with content length, got %+v", r)
+	}
+
+	t.Log("stream minimum, streamed")
+	if r := requests[2]; r.contentLength != -1 || r.encoding != "gzip" || r.metrics != 100 {
+		t.Fatalf("expected compressed request without content length, got %+v", r)
+	}
+}
+
+func TestSubmitMetricsStreamTrace(t *testing.T) {
+	t.Log("Testing streaming submission trace file")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var metrics map[string]MetricSample
+		if err := json.NewDecoder(r.Body).Decode(&metrics); err != nil {
+			t.Errorf("decoding submission: %s", err)
+		}
+		fmt.Fprintf(w, `{"stats":%d}`, len(metrics))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	c := &Check{
+		config:         &config.Circonus{SubmitStreamMin: 100, TraceSubmits: dir},
+		log:            zerolog.Nop(),
+		submitDeadline: 5 * time.Second,
+		submissionURL:  srv.URL,
+	}
+
+	metrics := make(map[string]MetricSample)
+	for i := 0; i < 1000; i++ {
+		metrics[fmt.Sprintf("metric_%03d", i)] = MetricSample{Type: MetricTypeUint64, Value: uint64(i)}
+	}
+
+	if err := c.submitMetrics(context.Background(), metrics, zerolog.Nop(), false); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected 1 trace file, got %d", len(files))
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	var traced map[string]MetricSample
+	if err := json.Unmarshal(data, &traced); err != nil {
+		t.Fatalf("expected valid trace, got %s", err)
+	}
+	if len(traced) != len(metrics) {
+		t.Fatalf("expected %d traced metrics, got %d", len(metrics), len(traced))
+	}
+}
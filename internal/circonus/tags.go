@@ -188,6 +188,58 @@ func encodeTags(tags []string, useBase64 bool) string {
 	return strings.Join(tagList, ",")
 }
 
+// parseTaggedName splits a tagged metric name into the base metric
+// name, stream tags, and measurement tags (decoding base64 tags)
+func parseTaggedName(taggedName string) (string, Tags, Tags) {
+	name := taggedName
+	var streamTags, measurementTags Tags
+
+	if idx := strings.Index(name, "|MT["); idx != -1 && strings.HasSuffix(name, "]") {
+		measurementTags = decodeTags(name[idx+4 : len(name)-1])
+		name = name[:idx]
+	}
+	if idx := strings.Index(name, "|ST["); idx != -1 && strings.HasSuffix(name, "]") {
+		streamTags = decodeTags(name[idx+4 : len(name)-1])
+		name = name[:idx]
+	}
+
+	return name, streamTags, measurementTags
+}
+
+// decodeTags parses an encoded tag list (the contents of ST[...] or MT[...])
+func decodeTags(tagList string) Tags {
+	if tagList == "" {
+		return Tags{}
+	}
+
+	tags := make(Tags, 0, strings.Count(tagList, ",")+1)
+	for _, tag := range strings.Split(tagList, ",") {
+		if tag == "" {
+			continue
+		}
+		tc, tv, _ := strings.Cut(tag, ":")
+		tc = decodeTagPart(tc)
+		if tc == "" {
+			continue
+		}
+		tags = append(tags, Tag{Category: tc, Value: decodeTagPart(tv)})
+	}
+
+	return tags
+}
+
+// decodeTagPart decodes a base64 encoded (b"...") tag category or value
+func decodeTagPart(s string) string {
+	if len(s) < 3 || !strings.HasPrefix(s, `b"`) || !strings.HasSuffix(s, `"`) {
+		return s
+	}
+	data, err := base64.StdEncoding.DecodeString(s[2 : len(s)-1])
+	if err != nil {
+		return s
+	}
+	return string(data)
+}
+
 func removeSpaces(r rune) rune {
 	if unicode.IsSpace(r) {
 		return -1
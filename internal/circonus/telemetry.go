@@ -0,0 +1,316 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package circonus
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	cgm "github.com/circonus-labs/circonus-gometrics/v3"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"google.golang.org/protobuf/proto"
+)
+
+// telemetryPrefix selects the agent's self-telemetry metrics (collect_*) which
+// are also exposed in prometheus exposition format at /metrics
+const telemetryPrefix = "collect_"
+
+// telemetryBuckets are the upper bounds of the latency histograms (collect_latency
+// samples are in milliseconds)
+var telemetryBuckets = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000}
+
+const (
+	// telemetryStaleAfter is how long a series is kept without being updated, series
+	// tagged per target (e.g. request=<pod url>) come and go with the targets
+	telemetryStaleAfter = 30 * time.Minute
+	// telemetryMaxSeries caps the number of series per metric name, the least
+	// recently updated series is evicted to make room for a new one
+	telemetryMaxSeries = 1000
+)
+
+// telemetry holds the self-telemetry of all clusters (checks) in the agent
+var telemetry = newTelemetryRegistry()
+
+type telemetrySeries struct {
+	updated time.Time
+	labels  []*dto.LabelPair
+	value   float64
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+type telemetryFamily struct {
+	series map[string]*telemetrySeries
+	mtype  dto.MetricType
+}
+
+type telemetryRegistry struct {
+	families map[string]*telemetryFamily
+	sync.Mutex
+}
+
+func newTelemetryRegistry() *telemetryRegistry {
+	return &telemetryRegistry{families: make(map[string]*telemetryFamily)}
+}
+
+// series returns the series for the metric name, cluster and tags, creating it when
+// needed. A metric name is registered with the type of its first sample, samples of a
+// different type are ignored. Must be called with the registry locked.
+func (r *telemetryRegistry) series(metricName, cluster string, tags cgm.Tags, mtype dto.MetricType) *telemetrySeries {
+	if !strings.HasPrefix(metricName, telemetryPrefix) {
+		return nil
+	}
+
+	fam, ok := r.families[metricName]
+	if !ok {
+		fam = &telemetryFamily{mtype: mtype, series: make(map[string]*telemetrySeries)}
+		r.families[metricName] = fam
+	}
+	if fam.mtype != mtype {
+		return nil
+	}
+
+	if cluster != "" {
+		// the check's cluster takes precedence over a cluster tag passed by the caller
+		tags = append(cgm.Tags{cgm.Tag{Category: "cluster", Value: cluster}}, tags...)
+	}
+	labels := telemetryLabels(tags)
+	var key strings.Builder
+	for _, l := range labels {
+		key.WriteString(l.GetName())
+		key.WriteByte('=')
+		key.WriteString(l.GetValue())
+		key.WriteByte(',')
+	}
+
+	s, ok := fam.series[key.String()]
+	if !ok {
+		if len(fam.series) >= telemetryMaxSeries {
+			fam.evictOldest()
+		}
+		s = &telemetrySeries{labels: labels}
+		if mtype == dto.MetricType_HISTOGRAM {
+			s.buckets = make([]uint64, len(telemetryBuckets))
+		}
+		fam.series[key.String()] = s
+	}
+	s.updated = time.Now()
+	return s
+}
+
+// evictOldest removes the least recently updated series
+func (f *telemetryFamily) evictOldest() {
+	oldestKey := ""
+	var oldest time.Time
+	for key, s := range f.series {
+		if oldestKey == "" || s.updated.Before(oldest) {
+			oldestKey = key
+			oldest = s.updated
+		}
+	}
+	delete(f.series, oldestKey)
+}
+
+// expire removes series which have not been updated since before
+func (f *telemetryFamily) expire(before time.Time) {
+	for key, s := range f.series {
+		if s.updated.Before(before) {
+			delete(f.series, key)
+		}
+	}
+}
+
+func (r *telemetryRegistry) setGauge(metricName, cluster string, tags cgm.Tags, value interface{}) {
+	v, ok := telemetryValue(value)
+	if !ok {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+	if s := r.series(metricName, cluster, tags, dto.MetricType_GAUGE); s != nil {
+		s.value = v
+	}
+}
+
+// addCounter increments a counter, counters are monotonic as prometheus expects
+// (unlike the circonus counters which are reset each collection)
+func (r *telemetryRegistry) addCounter(metricName, cluster string, tags cgm.Tags, value uint64) {
+	r.Lock()
+	defer r.Unlock()
+	if s := r.series(metricName, cluster, tags, dto.MetricType_COUNTER); s != nil {
+		s.value += float64(value)
+	}
+}
+
+// setCounter sets a counter, lower values (resets) are ignored to keep the counter monotonic
+func (r *telemetryRegistry) setCounter(metricName, cluster string, tags cgm.Tags, value uint64) {
+	r.Lock()
+	defer r.Unlock()
+	if s := r.series(metricName, cluster, tags, dto.MetricType_COUNTER); s != nil && float64(value) > s.value {
+		s.value = float64(value)
+	}
+}
+
+func (r *telemetryRegistry) observe(metricName, cluster string, tags cgm.Tags, value float64) {
+	r.Lock()
+	defer r.Unlock()
+	s := r.series(metricName, cluster, tags, dto.MetricType_HISTOGRAM)
+	if s == nil {
+		return
+	}
+	for i, le := range telemetryBuckets {
+		if value <= le {
+			s.buckets[i]++
+		}
+	}
+	s.count++
+	s.sum += value
+}
+
+// gather returns the metric families sorted by name, stale series are expired
+func (r *telemetryRegistry) gather() []*dto.MetricFamily {
+	r.Lock()
+	defer r.Unlock()
+
+	stale := time.Now().Add(-telemetryStaleAfter)
+
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	mfs := make([]*dto.MetricFamily, 0, len(names))
+	for _, name := range names {
+		fam := r.families[name]
+		fam.expire(stale)
+		if len(fam.series) == 0 {
+			continue
+		}
+		mf := &dto.MetricFamily{
+			Name: proto.String(name),
+			Type: fam.mtype.Enum(),
+		}
+		keys := make([]string, 0, len(fam.series))
+		for key := range fam.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			s := fam.series[key]
+			m := &dto.Metric{Label: s.labels}
+			switch fam.mtype {
+			case dto.MetricType_COUNTER:
+				m.Counter = &dto.Counter{Value: proto.Float64(s.value)}
+			case dto.MetricType_HISTOGRAM:
+				h := &dto.Histogram{
+					SampleCount: proto.Uint64(s.count),
+					SampleSum:   proto.Float64(s.sum),
+				}
+				for i, le := range telemetryBuckets {
+					h.Bucket = append(h.Bucket, &dto.Bucket{
+						UpperBound:      proto.Float64(le),
+						CumulativeCount: proto.Uint64(s.buckets[i]),
+					})
+				}
+				m.Histogram = h
+			default:
+				m.Gauge = &dto.Gauge{Value: proto.Float64(s.value)}
+			}
+			mf.Metric = append(mf.Metric, m)
+		}
+		mfs = append(mfs, mf)
+	}
+
+	return mfs
+}
+
+// TelemetryHandler serves the agent's self-telemetry (collect_* metrics of all
+// clusters) in prometheus exposition format, the format is negotiated with the
+// Accept header (text by default).
+func TelemetryHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format := expfmt.Negotiate(r.Header)
+		w.Header().Set("Content-Type", string(format))
+		enc := expfmt.NewEncoder(w, format)
+		for _, mf := range telemetry.gather() {
+			if err := enc.Encode(mf); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+	})
+}
+
+// telemetryLabels converts stream tags to labels, sorted by name. Tag categories are
+// sanitized to valid label names, the first tag wins when categories collide.
+func telemetryLabels(tags cgm.Tags) []*dto.LabelPair {
+	seen := make(map[string]bool, len(tags))
+	labels := make([]*dto.LabelPair, 0, len(tags))
+	for _, tag := range tags {
+		name := telemetryLabelName(tag.Category)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		labels = append(labels, &dto.LabelPair{Name: proto.String(name), Value: proto.String(tag.Value)})
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].GetName() < labels[j].GetName() })
+	return labels
+}
+
+// telemetryLabelName replaces characters which are not valid in a prometheus label name
+func telemetryLabelName(category string) string {
+	var b strings.Builder
+	for i, r := range category {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9' && i > 0:
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	name := b.String()
+	if strings.HasPrefix(name, "__") {
+		name = strings.TrimLeft(name, "_")
+	}
+	return name
+}
+
+// telemetryValue converts a gauge value to a float64
+func telemetryValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		if math.IsNaN(v) {
+			return 0, false
+		}
+		return v, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package circonus
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	cgm "github.com/circonus-labs/circonus-gometrics/v3"
+	"github.com/prometheus/common/expfmt"
+)
+
+func TestTelemetryHandler(t *testing.T) {
+	t.Log("Testing TelemetryHandler")
+
+	c := &Check{}
+	tags := cgm.Tags{
+		cgm.Tag{Category: "source", Value: "test"},
+		cgm.Tag{Category: "cluster", Value: "test-cluster"},
+		cgm.Tag{Category: "source", Value: "duplicate"},
+	}
+
+	c.AddGauge("collect_test_gauge", tags, uint64(10))
+	c.AddGauge("collect_test_gauge", tags, 20)
+	c.IncrementCounter("collect_test_counter", tags)
+	c.IncrementCounterByValue("collect_test_counter", tags, 4)
+	c.SetCounter("collect_test_counter", tags, 0) // reset, ignored
+	c.AddHistSample("collect_test_latency", cgm.Tags{cgm.Tag{Category: "op", Value: "test-op"}}, 7)
+	c.AddHistSample("collect_test_latency", cgm.Tags{cgm.Tag{Category: "op", Value: "test-op"}}, 700)
+	c.AddGauge("not_telemetry", tags, 1)
+	c.IncrementCounter("collect_test_gauge", tags) // type mismatch, ignored
+
+	srv := httptest.NewServer(TelemetryHandler())
+	defer srv.Close()
+
+	t.Log("text format")
+	{
+		resp, err := http.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != string(expfmt.FmtText) {
+			t.Fatalf("expected text content type, got %s", ct)
+		}
+
+		text := string(body)
+		for _, expect := range []string{
+			"# TYPE collect_test_gauge gauge",
+			`collect_test_gauge{cluster="test-cluster",source="test"} 20`,
+			"# TYPE collect_test_counter counter",
+			`collect_test_counter{cluster="test-cluster",source="test"} 5`,
+			"# TYPE collect_test_latency histogram",
+			`collect_test_latency_bucket{op="test-op",le="10"} 1`,
+			`collect_test_latency_bucket{op="test-op",le="1000"} 2`,
+			`collect_test_latency_bucket{op="test-op",le="+Inf"} 2`,
+			`collect_test_latency_sum{op="test-op"} 707`,
+			`collect_test_latency_count{op="test-op"} 2`,
+		} {
+			if !strings.Contains(text, expect) {
+				t.Fatalf("expected %q in\n%s", expect, text)
+			}
+		}
+		if strings.Contains(text, "not_telemetry") {
+			t.Fatalf("expected only collect_ metrics, got\n%s", text)
+		}
+	}
+
+	t.Log("protobuf format")
+	{
+		req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		req.Header.Set("Accept", string(expfmt.FmtProtoDelim))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		defer resp.Body.Close()
+		if ct := resp.Header.Get("Content-Type"); ct != string(expfmt.FmtProtoDelim) {
+			t.Fatalf("expected protobuf content type, got %s", ct)
+		}
+	}
+}
+
+func TestTelemetryLabelName(t *testing.T) {
+	t.Log("Testing telemetryLabelName")
+
+	tests := []struct {
+		category string
+		expect   string
+	}{
+		{"cluster", "cluster"},
+		{"k8s-namespace", "k8s_namespace"},
+		{"app.kubernetes.io/name", "app_kubernetes_io_name"},
+		{"1node", "_node"},
+		{"__name__", "name__"},
+	}
+
+	for _, tst := range tests {
+		if got := telemetryLabelName(tst.category); got != tst.expect {
+			t.Fatalf("expected %s, got %s", tst.expect, got)
+		}
+	}
+}
+
+func TestTelemetryClusters(t *testing.T) {
+	t.Log("Testing telemetry series per cluster")
+
+	r := newTelemetryRegistry()
+	tags := cgm.Tags{cgm.Tag{Category: "source", Value: "test"}}
+	r.setGauge("collect_test_gauge", "c1", tags, 1)
+	r.setGauge("collect_test_gauge", "c2", tags, 2)
+	r.addCounter("collect_test_counter", "c1", tags, 1)
+	r.addCounter("collect_test_counter", "c2", tags, 1)
+	r.setGauge("collect_test_override", "c1", cgm.Tags{cgm.Tag{Category: "cluster", Value: "other"}}, 1)
+
+	for _, mf := range r.gather() {
+		switch mf.GetName() {
+		case "collect_test_gauge", "collect_test_counter":
+			if len(mf.Metric) != 2 {
+				t.Fatalf("expected 2 series for %s, got %d", mf.GetName(), len(mf.Metric))
+			}
+			for _, m := range mf.Metric {
+				if m.GetGauge().GetValue() == 2 && m.Label[0].GetValue() != "c2" {
+					t.Fatalf("expected c2 gauge, got %s", m.Label[0].GetValue())
+				}
+				if m.GetCounter() != nil && m.GetCounter().GetValue() != 1 {
+					t.Fatalf("expected counter 1, got %f", m.GetCounter().GetValue())
+				}
+			}
+		case "collect_test_override":
+			if v := mf.Metric[0].Label[0].GetValue(); v != "c1" {
+				t.Fatalf("expected check cluster c1, got %s", v)
+			}
+		}
+	}
+}
+
+func TestTelemetryEviction(t *testing.T) {
+	t.Log("Testing telemetry series eviction")
+
+	r := newTelemetryRegistry()
+	for i := 0; i < telemetryMaxSeries+10; i++ {
+		r.observe("collect_test_latency", "c1", cgm.Tags{cgm.Tag{Category: "request", Value: fmt.Sprintf("http://10.0.0.%d/metrics", i)}}, 1)
+	}
+
+	r.Lock()
+	fam := r.families["collect_test_latency"]
+	if len(fam.series) != telemetryMaxSeries {
+		r.Unlock()
+		t.Fatalf("expected %d series, got %d", telemetryMaxSeries, len(fam.series))
+	}
+	for _, s := range fam.series {
+		s.updated = time.Now().Add(-2 * telemetryStaleAfter)
+	}
+	r.Unlock()
+
+	r.setGauge("collect_test_gauge", "c1", nil, 1)
+	for _, mf := range r.gather() {
+		if mf.GetName() == "collect_test_latency" {
+			t.Fatalf("expected stale series to be expired, got %d", len(mf.Metric))
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"expvar"
 	"fmt"
 	"os"
 	"runtime"
@@ -20,33 +21,47 @@ import (
 	"time"
 
 	cgm "github.com/circonus-labs/circonus-gometrics/v3"
-	"github.com/circonus-labs/circonus-kubernetes-agent/internal/as"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/circonus"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config/defaults"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/dc"
-	"github.com/circonus-labs/circonus-kubernetes-agent/internal/dns"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/events"
-	"github.com/circonus-labs/circonus-kubernetes-agent/internal/health"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/k8s"
-	"github.com/circonus-labs/circonus-kubernetes-agent/internal/ksm"
-	"github.com/circonus-labs/circonus-kubernetes-agent/internal/nodes"
+	nodecollector "github.com/circonus-labs/circonus-kubernetes-agent/internal/nodes/collector"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/release"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
 )
 
 type Cluster struct {
 	sync.Mutex
-	tlsConfig       *tls.Config
-	check           *circonus.Check
-	lastStart       *time.Time
-	logger          zerolog.Logger
-	collectors      []string
-	circCfg         config.Circonus
-	cfg             config.Cluster
-	interval        time.Duration
-	collectDeadline time.Duration
-	running         bool
+	tlsConfig        *tls.Config
+	check            *circonus.Check
+	clientset        kubernetes.Interface
+	informers        informers.SharedInformerFactory
+	podInformers     informers.SharedInformerFactory
+	pods             *k8s.PodCache
+	kubelet          *nodecollector.Kubelet
+	election         *election
+	dynamic          *dc.DC
+	pending          *pendingConfig
+	events           *events.Events
+	lastStart        *time.Time
+	started          time.Time
+	lastCollect      time.Time
+	logger           zerolog.Logger
+	collectors       []string
+	circCfg          config.Circonus
+	cfg              *config.Cluster // replaced (not modified) by a configuration reload
+	interval         time.Duration
+	collectDeadline  time.Duration
+	deadlineTimeouts uint
+	schedules        map[string]collectorSchedule // collectors with their own interval and deadline
+	scheduled        map[string]bool              // collectors running on their own schedule
+	running          map[string]bool              // collectors currently collecting
+	collecting       bool                         // a collection (collectors and tracking metric flush) is in progress
 }
 type Collector interface {
 	ID() string
@@ -57,27 +72,58 @@ func New(ctx context.Context, cfg config.Cluster, circCfg config.Circonus, paren
 	if cfg.Name == "" {
 		return nil, errors.New("invalid cluster config (empty name)")
 	}
-	if cfg.BearerToken == "" && cfg.BearerTokenFile == "" {
-		return nil, errors.New("invalid bearer credentials (empty)")
+	if cfg.BearerToken == "" && cfg.BearerTokenFile == "" && cfg.ClientCertFile == "" && cfg.Exec.Command == "" && cfg.Kubeconfig == "" {
+		return nil, errors.New("invalid credentials (empty), bearer token, client certificate, exec plugin or kubeconfig required")
+	}
+	if (cfg.ClientCertFile == "") != (cfg.ClientKeyFile == "") {
+		return nil, errors.New("invalid client certificate credentials, both a cert and key file are required")
 	}
 
 	c := &Cluster{
-		cfg:        cfg,
-		circCfg:    circCfg,
-		collectors: []string{"health"},
-		logger:     parentLog.With().Str("pkg", "cluster").Str("cluster_name", cfg.Name).Logger(),
+		cfg:       &cfg,
+		circCfg:   circCfg,
+		logger:    parentLog.With().Str("pkg", "cluster").Str("cluster_name", cfg.Name).Logger(),
+		scheduled: make(map[string]bool),
+		running:   make(map[string]bool),
 	}
 
-	if c.cfg.BearerToken == "" && c.cfg.BearerTokenFile != "" {
-		token, err := os.ReadFile(c.cfg.BearerTokenFile)
-		if err != nil {
-			return nil, errors.Wrap(err, "bearer token file")
+	switch c.cfg.Mode {
+	case "":
+		c.cfg.Mode = config.ModeAll
+	case config.ModeAll, config.ModeCluster:
+	case config.ModeNode:
+		if c.cfg.NodeName == "" {
+			return nil, errors.New("invalid node name (empty), required in node mode")
+		}
+		c.logger = c.logger.With().Str("node", c.cfg.NodeName).Logger()
+	default:
+		return nil, errors.Errorf("invalid mode (%s)", c.cfg.Mode)
+	}
+	c.logger.Debug().Str("mode", c.cfg.Mode).Msg("using collection mode")
+
+	// a token file is re-read when rotated (see k8s.ClusterTokenSource), it is not
+	// used with a kubeconfig or other credentials
+	ts, err := k8s.ClusterTokenSource(c.cfg)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c.cfg.Kubeconfig != "":
+		c.logger.Debug().Str("kubeconfig", c.cfg.Kubeconfig).Str("context", c.cfg.KubeContext).Msg("using kubeconfig")
+	case ts != nil:
+		token := ts.Token()
+		if len(token) > 8 {
+			token = token[0:8]
 		}
-		c.cfg.BearerToken = string(token)
+		c.logger.Debug().Str("token", token+"...").Msg("using bearer token")
+	case c.cfg.ClientCertFile != "":
+		c.logger.Debug().Str("cert", c.cfg.ClientCertFile).Msg("using client certificate")
+	case c.cfg.Exec.Command != "":
+		c.logger.Debug().Str("command", c.cfg.Exec.Command).Msg("using exec plugin credentials")
 	}
-	c.logger.Debug().Str("token", c.cfg.BearerToken[0:8]+"...").Msg("using bearer token")
 
-	if c.cfg.CAFile != "" {
+	// the ca file defaults to the service account's, which is not used with a kubeconfig
+	if c.cfg.CAFile != "" && c.cfg.Kubeconfig == "" {
 		cert, err := os.ReadFile(c.cfg.CAFile)
 		if err != nil {
 			return nil, errors.Wrap(err, "configuring k8s api tls")
@@ -108,6 +154,60 @@ func New(ctx context.Context, cfg config.Cluster, circCfg config.Circonus, paren
 	c.collectDeadline = d
 	c.logger.Debug().Str("deadline", d.String()).Msg("using collect deadline")
 
+	schedules, err := parseSchedules(c.cfg.CollectorSchedules, c.interval, c.collectDeadline)
+	if err != nil {
+		return nil, err
+	}
+	c.schedules = schedules
+
+	// one clientset and informer factory shared by all collectors for the cluster
+	clientset, err := k8s.GetClient(c.cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "initializing k8s client")
+	}
+	c.clientset = clientset
+
+	podResync := c.cfg.PodCacheResync
+	if podResync == "" {
+		podResync = defaults.K8SPodCacheResync
+	}
+	d, err = time.ParseDuration(podResync)
+	if err != nil {
+		return nil, fmt.Errorf("parsing pod cache resync %s: %w", podResync, err)
+	}
+	c.informers = k8s.NewInformerFactory(clientset, d)
+	c.podInformers = c.informers
+	if c.cfg.Mode == config.ModeNode {
+		// only cache the pods on the local node
+		c.podInformers = k8s.NewNodeInformerFactory(clientset, d, c.cfg.NodeName)
+	}
+
+	switch c.cfg.NodeRequestMode {
+	case "", nodecollector.RequestModeProxy:
+	case nodecollector.RequestModeDirect:
+		kubelet, err := nodecollector.NewKubelet(c.cfg)
+		if err != nil {
+			return nil, errors.Wrap(err, "initializing direct kubelet client")
+		}
+		c.kubelet = kubelet
+		c.logger.Debug().Msg("using direct kubelet requests")
+	default:
+		return nil, errors.Errorf("invalid node request mode (%s)", c.cfg.NodeRequestMode)
+	}
+
+	if c.cfg.LeaderElection.Enabled {
+		if c.cfg.Mode == config.ModeNode {
+			c.logger.Warn().Msg("leader election not used in node mode, each instance collects its own node")
+		} else {
+			e, err := newElection(c.cfg.Name, c.cfg.LeaderElection, clientset)
+			if err != nil {
+				return nil, errors.Wrap(err, "initializing leader election")
+			}
+			c.election = e
+			c.logger.Debug().Str("lease", e.status.Lease).Str("identity", e.status.Identity).Msg("using leader election")
+		}
+	}
+
 	// set check title if it has not been explicitly set by user
 	if circCfg.Check.Title == "" {
 		circCfg.Check.Title = fmt.Sprintf("%s /%s", cfg.Name, release.NAME)
@@ -122,64 +222,122 @@ func New(ctx context.Context, cfg config.Cluster, circCfg config.Circonus, paren
 	}
 	c.check = check
 
-	if c.cfg.EnableKubeStateMetrics {
-		c.collectors = append(c.collectors, "ksm")
+	c.collectors = enabledCollectors(c.cfg)
+	if len(c.collectors) == 0 {
+		return nil, errors.Errorf("no collectors enabled for cluster %s", c.cfg.Name)
 	}
 
-	if c.cfg.EnableAPIServer {
-		c.collectors = append(c.collectors, "api")
-	}
+	return c, nil
+}
 
-	if c.cfg.EnableDNSMetrics {
-		c.collectors = append(c.collectors, "dns")
-	}
+// enabledCollectors returns the ids of the collectors enabled in cfg
+func enabledCollectors(cfg *config.Cluster) []string {
+	collectors := []string{}
 
-	if c.cfg.EnableNodes {
-		// node metrics, as well as, pod and container metrics (both optional)
-		c.collectors = append(c.collectors, "node")
+	if cfg.Mode != config.ModeNode {
+		// cluster-scope collectors, when running as a DaemonSet (node mode)
+		// these are collected by the single cluster mode instance
+		collectors = append(collectors, "health")
+
+		if cfg.EnableKubeStateMetrics {
+			collectors = append(collectors, "ksm")
+		}
+
+		if cfg.EnableAPIServer {
+			collectors = append(collectors, "api")
+		}
+
+		if cfg.EnableDNSMetrics {
+			collectors = append(collectors, "dns")
+		}
 	}
 
-	if len(c.collectors) == 0 {
-		return nil, errors.Errorf("no collectors enabled for cluster %s", c.cfg.Name)
+	if cfg.EnableNodes && cfg.Mode != config.ModeCluster {
+		// node metrics, as well as, pod and container metrics (both optional)
+		collectors = append(collectors, "node")
 	}
 
-	return c, nil
+	return collectors
 }
 
 func (c *Cluster) Start(ctx context.Context) error {
 	var eventWatcher *events.Events
-	if c.cfg.EnableEvents {
-		ew, err := events.New(&c.cfg, c.logger, c.check)
+	if c.cfg.EnableEvents && c.cfg.Mode != config.ModeNode {
+		ew, err := events.New(c.cfg, c.logger, c.check, c.informers)
 		if err != nil {
 			return errors.Wrap(err, "initializing events collector")
 		}
 		eventWatcher = ew
 	}
 
-	var dynamicCollectors *dc.DC
-	if c.cfg.DynamicCollectorFile != "" {
-		var err error
-		d, err := dc.New(&c.cfg, c.logger, c.check)
+	if c.cfg.DynamicCollectorFile != "" && c.cfg.Mode != config.ModeNode {
+		d, err := dc.New(c.cfg, c.logger, c.check, c.clientset)
 		if err != nil {
 			c.logger.Warn().Err(err).Msg("initializing dynamic collectors, disabling")
 		} else {
-			dynamicCollectors = d
+			c.dynamic = d
 		}
 	}
 
-	if len(c.collectors) == 0 && eventWatcher == nil && dynamicCollectors == nil {
+	if len(c.collectors) == 0 && eventWatcher == nil && c.dynamic == nil {
 		return errors.New("invalid cluster (zero collectors)")
 	}
 
+	c.Lock()
+	c.started = time.Now()
+	c.events = eventWatcher
+	c.Unlock()
+
+	// informers are started for standby replicas as well, so they are warm when taking over
 	if eventWatcher != nil {
+		if c.election != nil {
+			eventWatcher.Pause()
+		}
 		go eventWatcher.Start(ctx, c.tlsConfig)
 	}
 
-	c.collect(ctx, dynamicCollectors)
+	if c.cfg.EnableNodes && c.cfg.IncludePods && c.cfg.Mode != config.ModeCluster {
+		if err := c.startPodCache(ctx); err != nil {
+			c.logger.Warn().Err(err).Msg("initializing pod cache, fetching pods from api server")
+		}
+	}
+
+	if c.election == nil {
+		return c.run(ctx)
+	}
 
-	c.logger.Info().Str("collection_interval", c.interval.String()).Time("next_collection", time.Now().Add(c.interval)).Msg("client started")
+	// published when started, a restarted cluster's previous instance is closed first
+	leaderStats.Set(c.election.cluster, expvar.Func(c.election.stats))
 
-	ticker := time.NewTicker(c.interval)
+	c.logger.Info().Msg("waiting for leadership")
+	return c.lead(ctx, func(leaderCtx context.Context) error {
+		if eventWatcher != nil {
+			eventWatcher.Resume()
+			defer eventWatcher.Pause()
+		}
+		return c.run(leaderCtx)
+	})
+}
+
+// Close releases the state the cluster publishes (leader election status), called when
+// the cluster is removed from the configuration or restarted, after Start has returned
+func (c *Cluster) Close() {
+	if c.election != nil {
+		leaderStats.Delete(c.election.cluster)
+	}
+}
+
+// run collects every interval until ctx is done
+func (c *Cluster) run(ctx context.Context) error {
+	c.collect(ctx)
+
+	c.Lock()
+	interval := c.interval
+	c.Unlock()
+
+	c.logger.Info().Str("collection_interval", interval.String()).Time("next_collection", time.Now().Add(interval)).Msg("client started")
+
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -188,6 +346,12 @@ func (c *Cluster) Start(ctx context.Context) error {
 			return nil
 		case <-ticker.C:
 			c.Lock()
+			if c.interval != interval {
+				// changed by a configuration reload
+				interval = c.interval
+				ticker.Reset(interval)
+				c.logger.Info().Str("collection_interval", interval.String()).Msg("collection interval changed")
+			}
 			if c.lastStart != nil {
 				elapsed := time.Since(*c.lastStart)
 				if c.interval.Round(time.Second)-elapsed.Round(time.Second) > 2 {
@@ -200,7 +364,7 @@ func (c *Cluster) Start(ctx context.Context) error {
 					continue
 				}
 			}
-			if c.running {
+			if c.collecting {
 				c.Unlock()
 				c.logger.Warn().
 					Str("started", c.lastStart.String()).
@@ -211,22 +375,84 @@ func (c *Cluster) Start(ctx context.Context) error {
 			c.Unlock()
 
 			go func() {
-				c.collect(ctx, dynamicCollectors)
+				c.collect(ctx)
 			}()
 		}
 	}
 }
 
-func (c *Cluster) collect(ctx context.Context, dynamicCollectors *dc.DC) {
+// startPodCache starts the informer backed pod cache used by the node collector,
+// collections do not wait for it to sync (pods are fetched from the api server until it has)
+func (c *Cluster) startPodCache(ctx context.Context) error {
+	pods, err := k8s.NewPodCache(c.podInformers)
+	if err != nil {
+		return err
+	}
+	c.podInformers.Start(ctx.Done())
+
+	go func() {
+		start := time.Now()
+		if !pods.WaitForSync(ctx) {
+			return
+		}
+		c.logger.Info().Str("duration", time.Since(start).String()).Msg("pod cache synced")
+	}()
+
+	c.pods = pods
+	return nil
+}
+
+func (c *Cluster) collect(ctx context.Context) {
 	c.Lock()
+	if c.collecting {
+		c.Unlock()
+		c.logger.Warn().
+			Str("started", c.lastStart.String()).
+			Str("elapsed", time.Since(*c.lastStart).String()).
+			Msg("collection in progress, not starting another")
+		return
+	}
+	c.collecting = true
 	start := time.Now()
 	c.lastStart = &start
-	c.running = true
+	pending := c.pending
+	c.pending = nil
+	c.Unlock()
+
+	defer func() {
+		c.Lock()
+		c.collecting = false
+		c.Unlock()
+	}()
+
+	if pending != nil {
+		c.applyReload(pending)
+	}
+
+	// collectors with their own schedule run independently of the collection interval
+	c.startSchedules(ctx)
+
+	// the configuration used for this collection, a reload replaces (not modifies) it
+	c.Lock()
+	cfg := c.cfg
+	interval := c.interval
+	collectDeadline := c.collectDeadline
+	collectorIDs := []string{}
+	if c.dynamic != nil {
+		if _, ok := c.schedules[dynamicCollectorID]; !ok {
+			collectorIDs = append(collectorIDs, dynamicCollectorID)
+		}
+	}
+	for _, id := range c.collectors {
+		if _, ok := c.schedules[id]; !ok {
+			collectorIDs = append(collectorIDs, id)
+		}
+	}
 	c.Unlock()
 
 	c.logger.Info().Msg("collection start")
 
-	collectCtx, collectCancel := context.WithDeadline(ctx, time.Now().Add(c.collectDeadline))
+	collectCtx, collectCancel := context.WithDeadline(ctx, time.Now().Add(collectDeadline))
 	defer collectCancel()
 
 	// reset submit retries metric
@@ -234,94 +460,15 @@ func (c *Cluster) collect(ctx context.Context, dynamicCollectors *dc.DC) {
 
 	var wg sync.WaitGroup
 
-	if dynamicCollectors != nil {
+	for _, collectorID := range collectorIDs {
+		collectorID := collectorID
 		wg.Add(1)
 		go func() {
-			tm := time.Now()
-			c.logger.Info().Msg("starting dynamic collectors")
-			dynamicCollectors.Collect(collectCtx, c.tlsConfig, &start)
+			c.runCollector(collectCtx, collectorID, &start)
 			wg.Done()
-			c.logger.Info().Str("dur", time.Since(tm).String()).Str("sdur", time.Since(start).String()).Msg("finished dynamic collectors")
 		}()
 	}
 
-	for _, collectorID := range c.collectors {
-		switch collectorID {
-		case "node":
-			wg.Add(1)
-			go func() {
-				collector, err := nodes.New(&c.cfg, c.logger, c.check, c.circCfg.NodeCC)
-				if err != nil {
-					c.logger.Error().Err(err).Msg("initializing node collector")
-				} else {
-					tm := time.Now()
-					c.logger.Info().Msg("starting node collector")
-					collector.Collect(collectCtx, c.tlsConfig, &start)
-					c.logger.Info().Str("dur", time.Since(tm).String()).Str("sdur", time.Since(start).String()).Msg("finished node collector")
-				}
-				wg.Done()
-			}()
-		case "health":
-			wg.Add(1)
-			go func() {
-				collector, err := health.New(&c.cfg, c.logger, c.check)
-				if err != nil {
-					c.logger.Error().Err(err).Msg("initializing health collector")
-				} else {
-					tm := time.Now()
-					c.logger.Info().Msg("starting health collector")
-					collector.Collect(collectCtx, c.tlsConfig, &start)
-					c.logger.Info().Str("dur", time.Since(tm).String()).Str("sdur", time.Since(start).String()).Msg("finished health collector")
-				}
-				wg.Done()
-			}()
-		case "ksm":
-			wg.Add(1)
-			go func() {
-				collector, err := ksm.New(&c.cfg, c.logger, c.check)
-				if err != nil {
-					c.logger.Error().Err(err).Msg("initializing kube-state-metrics collector")
-				} else {
-					tm := time.Now()
-					c.logger.Info().Msg("starting ksm collector")
-					collector.Collect(collectCtx, c.tlsConfig, &start)
-					c.logger.Info().Str("dur", time.Since(tm).String()).Str("sdur", time.Since(start).String()).Msg("finished ksm collector")
-				}
-				wg.Done()
-			}()
-		case "api":
-			wg.Add(1)
-			go func() {
-				collector, err := as.New(&c.cfg, c.logger, c.check)
-				if err != nil {
-					c.logger.Error().Err(err).Msg("initializing api-server collector")
-				} else {
-					tm := time.Now()
-					c.logger.Info().Msg("starting api collector")
-					collector.Collect(collectCtx, c.tlsConfig, &start)
-					c.logger.Info().Str("dur", time.Since(tm).String()).Str("sdur", time.Since(start).String()).Msg("finished api collector")
-				}
-				wg.Done()
-			}()
-		case "dns":
-			wg.Add(1)
-			go func() {
-				collector, err := dns.New(&c.cfg, c.logger, c.check)
-				if err != nil {
-					c.logger.Error().Err(err).Msg("initializing kube-dns/coredns collector")
-				} else {
-					tm := time.Now()
-					c.logger.Info().Msg("starting dns collector")
-					collector.Collect(collectCtx, c.tlsConfig, &start)
-					c.logger.Info().Str("dur", time.Since(tm).String()).Str("sdur", time.Since(start).String()).Msg("finished dns collector")
-				}
-				wg.Done()
-			}()
-		default:
-			c.logger.Warn().Str("collector_id", collectorID).Msg("ignoring unknown collector")
-		}
-	}
-
 	wg.Wait()
 
 	c.logger.Info().Msg("collections complete, adding internal tracking metrics")
@@ -329,13 +476,13 @@ func (c *Cluster) collect(ctx context.Context, dynamicCollectors *dc.DC) {
 	deadlineTimeout := false
 	select {
 	case <-collectCtx.Done():
-		c.logger.Warn().Err(collectCtx.Err()).Str("deadline", c.collectDeadline.String()).Str("interval", c.interval.String()).Uint("pool", c.cfg.NodePoolSize).Msg("deadline triggered cancellation of metric collection: increase interval, node pool, or resources")
+		c.logger.Warn().Err(collectCtx.Err()).Str("deadline", collectDeadline.String()).Str("interval", interval.String()).Uint("pool", cfg.NodePoolSize).Msg("deadline triggered cancellation of metric collection: increase interval, node pool, or resources")
 		deadlineTimeout = true
 	default:
 	}
 
-	{ // get api/cluster version/platform
-		verplat, err := k8s.GetVersionPlatform(ctx, &c.cfg)
+	if cfg.Mode != config.ModeNode { // get api/cluster version/platform (in node mode, reported by the cluster mode instance)
+		verplat, err := k8s.GetVersionPlatform(ctx, c.clientset)
 		if err != nil {
 			c.logger.Warn().Err(err).Msg("getting api/cluster version + platform information")
 		} else {
@@ -348,15 +495,22 @@ func (c *Cluster) collect(ctx context.Context, dynamicCollectors *dc.DC) {
 	dur := time.Since(start)
 
 	baseStreamTags := cgm.Tags{
-		cgm.Tag{Category: "cluster", Value: c.cfg.Name},
+		cgm.Tag{Category: "cluster", Value: cfg.Name},
 		cgm.Tag{Category: "source", Value: release.NAME},
 	}
+	if cfg.Mode == config.ModeNode {
+		// one instance per node submitting to the same check
+		baseStreamTags = append(baseStreamTags, cgm.Tag{Category: "node", Value: cfg.NodeName})
+	}
 	c.check.AddText("collect_agent", baseStreamTags, release.NAME+"_"+release.VERSION)
 	c.check.AddGauge("collect_metrics", baseStreamTags, cstats.SentMetrics)
 	c.check.AddGauge("collect_filtered", baseStreamTags, cstats.LocFiltered)
 	c.check.AddGauge("collect_bytes_sent", baseStreamTags, cstats.SentBytes)
 	c.check.AddGauge("collect_bytes_transmitted", baseStreamTags, cstats.SentSize)
 	c.check.AddGauge("collect_ngr", baseStreamTags, uint64(runtime.NumGoroutine()))
+	if c.election != nil {
+		c.check.AddGauge("collect_leader", c.leaderTags(), 1)
+	}
 
 	cdt := 0
 	if deadlineTimeout {
@@ -400,7 +554,7 @@ func (c *Cluster) collect(ctx context.Context, dynamicCollectors *dc.DC) {
 		streamTags = append(streamTags, baseStreamTags...)
 		streamTags = append(streamTags, cgm.Tag{Category: "units", Value: "milliseconds"})
 		c.check.AddGauge("collect_duration", streamTags, uint64(dur.Milliseconds()))
-		c.check.AddGauge("collect_interval", streamTags, uint64(c.interval.Milliseconds()))
+		c.check.AddGauge("collect_interval", streamTags, uint64(interval.Milliseconds()))
 	}
 
 	fs := time.Now()
@@ -414,6 +568,11 @@ func (c *Cluster) collect(ctx context.Context, dynamicCollectors *dc.DC) {
 		Str("dur", dur.String()).
 		Msg("collection complete")
 	c.Lock()
-	c.running = false
+	if deadlineTimeout {
+		c.deadlineTimeouts++
+	} else {
+		c.deadlineTimeouts = 0
+		c.lastCollect = time.Now()
+	}
 	c.Unlock()
 }
@@ -5,8 +5,506 @@
 
 package cluster
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/k8s"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/testsupport"
+	"github.com/rs/zerolog"
+)
 
 func Test(t *testing.T) {
 	t.Log("Placeholder...nothing to test currently")
 }
+
+func TestLeaderElection(t *testing.T) {
+	t.Log("Testing leader election")
+
+	kube := testsupport.NewKubeAPI("v1.24.3")
+	defer kube.Close()
+	defer k8s.SetClientFactory(kube.NewClient)()
+
+	api, err := testsupport.NewAPI()
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	defer api.Close()
+
+	cfg := *kube.ClusterConfig("test-cluster")
+	cfg.BearerToken = "test-bearer-token"
+	cfg.Interval = "1s"
+	cfg.LeaderElection = config.LeaderElection{
+		Enabled:       true,
+		Namespace:     "default",
+		LeaseName:     "test-lease",
+		LeaseDuration: "1s",
+		RenewDeadline: "500ms",
+		RetryPeriod:   "100ms",
+	}
+	circCfg := *api.CirconusConfig("test-cluster")
+	circCfg.CollectDeadline = "1s"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	replica := func(identity string) *Cluster {
+		t.Helper()
+		c, err := New(ctx, cfg, circCfg, zerolog.Nop())
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		// replicas in the test share a hostname
+		c.election.lock.LockConfig.Identity = identity
+		c.election.status.Identity = identity
+		return c
+	}
+
+	waitFor := func(cond func() bool) bool {
+		for i := 0; i < 100; i++ {
+			if cond() {
+				return true
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		return false
+	}
+
+	a := replica("replica-a")
+	b := replica("replica-b")
+
+	ctxA, cancelA := context.WithCancel(ctx)
+	doneA := make(chan error, 1)
+	go func() { doneA <- a.Start(ctxA) }()
+
+	t.Log("first replica acquires leadership")
+	{
+		if !waitFor(a.election.isLeader) {
+			t.Fatal("expected replica-a to be leader")
+		}
+		lease := kube.Lease("default", "test-lease")
+		if lease == nil || lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != "replica-a" {
+			t.Fatalf("expected lease held by replica-a, got %#v", lease)
+		}
+	}
+
+	doneB := make(chan error, 1)
+	go func() { doneB <- b.Start(ctx) }()
+
+	t.Log("second replica is a standby")
+	{
+		time.Sleep(2 * time.Second)
+		if b.election.isLeader() {
+			t.Fatal("expected replica-b to be standby")
+		}
+		if st := b.election.stats().(leaderStatus); st.CurrentLeader != "replica-a" {
+			t.Fatalf("expected replica-b to see replica-a as leader, got %q", st.CurrentLeader)
+		}
+	}
+
+	t.Log("standby takes over when the leader stops")
+	{
+		cancelA()
+		if err := <-doneA; err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if !waitFor(b.election.isLeader) {
+			t.Fatal("expected replica-b to take over leadership")
+		}
+		if a.election.isLeader() {
+			t.Fatal("expected replica-a to not be leader")
+		}
+	}
+
+	t.Log("collect_leader gauge")
+	{
+		// one per replica (tagged with the replica identity)
+		count := 0
+		if !waitFor(func() bool {
+			count = 0
+			for name := range api.Broker.Metrics() {
+				if strings.HasPrefix(name, "collect_leader|") {
+					count++
+				}
+			}
+			return count == 2
+		}) {
+			t.Fatalf("expected collect_leader for both replicas, got %d", count)
+		}
+	}
+
+	t.Log("leader status published")
+	{
+		if v := leaderStats.Get("test-cluster"); v == nil || !strings.Contains(v.String(), `"identity":"replica-b"`) {
+			t.Fatalf("expected leader_election stats for test-cluster, got %v", v)
+		}
+	}
+
+	cancel()
+	if err := <-doneB; err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+}
+
+// syncBuffer is a log writer safe for concurrent use
+type syncBuffer struct {
+	buf bytes.Buffer
+	sync.Mutex
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) count(msg string) int {
+	b.Lock()
+	defer b.Unlock()
+	return strings.Count(b.buf.String(), `"message":"`+msg+`"`)
+}
+
+func TestCollectInProgress(t *testing.T) {
+	t.Log("Testing a collection longer than the interval")
+
+	kube := testsupport.NewKubeAPI("v1.24.3")
+	defer kube.Close()
+	defer k8s.SetClientFactory(kube.NewClient)()
+
+	// the api server collector blocks longer than the interval
+	kube.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2500 * time.Millisecond):
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write([]byte("# TYPE up gauge\nup 1\n"))
+	})
+
+	api, err := testsupport.NewAPI()
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	defer api.Close()
+
+	cfg := *kube.ClusterConfig("test-cluster")
+	cfg.BearerToken = "test-bearer-token"
+	cfg.Interval = "1s"
+	cfg.EnableAPIServer = true
+	circCfg := *api.CirconusConfig("test-cluster")
+	circCfg.CollectDeadline = "5s"
+
+	logs := &syncBuffer{}
+	c, err := New(context.Background(), cfg, circCfg, zerolog.New(logs))
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	// the first collection runs before the ticker starts (~2.5s), the
+	// second starts on the first tick (~3.5s) and the next two ticks
+	// arrive while it is still in progress
+	ctx, cancel := context.WithTimeout(context.Background(), 5800*time.Millisecond)
+	defer cancel()
+	if err := c.run(ctx); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	// wait for a collection started by the last tick to finish
+	for i := 0; i < 100; i++ {
+		c.Lock()
+		collecting := c.collecting
+		c.Unlock()
+		if !collecting {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	starts := logs.count("collection start")
+	flushes := logs.count("starting tracking metric flush")
+	if starts != 2 {
+		t.Fatalf("expected 2 collections, got %d", starts)
+	}
+	if flushes != starts {
+		t.Fatalf("expected one flush per collection (%d), got %d", starts, flushes)
+	}
+	if n := logs.count("collection in progress, not starting another"); n == 0 {
+		t.Fatal("expected ticks to be skipped while collecting")
+	}
+}
+
+func TestReload(t *testing.T) {
+	t.Log("Testing configuration reload")
+
+	kube := testsupport.NewKubeAPI("v1.24.3")
+	defer kube.Close()
+	defer k8s.SetClientFactory(kube.NewClient)()
+
+	api, err := testsupport.NewAPI()
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	defer api.Close()
+
+	cfg := *kube.ClusterConfig("test-cluster")
+	cfg.BearerToken = "test-bearer-token"
+	cfg.Interval = "1m"
+	circCfg := *api.CirconusConfig("test-cluster")
+	circCfg.CollectDeadline = "50s"
+
+	c, err := New(context.Background(), cfg, circCfg, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	t.Log("invalid interval")
+	{
+		newCfg := cfg
+		newCfg.Interval = "invalid"
+		if err := c.Reload(newCfg, circCfg); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+
+	t.Log("different cluster")
+	{
+		newCfg := cfg
+		newCfg.Name = "other-cluster"
+		if err := c.Reload(newCfg, circCfg); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+
+	t.Log("restart required")
+	{
+		newCfg := cfg
+		newCfg.Interval = "2m"
+		newCircCfg := circCfg
+		newCircCfg.CollectDeadline = "100s"
+		if restart := c.RestartRequired(newCfg, newCircCfg); len(restart) != 0 {
+			t.Fatalf("expected no restart, got %v", restart)
+		}
+		newCfg.Exec = config.Exec{Command: "auth-plugin"}
+		newCircCfg.API.Key = "other-key"
+		restart := c.RestartRequired(newCfg, newCircCfg)
+		if len(restart) != 2 || restart[0] != "exec" || restart[1] != "circonus" {
+			t.Fatalf("expected exec and circonus, got %v", restart)
+		}
+	}
+
+	t.Log("bearer token changed")
+	{
+		newCfg := cfg
+		newCfg.BearerToken = "other-bearer-token"
+		restart := c.RestartRequired(newCfg, circCfg)
+		if len(restart) != 1 || restart[0] != "bearer_token" {
+			t.Fatalf("expected bearer_token, got %v", restart)
+		}
+	}
+
+	t.Log("bearer token file changed")
+	{
+		newCfg := cfg
+		newCfg.BearerTokenFile = "/var/run/secrets/token"
+		restart := c.RestartRequired(newCfg, circCfg)
+		if len(restart) != 1 || restart[0] != "bearer_token_file" {
+			t.Fatalf("expected bearer_token_file, got %v", restart)
+		}
+	}
+
+	t.Log("valid")
+	{
+		newCfg := cfg
+		newCfg.Interval = "2m"
+		newCfg.EnableNodes = false
+		newCfg.URL = "https://changed.example.com"
+		newCfg.NodePoolSize = cfg.NodePoolSize + 5
+		newCircCfg := circCfg
+		newCircCfg.CollectDeadline = "100s"
+		if err := c.Reload(newCfg, newCircCfg); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if c.pending == nil {
+			t.Fatal("expected pending config")
+		}
+		if c.interval != 60*time.Second {
+			t.Fatalf("expected interval unchanged until applied, got %s", c.interval)
+		}
+
+		prev := c.cfg
+		c.applyReload(c.pending)
+
+		if c.cfg == prev || prev.NodePoolSize != cfg.NodePoolSize || prev.EnableNodes != cfg.EnableNodes {
+			t.Fatal("expected configuration to be replaced, not modified")
+		}
+		if c.cfg.NodePoolSize != newCfg.NodePoolSize {
+			t.Fatalf("expected node pool size %d, got %d", newCfg.NodePoolSize, c.cfg.NodePoolSize)
+		}
+		if c.interval != 2*time.Minute {
+			t.Fatalf("expected interval 2m, got %s", c.interval)
+		}
+		if c.collectDeadline != 100*time.Second {
+			t.Fatalf("expected deadline 100s, got %s", c.collectDeadline)
+		}
+		for _, id := range c.collectors {
+			if id == "node" {
+				t.Fatalf("expected nodes collector disabled, got %v", c.collectors)
+			}
+		}
+		if c.cfg.URL != cfg.URL {
+			t.Fatalf("expected api url unchanged (%s), got %s", cfg.URL, c.cfg.URL)
+		}
+		if c.cfg.BearerToken != cfg.BearerToken {
+			t.Fatal("expected bearer token unchanged")
+		}
+	}
+}
+
+func TestProbes(t *testing.T) {
+	t.Log("Testing readiness and liveness probes")
+
+	kube := testsupport.NewKubeAPI("v1.24.3")
+	defer kube.Close()
+	defer k8s.SetClientFactory(kube.NewClient)()
+
+	api, err := testsupport.NewAPI()
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	defer api.Close()
+
+	cfg := *kube.ClusterConfig("test-cluster")
+	cfg.BearerToken = "test-bearer-token"
+	cfg.Interval = "1m"
+	circCfg := *api.CirconusConfig("test-cluster")
+	circCfg.CollectDeadline = "50s"
+
+	c, err := New(context.Background(), cfg, circCfg, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	thresholds := ProbeThresholds{MaxSubmitAge: time.Hour, MaxDeadlineTimeouts: 3}
+
+	t.Log("not started")
+	{
+		if s := c.Ready(); s.OK {
+			t.Fatalf("expected not ready, got %+v", s)
+		}
+		if s := c.Live(thresholds); !s.OK {
+			t.Fatalf("expected live, got %+v", s)
+		}
+	}
+
+	t.Log("started, no collection")
+	{
+		c.started = time.Now()
+		s := c.Ready()
+		if s.OK {
+			t.Fatalf("expected not ready, got %+v", s)
+		}
+		if len(s.Failures) != 1 || s.Failures[0] != "no completed collection" {
+			t.Fatalf("expected no completed collection failure, got %v", s.Failures)
+		}
+		if s := c.Live(thresholds); !s.OK {
+			t.Fatalf("expected live, got %+v", s)
+		}
+	}
+
+	t.Log("collected")
+	{
+		c.lastCollect = time.Now()
+		if s := c.Ready(); !s.OK {
+			t.Fatalf("expected ready, got %+v", s)
+		}
+	}
+
+	t.Log("no collection within max collect age")
+	{
+		c.started = time.Now().Add(-2 * time.Hour)
+		c.lastCollect = time.Now().Add(-5 * time.Minute)
+		s := c.Live(thresholds)
+		if s.OK {
+			t.Fatalf("expected not live, got %+v", s)
+		}
+		if len(s.Failures) != 2 {
+			t.Fatalf("expected collect and submit age failures, got %v", s.Failures)
+		}
+		if !strings.HasPrefix(s.Failures[0], "no successful collection") {
+			t.Fatalf("expected collect age failure, got %v", s.Failures)
+		}
+		if !strings.HasPrefix(s.Failures[1], "no successful submission") {
+			t.Fatalf("expected submit age failure, got %v", s.Failures)
+		}
+	}
+
+	t.Log("consecutive deadline timeouts")
+	{
+		c.started = time.Now()
+		c.lastCollect = time.Now()
+		c.deadlineTimeouts = 3
+		s := c.Live(thresholds)
+		if s.OK {
+			t.Fatalf("expected not live, got %+v", s)
+		}
+		if len(s.Failures) != 1 || !strings.Contains(s.Failures[0], "deadline timeouts") {
+			t.Fatalf("expected deadline timeout failure, got %v", s.Failures)
+		}
+		c.deadlineTimeouts = 0
+		if s := c.Live(thresholds); !s.OK {
+			t.Fatalf("expected live, got %+v", s)
+		}
+	}
+}
+
+func TestParseSchedules(t *testing.T) {
+	t.Log("Testing parseSchedules")
+
+	t.Log("empty")
+	{
+		s, err := parseSchedules("", time.Minute, 50*time.Second)
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if len(s) != 0 {
+			t.Fatalf("expected no schedules, got %v", s)
+		}
+	}
+
+	t.Log("valid")
+	{
+		s, err := parseSchedules("api=5m, node=1m/45s,health=30s,dynamic=2m", time.Minute, 50*time.Second)
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		expect := map[string]collectorSchedule{
+			"api":     {interval: 5 * time.Minute, deadline: 5*time.Minute - 10*time.Second},
+			"node":    {interval: time.Minute, deadline: 45 * time.Second},
+			"health":  {interval: 30 * time.Second, deadline: 20 * time.Second},
+			"dynamic": {interval: 2 * time.Minute, deadline: 2*time.Minute - 10*time.Second},
+		}
+		if len(s) != len(expect) {
+			t.Fatalf("expected %v, got %v", expect, s)
+		}
+		for id, sched := range expect {
+			if s[id] != sched {
+				t.Fatalf("expected %s %+v, got %+v", id, sched, s[id])
+			}
+		}
+	}
+
+	t.Log("invalid")
+	{
+		for _, spec := range []string{"api", "events=30s", "api=abc", "api=5m/abc", "api=0s", "api=5m/-1s"} {
+			if _, err := parseSchedules(spec, time.Minute, 50*time.Second); err == nil {
+				t.Fatalf("expected error for %q", spec)
+			}
+		}
+	}
+}
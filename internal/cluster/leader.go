@@ -0,0 +1,238 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package cluster
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	cgm "github.com/circonus-labs/circonus-gometrics/v3"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config/defaults"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/release"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// leaderStats publishes the leader election status of each cluster at /stats
+var leaderStats = expvar.NewMap("leader_election")
+
+// election is the lease based leader election state for a cluster
+type election struct {
+	lock          *resourcelock.LeaseLock
+	cluster       string
+	status        leaderStatus
+	leaseDuration time.Duration
+	renewDeadline time.Duration
+	retryPeriod   time.Duration
+	sync.RWMutex
+}
+
+// leaderStatus is the leader election state published at /stats
+type leaderStatus struct {
+	Since         *time.Time `json:"since,omitempty"` // when this instance became the leader
+	Identity      string     `json:"identity"`
+	Lease         string     `json:"lease"`
+	CurrentLeader string     `json:"current_leader"`
+	Transitions   uint64     `json:"transitions"`
+	Leader        bool       `json:"leader"`
+}
+
+// newElection configures leader election using a Lease in the agent's namespace,
+// replicas are identified by hostname (pod name)
+func newElection(clusterName string, cfg config.LeaderElection, clientset kubernetes.Interface) (*election, error) {
+	identity, err := os.Hostname()
+	if err != nil {
+		return nil, errors.Wrap(err, "leader election identity")
+	}
+
+	namespace := cfg.Namespace
+	if namespace == "" {
+		ns, err := os.ReadFile(serviceAccountNamespaceFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "leader election namespace (not set and unable to read service account namespace)")
+		}
+		namespace = strings.TrimSpace(string(ns))
+	}
+
+	leaseName := cfg.LeaseName
+	if leaseName == "" {
+		leaseName = defaults.K8SLeaderElectionLeaseName
+	}
+
+	e := &election{
+		lock: &resourcelock.LeaseLock{
+			LeaseMeta:  metav1.ObjectMeta{Namespace: namespace, Name: leaseName},
+			Client:     clientset.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+		},
+		cluster: clusterName,
+		status: leaderStatus{
+			Identity: identity,
+			Lease:    namespace + "/" + leaseName,
+		},
+	}
+
+	for _, d := range []struct {
+		dest *time.Duration
+		name string
+		val  string
+		def  string
+	}{
+		{&e.leaseDuration, "lease duration", cfg.LeaseDuration, defaults.K8SLeaderElectionLeaseDuration},
+		{&e.renewDeadline, "renew deadline", cfg.RenewDeadline, defaults.K8SLeaderElectionRenewDeadline},
+		{&e.retryPeriod, "retry period", cfg.RetryPeriod, defaults.K8SLeaderElectionRetryPeriod},
+	} {
+		v := d.val
+		if v == "" {
+			v = d.def
+		}
+		dur, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("parsing leader election %s %s: %w", d.name, v, err)
+		}
+		*d.dest = dur
+	}
+
+	return e, nil
+}
+
+// isLeader returns true if this instance currently holds the lease
+func (e *election) isLeader() bool {
+	e.RLock()
+	defer e.RUnlock()
+	return e.status.Leader
+}
+
+// setLeader records a change in this instance's leadership
+func (e *election) setLeader(leader bool) {
+	e.Lock()
+	defer e.Unlock()
+	if leader {
+		now := time.Now()
+		e.status.Since = &now
+		e.status.Transitions++
+	} else {
+		e.status.Since = nil
+	}
+	e.status.Leader = leader
+}
+
+// setCurrentLeader records the identity of the current lease holder
+func (e *election) setCurrentLeader(identity string) {
+	e.Lock()
+	e.status.CurrentLeader = identity
+	e.Unlock()
+}
+
+// stats returns a copy of the status for /stats
+func (e *election) stats() interface{} {
+	e.RLock()
+	defer e.RUnlock()
+	return e.status
+}
+
+// lead campaigns for the lease, running run with a context which is cancelled when
+// leadership is lost. After losing leadership it rejoins the election (as a standby)
+// until ctx is done. Standby replicas emit collect_leader=0 every collection interval.
+func (c *Cluster) lead(ctx context.Context, run func(context.Context) error) error {
+	go c.standby(ctx)
+
+	for {
+		electionCtx, electionCancel := context.WithCancel(ctx)
+		errCh := make(chan error, 1)
+
+		le, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+			Lock:            c.election.lock,
+			Name:            c.election.cluster,
+			LeaseDuration:   c.election.leaseDuration,
+			RenewDeadline:   c.election.renewDeadline,
+			RetryPeriod:     c.election.retryPeriod,
+			ReleaseOnCancel: true,
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnStartedLeading: func(leaderCtx context.Context) {
+					c.election.setLeader(true)
+					c.logger.Info().Str("lease", c.election.status.Lease).Msg("acquired leadership, starting collection")
+					if err := run(leaderCtx); err != nil {
+						errCh <- err
+						electionCancel() // release the lease for a standby replica
+					}
+				},
+				OnStoppedLeading: func() {
+					if c.election.isLeader() {
+						c.logger.Warn().Msg("lost leadership, stopping collection")
+					}
+					c.election.setLeader(false)
+				},
+				OnNewLeader: func(identity string) {
+					c.election.setCurrentLeader(identity)
+					c.logger.Info().Str("leader", identity).Msg("leader elected")
+				},
+			},
+		})
+		if err != nil {
+			electionCancel()
+			return errors.Wrap(err, "configuring leader election")
+		}
+
+		le.Run(electionCtx)
+		electionCancel()
+
+		select {
+		case err := <-errCh:
+			return err
+		default:
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// standby emits the collect_leader gauge while this instance is not the
+// leader (the leader emits it with the rest of the collection metrics)
+func (c *Cluster) standby(ctx context.Context) {
+	c.Lock()
+	interval := c.interval
+	c.Unlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if c.election.isLeader() {
+				continue
+			}
+			ts := time.Now()
+			c.check.AddGauge("collect_leader", c.leaderTags(), 0)
+			c.check.FlushCGM(ctx, &ts, c.logger, true)
+		}
+	}
+}
+
+// leaderTags are the stream tags of the collect_leader gauge, one per replica
+func (c *Cluster) leaderTags() cgm.Tags {
+	return cgm.Tags{
+		cgm.Tag{Category: "cluster", Value: c.election.cluster},
+		cgm.Tag{Category: "source", Value: release.NAME},
+		cgm.Tag{Category: "identity", Value: c.election.status.Identity},
+	}
+}
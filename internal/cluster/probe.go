@@ -0,0 +1,137 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package cluster
+
+import (
+	"fmt"
+	"time"
+)
+
+// ProbeThresholds are the liveness probe thresholds (see config.Probes)
+type ProbeThresholds struct {
+	MaxCollectAge       time.Duration // zero uses 3 collection intervals
+	MaxSubmitAge        time.Duration // zero disables
+	MaxDeadlineTimeouts uint          // zero disables
+}
+
+// ProbeStatus is the state of a cluster reported by the readiness and liveness probes
+type ProbeStatus struct {
+	Started          *time.Time `json:"started,omitempty"`
+	LastCollection   *time.Time `json:"last_collection,omitempty"`
+	LastSubmit       *time.Time `json:"last_submit,omitempty"`
+	Failures         []string   `json:"failures,omitempty"`
+	DeadlineTimeouts uint       `json:"deadline_timeouts"` // consecutive
+	CheckInitialized bool       `json:"check_initialized"`
+	EventsSynced     bool       `json:"events_synced"`
+	Standby          bool       `json:"standby"` // leader election, not the leader
+	OK               bool       `json:"ok"`
+
+	leaderSince time.Time
+	interval    time.Duration
+	dryRun      bool
+}
+
+// probeStatus returns the current state of the cluster
+func (c *Cluster) probeStatus() ProbeStatus {
+	c.Lock()
+	s := ProbeStatus{
+		DeadlineTimeouts: c.deadlineTimeouts,
+		EventsSynced:     c.events == nil || c.events.Synced(),
+		interval:         c.interval,
+	}
+	if !c.started.IsZero() {
+		started := c.started
+		s.Started = &started
+	}
+	if !c.lastCollect.IsZero() {
+		lastCollect := c.lastCollect
+		s.LastCollection = &lastCollect
+	}
+	c.Unlock()
+
+	s.CheckInitialized = c.check.Initialized()
+	s.dryRun = c.check.DryRun()
+	if lastSubmit := c.check.LastSubmit(); !lastSubmit.IsZero() {
+		s.LastSubmit = &lastSubmit
+	}
+
+	if c.election != nil {
+		c.election.RLock()
+		s.Standby = !c.election.status.Leader
+		if c.election.status.Since != nil {
+			s.leaderSince = *c.election.status.Since
+		}
+		c.election.RUnlock()
+	}
+
+	return s
+}
+
+// Ready reports whether the cluster is ready: the check is initialized, the event
+// watcher has synced, and (unless a standby replica) a collection has completed.
+func (c *Cluster) Ready() ProbeStatus {
+	s := c.probeStatus()
+
+	if s.Started == nil {
+		s.Failures = append(s.Failures, "not started")
+	}
+	if !s.CheckInitialized {
+		s.Failures = append(s.Failures, "check not initialized")
+	}
+	if !s.EventsSynced {
+		s.Failures = append(s.Failures, "event watcher not synced")
+	}
+	if !s.Standby && s.LastCollection == nil && s.DeadlineTimeouts == 0 {
+		s.Failures = append(s.Failures, "no completed collection")
+	}
+
+	s.OK = len(s.Failures) == 0
+	return s
+}
+
+// Live reports whether the cluster is collecting and submitting within the thresholds,
+// a standby replica is always live. Ages are measured from the most recent of the
+// last success, the cluster start and acquiring leadership.
+func (c *Cluster) Live(t ProbeThresholds) ProbeStatus {
+	s := c.probeStatus()
+
+	if s.Started == nil || s.Standby {
+		s.OK = true
+		return s
+	}
+
+	since := func(last *time.Time) time.Duration {
+		ref := *s.Started
+		if s.leaderSince.After(ref) {
+			ref = s.leaderSince
+		}
+		if last != nil && last.After(ref) {
+			ref = *last
+		}
+		return time.Since(ref)
+	}
+
+	maxCollectAge := t.MaxCollectAge
+	if maxCollectAge == 0 {
+		maxCollectAge = 3 * s.interval
+	}
+	if age := since(s.LastCollection); age > maxCollectAge {
+		s.Failures = append(s.Failures, fmt.Sprintf("no successful collection in %s (max %s)", age.Round(time.Second), maxCollectAge))
+	}
+
+	if t.MaxDeadlineTimeouts > 0 && s.DeadlineTimeouts >= t.MaxDeadlineTimeouts {
+		s.Failures = append(s.Failures, fmt.Sprintf("%d consecutive collection deadline timeouts (max %d)", s.DeadlineTimeouts, t.MaxDeadlineTimeouts))
+	}
+
+	if t.MaxSubmitAge > 0 && !s.dryRun {
+		if age := since(s.LastSubmit); age > t.MaxSubmitAge {
+			s.Failures = append(s.Failures, fmt.Sprintf("no successful submission in %s (max %s)", age.Round(time.Second), t.MaxSubmitAge))
+		}
+	}
+
+	s.OK = len(s.Failures) == 0
+	return s
+}
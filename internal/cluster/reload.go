@@ -0,0 +1,209 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package cluster
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/dc"
+	"github.com/pkg/errors"
+)
+
+// pendingConfig is a validated configuration waiting to be applied before the next collection
+type pendingConfig struct {
+	cfg             config.Cluster
+	circCfg         config.Circonus
+	collectors      []string
+	schedules       map[string]collectorSchedule
+	interval        time.Duration
+	collectDeadline time.Duration
+}
+
+// Reload validates a new configuration for the cluster, it is applied at the start
+// of the next collection so collectors, metric filters and dynamic collector
+// definitions change together between collections. Settings used to initialize
+// the cluster's clients (api, credentials, mode, leader election, etc.) require
+// a restart and are not changed.
+func (c *Cluster) Reload(cfg config.Cluster, circCfg config.Circonus) error {
+	c.Lock()
+	name, mode, nodeName := c.cfg.Name, c.cfg.Mode, c.cfg.NodeName
+	c.Unlock()
+
+	if cfg.Name != name {
+		return errors.Errorf("invalid cluster config (name %s != %s)", cfg.Name, name)
+	}
+
+	interval, err := time.ParseDuration(cfg.Interval)
+	if err != nil {
+		return fmt.Errorf("parsing collect interval %s: %w", cfg.Interval, err)
+	}
+
+	collectDeadline, err := time.ParseDuration(circCfg.CollectDeadline)
+	if err != nil {
+		return fmt.Errorf("parsing collect deadline %s: %w", circCfg.CollectDeadline, err)
+	}
+
+	// keep the settings which require a restart
+	cfg.Mode = mode
+	cfg.NodeName = nodeName
+
+	schedules, err := parseSchedules(cfg.CollectorSchedules, interval, collectDeadline)
+	if err != nil {
+		return err
+	}
+
+	collectors := enabledCollectors(&cfg)
+	if len(collectors) == 0 {
+		return errors.Errorf("no collectors enabled for cluster %s", cfg.Name)
+	}
+
+	c.Lock()
+	c.pending = &pendingConfig{
+		cfg:             cfg,
+		circCfg:         circCfg,
+		collectors:      collectors,
+		schedules:       schedules,
+		interval:        interval,
+		collectDeadline: collectDeadline,
+	}
+	c.Unlock()
+
+	c.logger.Info().Strs("collectors", collectors).Msg("configuration reload pending, applied at next collection")
+
+	return nil
+}
+
+// RestartRequired returns the changed settings of a new configuration which are only applied
+// by restarting the cluster: the settings used to initialize its clients and the circonus
+// settings used to initialize its check (other than metric filters and the collect deadline)
+func (c *Cluster) RestartRequired(cfg config.Cluster, circCfg config.Circonus) []string {
+	c.Lock()
+	current, currentCirc := c.cfg, c.circCfg
+	c.Unlock()
+
+	restart := restartSettings(*current, cfg)
+
+	// reloadable circonus settings
+	circCfg.CollectDeadline = currentCirc.CollectDeadline
+	circCfg.NodeCC = currentCirc.NodeCC
+	circCfg.Check.MetricFilters = currentCirc.Check.MetricFilters
+	if circCfg != currentCirc {
+		restart = append(restart, "circonus")
+	}
+
+	return restart
+}
+
+// restartSettings returns the names of the settings used to initialize the cluster's clients which differ
+func restartSettings(current, cfg config.Cluster) []string {
+	restart := []string{}
+	for _, s := range []struct {
+		name    string
+		changed bool
+	}{
+		{"api_url", cfg.URL != current.URL},
+		{"api_ca_file", cfg.CAFile != current.CAFile},
+		{"bearer_token", cfg.BearerToken != current.BearerToken},
+		{"bearer_token_file", cfg.BearerTokenFile != current.BearerTokenFile},
+		{"client_cert_file", cfg.ClientCertFile != current.ClientCertFile},
+		{"client_key_file", cfg.ClientKeyFile != current.ClientKeyFile},
+		{"exec", !reflect.DeepEqual(cfg.Exec, current.Exec)},
+		{"kubeconfig", cfg.Kubeconfig != current.Kubeconfig},
+		{"kube_context", cfg.KubeContext != current.KubeContext},
+		{"api_qps", cfg.APIQPS != current.APIQPS},
+		{"api_burst", cfg.APIBurst != current.APIBurst},
+		{"enable_events", cfg.EnableEvents != current.EnableEvents},
+		{"pod_cache_resync", cfg.PodCacheResync != current.PodCacheResync},
+		{"node_request_mode", cfg.NodeRequestMode != current.NodeRequestMode},
+		{"kubelet_port", cfg.KubeletPort != current.KubeletPort},
+		{"kubelet_ca_file", cfg.KubeletCAFile != current.KubeletCAFile},
+		{"kubelet_insecure", cfg.KubeletInsecure != current.KubeletInsecure},
+		{"leader_election", cfg.LeaderElection != current.LeaderElection},
+	} {
+		if s.changed {
+			restart = append(restart, s.name)
+		}
+	}
+	return restart
+}
+
+// applyReload swaps in a pending configuration, called at the start of a collection.
+// The new configuration and dynamic collectors are built first then swapped in, under
+// the cluster lock, together. Collectors already running keep their own copy of the
+// configuration they were started with.
+func (c *Cluster) applyReload(p *pendingConfig) {
+	c.Lock()
+	current := c.cfg
+	currentDynamic := c.dynamic
+	c.Unlock()
+
+	cfg := p.cfg
+
+	if restart := restartSettings(*current, cfg); len(restart) > 0 {
+		c.logger.Warn().Strs("settings", restart).Msg("changed settings require a restart, not applied")
+	}
+
+	cfg.URL = current.URL
+	cfg.CAFile = current.CAFile
+	cfg.BearerToken = current.BearerToken
+	cfg.BearerTokenFile = current.BearerTokenFile
+	cfg.ClientCertFile = current.ClientCertFile
+	cfg.ClientKeyFile = current.ClientKeyFile
+	cfg.Exec = current.Exec
+	cfg.Kubeconfig = current.Kubeconfig
+	cfg.KubeContext = current.KubeContext
+	cfg.Circonus = current.Circonus
+	cfg.APIQPS = current.APIQPS
+	cfg.APIBurst = current.APIBurst
+	cfg.EnableEvents = current.EnableEvents
+	cfg.PodCacheResync = current.PodCacheResync
+	cfg.NodeRequestMode = current.NodeRequestMode
+	cfg.KubeletPort = current.KubeletPort
+	cfg.KubeletCAFile = current.KubeletCAFile
+	cfg.KubeletInsecure = current.KubeletInsecure
+	cfg.LeaderElection = current.LeaderElection
+
+	// dynamic collector definitions, keep the current definitions if the new ones are invalid
+	dynamic := currentDynamic
+	switch {
+	case cfg.DynamicCollectorFile == "" || cfg.Mode == config.ModeNode:
+		dynamic = nil
+	default:
+		d, err := dc.New(&cfg, c.logger, c.check, c.clientset)
+		if err != nil {
+			c.logger.Warn().Err(err).Msg("reloading dynamic collectors, using current definitions")
+		} else {
+			dynamic = d
+		}
+	}
+
+	c.Lock()
+	c.cfg = &cfg
+	c.dynamic = dynamic
+	c.collectors = p.collectors
+	c.schedules = p.schedules
+	c.interval = p.interval
+	c.collectDeadline = p.collectDeadline
+	c.circCfg.CollectDeadline = p.circCfg.CollectDeadline
+	c.circCfg.NodeCC = p.circCfg.NodeCC
+	c.circCfg.Check.MetricFilters = p.circCfg.Check.MetricFilters
+	c.Unlock()
+
+	// metric filters and alerting
+	if err := c.check.Reload(p.circCfg.Check.MetricFilters); err != nil {
+		c.logger.Warn().Err(err).Msg("reloading metric filters, using current filters")
+	}
+
+	c.logger.Info().
+		Strs("collectors", p.collectors).
+		Bool("dynamic_collectors", dynamic != nil).
+		Str("interval", p.interval.String()).
+		Str("deadline", p.collectDeadline.String()).
+		Msg("configuration reloaded")
+}
@@ -0,0 +1,248 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	cgm "github.com/circonus-labs/circonus-gometrics/v3"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/as"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/dns"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/health"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/ksm"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/nodes"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/release"
+)
+
+// dynamicCollectorID is the collector id of the dynamic collectors
+const dynamicCollectorID = "dynamic"
+
+// collectorSchedule is the interval and deadline of a collector running on its own schedule
+type collectorSchedule struct {
+	interval time.Duration
+	deadline time.Duration
+}
+
+// parseSchedules parses the per-collector schedules, a comma separated list of
+// id=interval[/deadline]. When a deadline is not set, the collector's deadline
+// leaves the same margin for submission as the cluster's collect deadline
+// (interval - (collection interval - collect deadline)).
+func parseSchedules(spec string, interval, collectDeadline time.Duration) (map[string]collectorSchedule, error) {
+	schedules := make(map[string]collectorSchedule)
+	if strings.TrimSpace(spec) == "" {
+		return schedules, nil
+	}
+
+	margin := interval - collectDeadline
+	if margin < 0 {
+		margin = 0
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, val, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid collector schedule (%s), expected id=interval[/deadline]", entry)
+		}
+		id = strings.TrimSpace(id)
+		switch id {
+		case "node", "ksm", "api", "dns", "health", dynamicCollectorID:
+		default:
+			return nil, fmt.Errorf("invalid collector schedule (%s), unknown collector id %s", entry, id)
+		}
+
+		ival, dval, hasDeadline := strings.Cut(val, "/")
+		var sched collectorSchedule
+		d, err := time.ParseDuration(strings.TrimSpace(ival))
+		if err != nil {
+			return nil, fmt.Errorf("parsing collector %s interval: %w", id, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("invalid collector %s interval (%s)", id, ival)
+		}
+		sched.interval = d
+
+		if hasDeadline {
+			d, err := time.ParseDuration(strings.TrimSpace(dval))
+			if err != nil {
+				return nil, fmt.Errorf("parsing collector %s deadline: %w", id, err)
+			}
+			if d <= 0 {
+				return nil, fmt.Errorf("invalid collector %s deadline (%s)", id, dval)
+			}
+			sched.deadline = d
+		} else {
+			sched.deadline = sched.interval - margin
+			if sched.deadline <= 0 {
+				sched.deadline = sched.interval
+			}
+		}
+
+		schedules[id] = sched
+	}
+
+	return schedules, nil
+}
+
+// newCollector initializes a collector by id, each collector gets its own copy of the
+// current configuration so a configuration reload does not change it mid collection
+func (c *Cluster) newCollector(id string) (Collector, error) {
+	c.Lock()
+	cfg := *c.cfg
+	nodeCC := c.circCfg.NodeCC
+	d := c.dynamic
+	c.Unlock()
+
+	switch id {
+	case "node":
+		return nodes.New(&cfg, c.logger, c.check, c.clientset, c.pods, c.kubelet, nodeCC)
+	case "health":
+		return health.New(&cfg, c.logger, c.check, c.clientset)
+	case "ksm":
+		return ksm.New(&cfg, c.logger, c.check, c.clientset)
+	case "api":
+		return as.New(&cfg, c.logger, c.check, c.clientset)
+	case "dns":
+		return dns.New(&cfg, c.logger, c.check, c.clientset)
+	case dynamicCollectorID:
+		if d == nil {
+			return nil, errors.New("dynamic collectors not configured")
+		}
+		return d, nil
+	default:
+		return nil, fmt.Errorf("unknown collector")
+	}
+}
+
+// runCollector runs a collector, unless the collector is still running from a previous collection
+func (c *Cluster) runCollector(ctx context.Context, id string, start *time.Time) {
+	c.Lock()
+	if c.running[id] {
+		c.Unlock()
+		c.logger.Warn().Str("collector_id", id).Msg("collector in progress, not starting another")
+		return
+	}
+	c.running[id] = true
+	c.Unlock()
+
+	defer func() {
+		c.Lock()
+		delete(c.running, id)
+		c.Unlock()
+	}()
+
+	collector, err := c.newCollector(id)
+	if err != nil {
+		c.logger.Error().Err(err).Str("collector_id", id).Msg("initializing collector")
+		return
+	}
+
+	tm := time.Now()
+	c.logger.Info().Str("collector_id", id).Msg("starting collector")
+	collector.Collect(ctx, c.tlsConfig, start)
+	c.logger.Info().Str("collector_id", id).Str("dur", time.Since(tm).String()).Str("sdur", time.Since(*start).String()).Msg("finished collector")
+}
+
+// enabled returns true if the collector is enabled. Must be called with the cluster locked.
+func (c *Cluster) enabled(id string) bool {
+	if id == dynamicCollectorID {
+		return c.dynamic != nil
+	}
+	for _, cid := range c.collectors {
+		if cid == id {
+			return true
+		}
+	}
+	return false
+}
+
+// startSchedules starts the enabled collectors which have their own schedule and are not already scheduled
+func (c *Cluster) startSchedules(ctx context.Context) {
+	c.Lock()
+	defer c.Unlock()
+
+	for id := range c.schedules {
+		if c.scheduled[id] || !c.enabled(id) {
+			continue
+		}
+		c.scheduled[id] = true
+		go c.schedule(ctx, id)
+	}
+}
+
+// schedule runs a collector on its own interval and deadline until ctx is done, or the
+// collector is disabled or no longer has its own schedule (configuration reload)
+func (c *Cluster) schedule(ctx context.Context, id string) {
+	defer func() {
+		c.Lock()
+		delete(c.scheduled, id)
+		c.Unlock()
+	}()
+
+	c.Lock()
+	sched := c.schedules[id]
+	c.Unlock()
+
+	c.logger.Info().Str("collector_id", id).Str("interval", sched.interval.String()).Str("deadline", sched.deadline.String()).Msg("collector schedule started")
+
+	ticker := time.NewTicker(sched.interval)
+	defer ticker.Stop()
+
+	tags := cgm.Tags{
+		cgm.Tag{Category: "cluster", Value: c.clusterName()},
+		cgm.Tag{Category: "source", Value: release.NAME},
+		cgm.Tag{Category: "collector", Value: id},
+	}
+
+	for {
+		start := time.Now()
+		collectCtx, collectCancel := context.WithDeadline(ctx, start.Add(sched.deadline))
+		go func(deadline time.Duration) {
+			defer collectCancel()
+			c.runCollector(collectCtx, id, &start)
+			cdt := 0
+			if errors.Is(collectCtx.Err(), context.DeadlineExceeded) {
+				c.logger.Warn().Str("collector_id", id).Str("deadline", deadline.String()).Msg("deadline triggered cancellation of collector: increase collector interval/deadline, or resources")
+				cdt = 1
+			}
+			c.check.AddGauge("collect_deadline_timeout", tags, cdt)
+		}(sched.deadline)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		c.Lock()
+		next, ok := c.schedules[id]
+		enabled := c.enabled(id)
+		c.Unlock()
+		if !ok || !enabled {
+			c.logger.Info().Str("collector_id", id).Msg("collector schedule stopped")
+			return
+		}
+		if next != sched {
+			sched = next
+			ticker.Reset(sched.interval)
+			c.logger.Info().Str("collector_id", id).Str("interval", sched.interval.String()).Str("deadline", sched.deadline.String()).Msg("collector schedule changed")
+		}
+	}
+}
+
+// clusterName returns the name of the cluster
+func (c *Cluster) clusterName() string {
+	c.Lock()
+	defer c.Unlock()
+	return c.cfg.Name
+}
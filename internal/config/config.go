@@ -11,9 +11,12 @@ import (
 	"expvar"
 	"fmt"
 	"io"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config/keys"
+	"github.com/mitchellh/mapstructure"
 	toml "github.com/pelletier/go-toml"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -28,8 +31,24 @@ type Config struct {
 	Circonus   Circonus  `json:"circonus" toml:"circonus" yaml:"circonus"`
 	Kubernetes Cluster   `json:"kubernetes" toml:"kubernetes" yaml:"kubernetes"`
 	Debug      bool      `json:"debug" toml:"debug" yaml:"debug"`
+
+	ConfigWatchInterval string `mapstructure:"config_watch_interval" json:"config_watch_interval" toml:"config_watch_interval" yaml:"config_watch_interval"`
+
+	Probes Probes `json:"probes" toml:"probes" yaml:"probes"`
+	Server Server `json:"server" toml:"server" yaml:"server"`
 }
 
+// Collection modes
+const (
+	// ModeAll collects cluster-scope and node metrics from a single instance (Deployment)
+	ModeAll = "all"
+	// ModeCluster collects only cluster-scope metrics (ksm, api-server, dns, health, events, dynamic collectors),
+	// used with a ModeNode DaemonSet collecting the node metrics
+	ModeCluster = "cluster"
+	// ModeNode collects only the metrics of the node the instance is running on (DaemonSet), identified by NODE_NAME
+	ModeNode = "node"
+)
+
 // Cluster defines the kubernetes cluster configuration options
 type Cluster struct {
 	PodLabelKey           string `mapstructure:"pod_label_key" json:"pod_label_key" toml:"pod_label" yaml:"pod_label_key"`
@@ -46,13 +65,22 @@ type Cluster struct {
 	PodLabelVal           string `mapstructure:"pod_label_val" json:"pod_label_val" toml:"pod_label" yaml:"pod_label_val"`
 	BearerToken           string `mapstructure:"bearer_token" json:"bearer_token" toml:"bearer_token" yaml:"bearer_token"`
 	DynamicCollectorFile  string `mapstructure:"dynamic_collector_file" json:"dynamic_collector_file" yaml:"dynamic_collector_file"`
+	PodCacheResync        string `mapstructure:"pod_cache_resync" json:"pod_cache_resync" toml:"pod_cache_resync" yaml:"pod_cache_resync"`
+	NodeRequestMode       string `mapstructure:"node_request_mode" json:"node_request_mode" toml:"node_request_mode" yaml:"node_request_mode"`
+	KubeletCAFile         string `mapstructure:"kubelet_ca_file" json:"kubelet_ca_file" toml:"kubelet_ca_file" yaml:"kubelet_ca_file"`
+	Mode                  string `mapstructure:"mode" json:"mode" toml:"mode" yaml:"mode"`
+	NodeName              string `mapstructure:"node_name" json:"node_name" toml:"node_name" yaml:"node_name"`
+	CollectorSchedules    string `mapstructure:"collector_schedules" json:"collector_schedules" toml:"collector_schedules" yaml:"collector_schedules"`
 	// DEPRECATED
 	KSMRequestMode string `mapstructure:"ksm_request_mode" json:"ksm_request_mode" toml:"ksm_request_mode" yaml:"ksm_request_mode"`
 	// DEPRECATED
 	KSMTelemetryPortName      string `mapstructure:"ksm_telemetry_port_name" json:"ksm_telemetry_port_name" toml:"ksm_telemetry_port_name" yaml:"ksm_telemetry_port_name"`
 	NodeKubletVersion         string `mapstructure:"node_kublet_version" json:"node_kublet_version" toml:"node_kublet_version" yaml:"node_kublet_version"`
 	DNSMetricsPort            int    `mapstructure:"dns_metrics_port" json:"dns_metrics_port" toml:"dns_metrics_port" yaml:"dns_metrics_port"`
+	KubeletPort               int    `mapstructure:"kubelet_port" json:"kubelet_port" toml:"kubelet_port" yaml:"kubelet_port"`
 	NodePoolSize              uint   `mapstructure:"node_pool_size" json:"node_pool_size" toml:"node_pool_size" yaml:"node_pool_size"`
+	APIQPS                    uint   `mapstructure:"api_qps" json:"api_qps" toml:"api_qps" yaml:"api_qps"`
+	APIBurst                  uint   `mapstructure:"api_burst" json:"api_burst" toml:"api_burst" yaml:"api_burst"`
 	IncludePods               bool   `mapstructure:"include_pod_metrics" json:"include_pod_metrics" toml:"include_pod_metrics" yaml:"include_pod_metrics"`
 	EnableDNSMetrics          bool   `mapstructure:"enable_dns_metrics" json:"enable_dns_metrics" toml:"enable_dns_metrics" yaml:"enable_dns_metrics"`
 	EnableNodeResourceMetrics bool   `mapstructure:"enable_node_resource_metrics" json:"enable_node_resource_metrics" toml:"enable_node_resource_metrics" yaml:"enable_node_resource_metrics"`
@@ -65,6 +93,39 @@ type Cluster struct {
 	EnableKubeStateMetrics    bool   `mapstructure:"enable_kube_state_metrics" json:"enable_kube_state_metrics" toml:"enable_kube_state_metrics" yaml:"enable_kube_state_metrics"`
 	EnableEvents              bool   `mapstructure:"enable_events" json:"enable_events" toml:"enable_events" yaml:"enable_events"`
 	EnableCadvisorMetrics     bool   `mapstructure:"enable_cadvisor_metrics" json:"enable_cadvisor_metrics" toml:"enable_cadvisor_metrics" yaml:"enable_cadvisor_metrics"`
+	KubeletInsecure           bool   `mapstructure:"kubelet_insecure" json:"kubelet_insecure" toml:"kubelet_insecure" yaml:"kubelet_insecure"`
+
+	LeaderElection LeaderElection `mapstructure:"leader_election" json:"leader_election" toml:"leader_election" yaml:"leader_election"`
+
+	// client certificate and exec plugin credentials (alternatives to a bearer token)
+	ClientCertFile string `mapstructure:"client_cert_file" json:"client_cert_file" toml:"client_cert_file" yaml:"client_cert_file"`
+	ClientKeyFile  string `mapstructure:"client_key_file" json:"client_key_file" toml:"client_key_file" yaml:"client_key_file"`
+	Exec           Exec   `mapstructure:"exec" json:"exec" toml:"exec" yaml:"exec"`
+
+	// kubeconfig file and context (blank=current context), supersedes the api url, ca file and credentials
+	Kubeconfig  string `mapstructure:"kubeconfig" json:"kubeconfig" toml:"kubeconfig" yaml:"kubeconfig"`
+	KubeContext string `mapstructure:"kube_context" json:"kube_context" toml:"kube_context" yaml:"kube_context"`
+
+	// Circonus overrides settings of the circonus configuration for this cluster (e.g. api key, broker, filters)
+	Circonus map[string]interface{} `mapstructure:"circonus" json:"circonus,omitempty" toml:"circonus,omitempty" yaml:"circonus,omitempty"`
+}
+
+// Exec defines a client-go credential exec plugin (e.g. aws-iam-authenticator, gke-gcloud-auth-plugin)
+type Exec struct {
+	Command    string   `mapstructure:"command" json:"command" toml:"command" yaml:"command"`
+	APIVersion string   `mapstructure:"api_version" json:"api_version" toml:"api_version" yaml:"api_version"`
+	Args       []string `mapstructure:"args" json:"args" toml:"args" yaml:"args"`
+	Env        []string `mapstructure:"env" json:"env" toml:"env" yaml:"env"` // NAME=value
+}
+
+// LeaderElection defines the lease based leader election options, used to run multiple replicas of the agent
+type LeaderElection struct {
+	Namespace     string `json:"namespace" toml:"namespace" yaml:"namespace"`
+	LeaseName     string `mapstructure:"lease_name" json:"lease_name" toml:"lease_name" yaml:"lease_name"`
+	LeaseDuration string `mapstructure:"lease_duration" json:"lease_duration" toml:"lease_duration" yaml:"lease_duration"`
+	RenewDeadline string `mapstructure:"renew_deadline" json:"renew_deadline" toml:"renew_deadline" yaml:"renew_deadline"`
+	RetryPeriod   string `mapstructure:"retry_period" json:"retry_period" toml:"retry_period" yaml:"retry_period"`
+	Enabled       bool   `json:"enabled" toml:"enabled" yaml:"enabled"`
 }
 
 // LabelFilters defines labels to include and exclude
@@ -75,15 +136,25 @@ type LabelFilters struct {
 
 // Circonus defines the circonus specific configuration options
 type Circonus struct {
-	CustomRulesFile   string `mapstructure:"custom_rules_file" json:"custom_rules_file" toml:"custom_rules_file" yaml:"custom_rules_file"`
-	TraceSubmits      string `mapstructure:"trace_submits" json:"trace_submits" toml:"trace_submits" yaml:"trace_submits"`
-	DefaultStreamtags string `mapstructure:"default_streamtags" json:"default_streamtags" toml:"default_streamtags" yaml:"default_streamtags"`
-	MetricFiltersFile string `mapstructure:"metric_filters_file" json:"metric_filters_file" toml:"metric_filters_file" yaml:"metric_filters_file"`
-	DefaultAlertsFile string `mapstructure:"default_alerts_file" json:"default_alerts_file" toml:"default_alerts_file" yaml:"default_alerts_file"`
-	CollectDeadline   string `mapstructure:"collect_deadline" json:"collect_deadline" toml:"collect_deadline" yaml:"collect_deadline"`
-	SubmitDeadline    string `mapstructure:"submit_deadline" json:"submit_deadline" toml:"submit_deadline" yaml:"submit_deadline"`
-	Check             Check  `json:"check" toml:"check" yaml:"check"`
-	API               API    `json:"api" toml:"api" yaml:"api"`
+	CustomRulesFile   string      `mapstructure:"custom_rules_file" json:"custom_rules_file" toml:"custom_rules_file" yaml:"custom_rules_file"`
+	TraceSubmits      string      `mapstructure:"trace_submits" json:"trace_submits" toml:"trace_submits" yaml:"trace_submits"`
+	DefaultStreamtags string      `mapstructure:"default_streamtags" json:"default_streamtags" toml:"default_streamtags" yaml:"default_streamtags"`
+	MetricFiltersFile string      `mapstructure:"metric_filters_file" json:"metric_filters_file" toml:"metric_filters_file" yaml:"metric_filters_file"`
+	DefaultAlertsFile string      `mapstructure:"default_alerts_file" json:"default_alerts_file" toml:"default_alerts_file" yaml:"default_alerts_file"`
+	CollectDeadline   string      `mapstructure:"collect_deadline" json:"collect_deadline" toml:"collect_deadline" yaml:"collect_deadline"`
+	SubmitDeadline    string      `mapstructure:"submit_deadline" json:"submit_deadline" toml:"submit_deadline" yaml:"submit_deadline"`
+	SubmitMaxBytes    string      `mapstructure:"submit_max_bytes" json:"submit_max_bytes" toml:"submit_max_bytes" yaml:"submit_max_bytes"`
+	SubmitMaxMetrics  uint        `mapstructure:"submit_max_metrics" json:"submit_max_metrics" toml:"submit_max_metrics" yaml:"submit_max_metrics"`
+	SubmitParallelism uint        `mapstructure:"submit_parallelism" json:"submit_parallelism" toml:"submit_parallelism" yaml:"submit_parallelism"`
+	SubmitStreamMin   uint        `mapstructure:"submit_stream_min" json:"submit_stream_min" toml:"submit_stream_min" yaml:"submit_stream_min"`
+	Sinks             string      `mapstructure:"sinks" json:"sinks" toml:"sinks" yaml:"sinks"`
+	SinkFile          string      `mapstructure:"sink_file" json:"sink_file" toml:"sink_file" yaml:"sink_file"`
+	Check             Check       `json:"check" toml:"check" yaml:"check"`
+	API               API         `json:"api" toml:"api" yaml:"api"`
+	RemoteWrite       RemoteWrite `mapstructure:"remote_write" json:"remote_write" toml:"remote_write" yaml:"remote_write"`
+	OTLP              OTLP        `json:"otlp" toml:"otlp" yaml:"otlp"`
+	Spool             Spool       `json:"spool" toml:"spool" yaml:"spool"`
+	Broker            Broker      `json:"broker" toml:"broker" yaml:"broker"`
 	// hidden circonus settings for development and debugging
 	Base64Tags      bool `json:"-" toml:"-" yaml:"-"`
 	DryRun          bool `json:"-" toml:"-" yaml:"-"`
@@ -92,6 +163,58 @@ type Circonus struct {
 	NodeCC          bool `json:"-" toml:"-" yaml:"-"`
 }
 
+// ClusterCirconus returns the circonus configuration for a cluster, the (global) circonus
+// configuration with the cluster's circonus overrides applied
+func ClusterCirconus(base Circonus, cluster Cluster) (Circonus, error) {
+	if len(cluster.Circonus) == 0 {
+		return base, nil
+	}
+
+	overrides := make(map[string]interface{}, len(cluster.Circonus))
+	for k, v := range cluster.Circonus {
+		overrides[k] = v
+	}
+
+	// api.key_file is not part of the circonus configuration, read the key from the file
+	if api, ok := overrides["api"].(map[string]interface{}); ok {
+		if keyFile, ok := api["key_file"].(string); ok {
+			a := make(map[string]interface{}, len(api))
+			for k, v := range api {
+				a[k] = v
+			}
+			delete(a, "key_file")
+			if _, ok := a["key"]; !ok && keyFile != "" {
+				data, err := os.ReadFile(keyFile)
+				if err != nil {
+					return base, errors.Wrapf(err, "cluster %s circonus api key file", cluster.Name)
+				}
+				key := strings.TrimSpace(string(data))
+				if key == "" {
+					return base, errors.Errorf("cluster %s invalid api key file (%s), empty key", cluster.Name, keyFile)
+				}
+				a["key"] = key
+			}
+			overrides["api"] = a
+		}
+	}
+
+	cc := base
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToSliceHookFunc(","),
+		WeaklyTypedInput: true,
+		ErrorUnused:      true,
+		Result:           &cc,
+	})
+	if err != nil {
+		return base, errors.Wrap(err, "circonus overrides decoder")
+	}
+	if err := dec.Decode(overrides); err != nil {
+		return base, errors.Wrapf(err, "cluster %s circonus overrides", cluster.Name)
+	}
+
+	return cc, nil
+}
+
 // API defines the circonus api configuration options
 type API struct {
 	App    string `json:"app" toml:"app" yaml:"app"`
@@ -101,6 +224,35 @@ type API struct {
 	Debug  bool   `json:"debug" toml:"debug" yaml:"debug"`
 }
 
+// RemoteWrite defines the prometheus remote-write sink configuration options
+type RemoteWrite struct {
+	URL         string `json:"url" toml:"url" yaml:"url"`
+	BearerToken string `mapstructure:"bearer_token" json:"bearer_token" toml:"bearer_token" yaml:"bearer_token"`
+}
+
+// OTLP defines the OpenTelemetry OTLP/HTTP sink configuration options
+type OTLP struct {
+	URL      string `json:"url" toml:"url" yaml:"url"`
+	Encoding string `json:"encoding" toml:"encoding" yaml:"encoding"`
+	Headers  string `json:"headers" toml:"headers" yaml:"headers"`
+}
+
+// Broker defines the broker connection options
+type Broker struct {
+	MaxIdleConns    uint   `mapstructure:"max_idle_conns" json:"max_idle_conns" toml:"max_idle_conns" yaml:"max_idle_conns"`
+	IdleConnTimeout string `mapstructure:"idle_conn_timeout" json:"idle_conn_timeout" toml:"idle_conn_timeout" yaml:"idle_conn_timeout"`
+	DisableHTTP2    bool   `mapstructure:"disable_http2" json:"disable_http2" toml:"disable_http2" yaml:"disable_http2"`
+	RoundRobin      bool   `mapstructure:"round_robin" json:"round_robin" toml:"round_robin" yaml:"round_robin"`
+}
+
+// Spool defines the disk backed buffer for failed submissions
+type Spool struct {
+	Dir           string `json:"dir" toml:"dir" yaml:"dir"`
+	MaxSize       string `mapstructure:"max_size" json:"max_size" toml:"max_size" yaml:"max_size"`
+	MaxAge        string `mapstructure:"max_age" json:"max_age" toml:"max_age" yaml:"max_age"`
+	MaxReplayRate uint   `mapstructure:"max_replay_rate" json:"max_replay_rate" toml:"max_replay_rate" yaml:"max_replay_rate"`
+}
+
 // Check defines the circonus check configuration options
 type Check struct {
 	BrokerCID     string `mapstructure:"broker_cid" json:"broker_cid" toml:"broker_cid" yaml:"broker_cid"`
@@ -113,6 +265,24 @@ type Check struct {
 	Create        bool   `mapstructure:"create" json:"create" toml:"create" yaml:"create" `
 }
 
+// Probes defines the readiness (/readyz) and liveness (/livez) probe thresholds
+type Probes struct {
+	MaxCollectAge       string `mapstructure:"max_collect_age" json:"max_collect_age" toml:"max_collect_age" yaml:"max_collect_age"`
+	MaxSubmitAge        string `mapstructure:"max_submit_age" json:"max_submit_age" toml:"max_submit_age" yaml:"max_submit_age"`
+	MaxDeadlineTimeouts uint   `mapstructure:"max_deadline_timeouts" json:"max_deadline_timeouts" toml:"max_deadline_timeouts" yaml:"max_deadline_timeouts"`
+}
+
+// Server defines the internal http server (stats, metrics, probes and pprof) options
+type Server struct {
+	Listen          string `json:"listen" toml:"listen" yaml:"listen"`
+	TLSCertFile     string `mapstructure:"tls_cert_file" json:"tls_cert_file" toml:"tls_cert_file" yaml:"tls_cert_file"`
+	TLSKeyFile      string `mapstructure:"tls_key_file" json:"tls_key_file" toml:"tls_key_file" yaml:"tls_key_file"`
+	ClientCAFile    string `mapstructure:"client_ca_file" json:"client_ca_file" toml:"client_ca_file" yaml:"client_ca_file"`
+	BearerToken     string `mapstructure:"bearer_token" json:"bearer_token" toml:"bearer_token" yaml:"bearer_token"`
+	BearerTokenFile string `mapstructure:"bearer_token_file" json:"bearer_token_file" toml:"bearer_token_file" yaml:"bearer_token_file"`
+	EnablePprof     bool   `mapstructure:"enable_pprof" json:"enable_pprof" toml:"enable_pprof" yaml:"enable_pprof"`
+}
+
 // Log defines the logging configuration options
 type Log struct {
 	Level  string `json:"level" yaml:"level" toml:"level"`
@@ -135,6 +305,39 @@ func Validate() error {
 		return fmt.Errorf("use --check-create OR --check-bundle-cid, they are mutually exclusive")
 	}
 
+	switch mode := viper.GetString(keys.K8SMode); mode {
+	case ModeAll, ModeCluster:
+	case ModeNode:
+		if viper.GetString(keys.K8SNodeName) == "" {
+			return fmt.Errorf("node mode requires the node name (--k8s-node-name or NODE_NAME)")
+		}
+	default:
+		return fmt.Errorf("invalid mode (%s), must be one of %s, %s, or %s", mode, ModeAll, ModeCluster, ModeNode)
+	}
+
+	if cw := viper.GetString(keys.ConfigWatchInterval); cw != "" {
+		if _, err := time.ParseDuration(cw); err != nil {
+			return fmt.Errorf("parsing config watch interval: %w", err)
+		}
+	}
+
+	certFile := viper.GetString(keys.ServerTLSCertFile)
+	keyFile := viper.GetString(keys.ServerTLSKeyFile)
+	if (certFile == "") != (keyFile == "") {
+		return fmt.Errorf("server tls requires both a cert and key file")
+	}
+	if viper.GetString(keys.ServerClientCAFile) != "" && certFile == "" {
+		return fmt.Errorf("server client certificate auth requires tls (cert and key file)")
+	}
+
+	for _, key := range []string{keys.ProbesMaxCollectAge, keys.ProbesMaxSubmitAge} {
+		if v := viper.GetString(key); v != "" {
+			if _, err := time.ParseDuration(v); err != nil {
+				return fmt.Errorf("parsing %s: %w", key, err)
+			}
+		}
+	}
+
 	interval := viper.GetString(keys.K8SInterval)
 	collectDeadline := viper.GetString(keys.CollectDeadline)
 	submitDeadline := viper.GetString(keys.SubmitDeadline)
@@ -192,14 +395,24 @@ func StatConfig() error {
 	if cfg.Circonus.API.Key != "" {
 		cfg.Circonus.API.Key = "..."
 	}
+	if cfg.Circonus.RemoteWrite.BearerToken != "" {
+		cfg.Circonus.RemoteWrite.BearerToken = "..."
+	}
+	if cfg.Circonus.OTLP.Headers != "" {
+		cfg.Circonus.OTLP.Headers = "..."
+	}
 	if cfg.Kubernetes.BearerToken != "" {
 		cfg.Kubernetes.BearerToken = "..."
 	}
+	if cfg.Server.BearerToken != "" {
+		cfg.Server.BearerToken = "..."
+	}
 	if len(cfg.Clusters) > 0 {
 		for idx := range cfg.Clusters {
 			if cfg.Clusters[idx].BearerToken != "" {
 				cfg.Clusters[idx].BearerToken = "..."
 			}
+			cfg.Clusters[idx].Circonus = obfuscateOverrides(cfg.Clusters[idx].Circonus)
 		}
 	}
 
@@ -210,6 +423,36 @@ func StatConfig() error {
 	return nil
 }
 
+// obfuscateOverrides returns a copy of a cluster's circonus overrides with the secrets obfuscated
+func obfuscateOverrides(overrides map[string]interface{}) map[string]interface{} {
+	if len(overrides) == 0 {
+		return overrides
+	}
+
+	secrets := map[string]string{"api": "key", "remote_write": "bearer_token", "otlp": "headers"}
+
+	o := make(map[string]interface{}, len(overrides))
+	for k, v := range overrides {
+		o[k] = v
+		secret, ok := secrets[k]
+		if !ok {
+			continue
+		}
+		if m, ok := v.(map[string]interface{}); ok {
+			mc := make(map[string]interface{}, len(m))
+			for mk, mv := range m {
+				mc[mk] = mv
+			}
+			if _, ok := mc[secret]; ok {
+				mc[secret] = "..."
+			}
+			o[k] = mc
+		}
+	}
+
+	return o
+}
+
 // getConfig dumps the current configuration and returns it
 func getConfig() (*Config, error) {
 	var cfg Config
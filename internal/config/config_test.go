@@ -7,6 +7,8 @@ package config
 
 import (
 	"io"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config/keys"
@@ -82,3 +84,91 @@ func TestStatConfig(t *testing.T) {
 		t.Fatalf("expected no error, got %s", err)
 	}
 }
+
+func TestClusterCirconus(t *testing.T) {
+	t.Log("Testing ClusterCirconus")
+
+	base := Circonus{
+		API:               API{Key: "global", App: "app", URL: "https://api.example.com/"},
+		DefaultStreamtags: "env:prod",
+		MetricFiltersFile: "/etc/filters.json",
+		SubmitMaxMetrics:  1000,
+		DryRun:            true,
+	}
+
+	t.Log("no overrides")
+	{
+		cc, err := ClusterCirconus(base, Cluster{Name: "prod"})
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if cc != base {
+			t.Fatalf("expected base config, got %#v", cc)
+		}
+	}
+
+	t.Log("overrides")
+	{
+		keyFile := filepath.Join(t.TempDir(), "key")
+		if err := os.WriteFile(keyFile, []byte("staging\n"), 0600); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		cc, err := ClusterCirconus(base, Cluster{Name: "staging", Circonus: map[string]interface{}{
+			"api":                map[string]interface{}{"key_file": keyFile},
+			"default_streamtags": "env:staging",
+			"submit_max_metrics": "500",
+			"broker":             map[string]interface{}{"round_robin": true},
+		}})
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if cc.API.Key != "staging" {
+			t.Fatalf("expected api key staging, got %q", cc.API.Key)
+		}
+		if cc.API.App != "app" || cc.API.URL != base.API.URL {
+			t.Fatalf("expected api app and url from base, got %#v", cc.API)
+		}
+		if cc.DefaultStreamtags != "env:staging" {
+			t.Fatalf("expected env:staging, got %s", cc.DefaultStreamtags)
+		}
+		if cc.SubmitMaxMetrics != 500 {
+			t.Fatalf("expected 500, got %d", cc.SubmitMaxMetrics)
+		}
+		if !cc.Broker.RoundRobin {
+			t.Fatal("expected broker round robin")
+		}
+		if cc.MetricFiltersFile != base.MetricFiltersFile || !cc.DryRun {
+			t.Fatalf("expected settings not overridden from base, got %#v", cc)
+		}
+		if base.API.Key != "global" {
+			t.Fatal("expected base config unchanged")
+		}
+	}
+
+	t.Log("invalid override")
+	{
+		_, err := ClusterCirconus(base, Cluster{Name: "bad", Circonus: map[string]interface{}{"no_such_setting": "x"}})
+		if err == nil {
+			t.Fatal("expected error")
+		}
+	}
+}
+
+func TestObfuscateOverrides(t *testing.T) {
+	t.Log("Testing obfuscateOverrides")
+
+	overrides := map[string]interface{}{
+		"api":                map[string]interface{}{"key": "secret", "app": "app"},
+		"default_streamtags": "env:staging",
+	}
+	o := obfuscateOverrides(overrides)
+	if key := o["api"].(map[string]interface{})["key"]; key != "..." {
+		t.Fatalf("expected obfuscated key, got %v", key)
+	}
+	if key := overrides["api"].(map[string]interface{})["key"]; key != "secret" {
+		t.Fatalf("expected overrides unchanged, got %v", key)
+	}
+	if o["default_streamtags"] != "env:staging" {
+		t.Fatalf("expected env:staging, got %v", o["default_streamtags"])
+	}
+}
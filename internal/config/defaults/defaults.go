@@ -39,9 +39,32 @@ const (
 	TraceSubmits       = ""
 	CollectDeadline    = ""    // if not set, will be set to collection interval - SubmitDeadline
 	SubmitDeadline     = "10s" // must be less than  collection interval
+	Sinks              = "circonus-trap"
+	SinkFile           = ""
+	// prometheus remote-write sink
+	RemoteWriteURL         = ""
+	RemoteWriteBearerToken = ""
+	// otlp sink
+	OTLPURL      = ""
+	OTLPEncoding = "protobuf"
+	OTLPHeaders  = ""
+	// failed submission spool
+	SpoolDir           = ""
+	SpoolMaxSize       = "256MB"
+	SpoolMaxAge        = "24h"
+	SpoolMaxReplayRate = 5
+	// trap submission chunking
+	SubmitMaxBytes    = "8MB"
+	SubmitMaxMetrics  = 25000
+	SubmitParallelism = 4
+	SubmitStreamMin   = 5000
+	// broker connections
+	BrokerMaxIdleConns    = 10
+	BrokerIdleConnTimeout = "90s"
+	BrokerDisableHTTP2    = false
+	BrokerRoundRobin      = false
 	// hidden circonus settings for development and debugging
 	DryRun = false
-	// StreamMetrics = false
 	// these hidden settings are mainly for debugging
 	// the features default to ON and can be toggled OFF
 	NoBase64        = false
@@ -57,6 +80,22 @@ const (
 	LogLevel  = "info"
 	LogPretty = false
 
+	ConfigWatchInterval = "" // blank=disabled, reload on SIGHUP only
+
+	// readiness and liveness probes
+	ProbesMaxCollectAge       = ""   // blank=3 collection intervals
+	ProbesMaxSubmitAge        = "1h" // submissions failing for an hour
+	ProbesMaxDeadlineTimeouts = 3    // consecutive collections
+
+	// internal http server
+	ServerListen          = ":8080"
+	ServerTLSCertFile     = "" // blank=http
+	ServerTLSKeyFile      = ""
+	ServerClientCAFile    = "" // blank=no client certificate auth
+	ServerBearerToken     = "" // blank=no token auth
+	ServerBearerTokenFile = ""
+	ServerEnablePprof     = false
+
 	// Kubernetes cluster
 
 	/*
@@ -98,10 +137,41 @@ const (
 	K8SPodLabelVal               = ""                                                    // blank=all
 	K8SIncludeContainers         = false                                                 // not needed by dashboard
 	K8SAPITimelimit              = "10s"                                                 // default timeout
+	K8SAPIQPS                    = 20                                                    // client-go default is 5
+	K8SAPIBurst                  = 40                                                    // client-go default is 10
 	K8SDynamicCollectorFile      = "/ck8sa/dynamic-collectors.yaml"                      // assumes running in a pod, ConfigMap mounted volume
+	K8SPodCacheResync            = "5m"                                                  // bounds pod cache staleness when there are no pod changes
+	K8SNodeRequestMode           = "proxy"                                               // 'proxy' or 'direct' modes supported
+	K8SKubeletPort               = 10250                                                 // default kubelet port
+	K8SKubeletCAFile             = ""                                                    // blank=use api ca file
+	K8SKubeletInsecure           = false                                                 // verify kubelet certificates
+	K8SMode                      = "all"                                                 // 'all', 'cluster' or 'node' modes supported
+	K8SNodeName                  = ""                                                    // set from downward api (spec.nodeName) in 'node' mode
+	K8SCollectorSchedules        = ""                                                    // blank=all collectors use interval and collect deadline
+
+	// leader election, client-go recommended lease settings
+	K8SLeaderElect                 = false                       // single replica
+	K8SLeaderElectionNamespace     = ""                          // blank=service account namespace
+	K8SLeaderElectionLeaseName     = "circonus-kubernetes-agent" // lease shared by replicas
+	K8SLeaderElectionLeaseDuration = "15s"
+	K8SLeaderElectionRenewDeadline = "10s"
+	K8SLeaderElectionRetryPeriod   = "2s"
+
+	// client certificate and credential exec plugin authentication
+	K8SClientCertFile = ""
+	K8SClientKeyFile  = ""
+	K8SExecCommand    = ""
+	K8SExecAPIVersion = "client.authentication.k8s.io/v1"
+
+	// kubeconfig, out-of-cluster operation
+	K8SKubeconfig  = ""
+	K8SKubeContext = "" // blank=current-context
 )
 
 var (
+	// K8SExecArgs credential exec plugin arguments
+	K8SExecArgs = []string{}
+
 	// BasePath is the "base" directory
 	//
 	// expected installation structure:
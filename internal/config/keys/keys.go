@@ -98,6 +98,64 @@ const (
 	// SubmitDeadline sets the timeout deadline for metric submissions
 	SubmitDeadline = "circonus.submit_deadline"
 
+	// SubmitMaxBytes maximum serialized size of a trap submission, larger batches are split (e.g. 8MB)
+	SubmitMaxBytes = "circonus.submit_max_bytes"
+
+	// SubmitMaxMetrics maximum number of metrics in a trap submission, larger batches are split
+	SubmitMaxMetrics = "circonus.submit_max_metrics"
+
+	// SubmitParallelism number of chunks of a split batch submitted concurrently
+	SubmitParallelism = "circonus.submit_parallelism"
+
+	// SubmitStreamMin minimum number of metrics in a trap submission chunk for it to be
+	// encoded directly into the request body (bounds memory to the chunk size), 0 disables
+	SubmitStreamMin = "circonus.submit_stream_min"
+
+	// BrokerMaxIdleConns maximum idle (keep-alive) connections kept to the broker
+	BrokerMaxIdleConns = "circonus.broker.max_idle_conns"
+
+	// BrokerIdleConnTimeout how long idle broker connections are kept open
+	BrokerIdleConnTimeout = "circonus.broker.idle_conn_timeout"
+
+	// BrokerDisableHTTP2 disables negotiating http/2 with the broker
+	BrokerDisableHTTP2 = "circonus.broker.disable_http2"
+
+	// BrokerRoundRobin spread submissions across healthy brokers when the check has multiple brokers
+	BrokerRoundRobin = "circonus.broker.round_robin"
+
+	// Sinks comma separated list of destinations for metrics (circonus-trap, stdout-json, file, prometheus-remote-write, otlp)
+	Sinks = "circonus.sinks"
+
+	// SinkFile file metrics are appended to when using the file sink
+	SinkFile = "circonus.sink_file"
+
+	// RemoteWriteURL prometheus remote-write endpoint url used by the prometheus-remote-write sink
+	RemoteWriteURL = "circonus.remote_write.url"
+
+	// RemoteWriteBearerToken bearer token sent to the prometheus remote-write endpoint
+	RemoteWriteBearerToken = "circonus.remote_write.bearer_token" //nolint:gosec
+
+	// OTLPURL OpenTelemetry OTLP/HTTP metrics endpoint url used by the otlp sink (e.g. http://collector:4318/v1/metrics)
+	OTLPURL = "circonus.otlp.url"
+
+	// OTLPEncoding OTLP/HTTP request encoding (protobuf or json)
+	OTLPEncoding = "circonus.otlp.encoding"
+
+	// OTLPHeaders comma separated list of key=value headers sent to the OTLP/HTTP endpoint
+	OTLPHeaders = "circonus.otlp.headers"
+
+	// SpoolDir directory failed submissions are spooled to for replay (empty disables spooling)
+	SpoolDir = "circonus.spool.dir"
+
+	// SpoolMaxSize maximum total size of spooled submissions, oldest are dropped first (e.g. 256MB)
+	SpoolMaxSize = "circonus.spool.max_size"
+
+	// SpoolMaxAge maximum age of spooled submissions, older are dropped (e.g. 24h)
+	SpoolMaxAge = "circonus.spool.max_age"
+
+	// SpoolMaxReplayRate maximum number of spooled submissions replayed per second (0 = unlimited)
+	SpoolMaxReplayRate = "circonus.spool.max_replay_rate"
+
 	//
 	// hidden circonus settings for development and debugging
 	//
@@ -207,9 +265,79 @@ const (
 	// K8SAPITimelimit amount of time to wait for a complete response from api-server
 	K8SAPITimelimit = "kubernetes.api_timelimit"
 
+	// K8SAPIQPS sustained queries per second allowed to the api-server (shared by all collectors of a cluster)
+	K8SAPIQPS = "kubernetes.api_qps"
+
+	// K8SAPIBurst burst of queries allowed to the api-server above K8SAPIQPS
+	K8SAPIBurst = "kubernetes.api_burst"
+
 	// K8SDynamicCollectorFile defines the file containing the dynamic collectors configuration
 	K8SDynamicCollectorFile = "kubernetes.dynamic_collector_file"
 
+	// K8SPodCacheResync resync period of the pod cache used for pod labels and resource specs
+	K8SPodCacheResync = "kubernetes.pod_cache_resync"
+
+	// K8SNodeRequestMode how kubelet endpoints are requested, 'proxy' (via api-server) or 'direct' (kubelet on node InternalIP)
+	K8SNodeRequestMode = "kubernetes.node_request_mode"
+
+	// K8SKubeletPort kubelet port used in direct mode when the node status does not include it
+	K8SKubeletPort = "kubernetes.kubelet_port"
+
+	// K8SKubeletCAFile CA used to verify kubelet serving certificates in direct mode (default: api CA file)
+	K8SKubeletCAFile = "kubernetes.kubelet_ca_file"
+
+	// K8SKubeletInsecure do not verify kubelet serving certificates in direct mode
+	K8SKubeletInsecure = "kubernetes.kubelet_insecure"
+
+	// K8SMode collection mode, 'all', 'cluster' (cluster-scope collectors only) or 'node' (local node only, DaemonSet)
+	K8SMode = "kubernetes.mode"
+
+	// K8SNodeName name of the node the agent is running on, required in 'node' mode (downward api NODE_NAME)
+	K8SNodeName = "kubernetes.node_name"
+
+	// K8SCollectorSchedules per-collector intervals and deadlines, comma separated list of id=interval[/deadline]
+	// (ids: node, ksm, api, dns, health, dynamic), collectors not listed use the collection interval and deadline
+	K8SCollectorSchedules = "kubernetes.collector_schedules"
+
+	// K8SLeaderElect enable lease based leader election, only the leader collects (standby replicas take over if it fails)
+	K8SLeaderElect = "kubernetes.leader_election.enabled"
+
+	// K8SLeaderElectionNamespace namespace of the leader election lease (default: agent's service account namespace)
+	K8SLeaderElectionNamespace = "kubernetes.leader_election.namespace"
+
+	// K8SLeaderElectionLeaseName name of the leader election lease
+	K8SLeaderElectionLeaseName = "kubernetes.leader_election.lease_name"
+
+	// K8SLeaderElectionLeaseDuration how long standby replicas wait before taking over an un-renewed lease
+	K8SLeaderElectionLeaseDuration = "kubernetes.leader_election.lease_duration"
+
+	// K8SLeaderElectionRenewDeadline how long the leader retries renewing the lease before giving up leadership
+	K8SLeaderElectionRenewDeadline = "kubernetes.leader_election.renew_deadline"
+
+	// K8SLeaderElectionRetryPeriod how often replicas try to acquire or renew the lease
+	K8SLeaderElectionRetryPeriod = "kubernetes.leader_election.retry_period"
+
+	// K8SClientCertFile client certificate used to authenticate to the k8s api (alternative to a bearer token)
+	K8SClientCertFile = "kubernetes.client_cert_file"
+
+	// K8SClientKeyFile client certificate key used to authenticate to the k8s api
+	K8SClientKeyFile = "kubernetes.client_key_file"
+
+	// K8SExecCommand client-go credential exec plugin command used to authenticate to the k8s api (alternative to a bearer token)
+	K8SExecCommand = "kubernetes.exec.command"
+
+	// K8SExecArgs credential exec plugin arguments
+	K8SExecArgs = "kubernetes.exec.args"
+
+	// K8SExecAPIVersion credential exec plugin api version (client.authentication.k8s.io/v1, v1beta1)
+	K8SExecAPIVersion = "kubernetes.exec.api_version"
+
+	// K8SKubeconfig kubeconfig file used to access the k8s api from outside the cluster (supersedes api url, ca file and credentials)
+	K8SKubeconfig = "kubernetes.kubeconfig"
+
+	// K8SKubeContext kubeconfig context (default: current-context)
+	K8SKubeContext = "kubernetes.kube_context"
+
 	//
 	// Kubernetes clusters (multiple, use either kubernetes or clusters, not both)
 	//
@@ -234,6 +362,50 @@ const (
 	// Debug enables debug messages
 	Debug = "debug"
 
+	// ConfigWatchInterval how often to check the configuration, metric filter, alert and dynamic
+	// collector files for changes (e.g. an updated ConfigMap) and reload, blank disables (use SIGHUP)
+	ConfigWatchInterval = "config_watch_interval"
+
+	//
+	// Probes
+	//
+
+	// ProbesMaxCollectAge liveness fails when there has not been a successful collection
+	// within this duration, blank uses 3 collection intervals
+	ProbesMaxCollectAge = "probes.max_collect_age"
+
+	// ProbesMaxSubmitAge liveness fails when there has not been a successful submission within this duration
+	ProbesMaxSubmitAge = "probes.max_submit_age"
+
+	// ProbesMaxDeadlineTimeouts liveness fails after this many consecutive collections
+	// hit the collection deadline, 0 disables
+	ProbesMaxDeadlineTimeouts = "probes.max_deadline_timeouts"
+
+	//
+	// Internal http server
+	//
+
+	// ServerListen address the internal http server listens on (stats, metrics, probes)
+	ServerListen = "server.listen"
+
+	// ServerTLSCertFile certificate file for serving https
+	ServerTLSCertFile = "server.tls_cert_file"
+
+	// ServerTLSKeyFile key file for serving https
+	ServerTLSKeyFile = "server.tls_key_file"
+
+	// ServerClientCAFile ca file used to verify client certificates (mTLS), requires tls
+	ServerClientCAFile = "server.client_ca_file"
+
+	// ServerBearerToken token clients must present in an Authorization header
+	ServerBearerToken = "server.bearer_token" //nolint:gosec
+
+	// ServerBearerTokenFile file containing the token clients must present
+	ServerBearerTokenFile = "server.bearer_token_file" //nolint:gosec
+
+	// ServerEnablePprof serves the go pprof endpoints at /debug/pprof/
+	ServerEnablePprof = "server.enable_pprof"
+
 	//
 	// Informational
 	// NOTE: these ARE NOT included in the configuration file as they
@@ -24,20 +24,20 @@ import (
 	cgm "github.com/circonus-labs/circonus-gometrics/v3"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/circonus"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
-	"github.com/circonus-labs/circonus-kubernetes-agent/internal/k8s"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/promtext"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/release"
-	"github.com/prometheus/common/expfmt"
 	"github.com/rs/zerolog"
 	"gopkg.in/yaml.v2"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 type DC struct {
 	sync.Mutex
 	config     *config.Cluster
 	check      *circonus.Check
+	clientset  kubernetes.Interface
 	ts         *time.Time
 	log        zerolog.Logger
 	collectors []Collector `yaml:"collectors"`
@@ -97,11 +97,12 @@ type Rollup struct {
 	Value      string `yaml:"value"`
 }
 
-func New(cfg *config.Cluster, parentLogger zerolog.Logger, check *circonus.Check) (*DC, error) {
+func New(cfg *config.Cluster, parentLogger zerolog.Logger, check *circonus.Check, clientset kubernetes.Interface) (*DC, error) {
 	dc := &DC{
-		config: cfg,
-		check:  check,
-		log:    parentLogger.With().Str("pkg", "dynamic-collectors").Logger(),
+		config:    cfg,
+		check:     check,
+		clientset: clientset,
+		log:       parentLogger.With().Str("pkg", "dynamic-collectors").Logger(),
 	}
 
 	configFile := cfg.DynamicCollectorFile
@@ -147,6 +148,10 @@ func New(cfg *config.Cluster, parentLogger zerolog.Logger, check *circonus.Check
 	return dc, nil
 }
 
+func (dc *DC) ID() string {
+	return "dynamic"
+}
+
 func (dc *DC) Collect(ctx context.Context, tlsConfig *tls.Config, ts *time.Time) {
 	dc.Lock()
 	if dc.running {
@@ -224,12 +229,6 @@ type metricTarget struct {
 func (dc *DC) collectEndpoints(ctx context.Context, collector Collector) {
 	logger := dc.log.With().Str("collector-type", collector.Type).Str("collector-name", collector.Name).Logger()
 
-	clientset, err := k8s.GetClient(dc.config)
-	if err != nil {
-		logger.Warn().Err(err).Msg("initializing k8s client")
-		return
-	}
-
 	opts := metav1.ListOptions{}
 	if collector.Selectors.Field != "" {
 		opts.FieldSelector = collector.Selectors.Field
@@ -238,7 +237,7 @@ func (dc *DC) collectEndpoints(ctx context.Context, collector Collector) {
 		opts.LabelSelector = collector.Selectors.Label
 	}
 
-	endpoints, err := clientset.CoreV1().Endpoints("").List(ctx, opts)
+	endpoints, err := dc.clientset.CoreV1().Endpoints("").List(ctx, opts)
 	if err != nil {
 		logger.Warn().Err(err).Msg("querying k8s endpoints")
 		return
@@ -287,12 +286,6 @@ func (dc *DC) collectEndpoints(ctx context.Context, collector Collector) {
 func (dc *DC) collectNodes(ctx context.Context, collector Collector) {
 	logger := dc.log.With().Str("collector-type", collector.Type).Str("collector-name", collector.Name).Logger()
 
-	clientset, err := k8s.GetClient(dc.config)
-	if err != nil {
-		logger.Warn().Err(err).Msg("initializing k8s client")
-		return
-	}
-
 	opts := metav1.ListOptions{}
 	if collector.Selectors.Field != "" {
 		opts.FieldSelector = collector.Selectors.Field
@@ -301,7 +294,7 @@ func (dc *DC) collectNodes(ctx context.Context, collector Collector) {
 		opts.LabelSelector = collector.Selectors.Label
 	}
 
-	nodes, err := clientset.CoreV1().Nodes().List(ctx, opts)
+	nodes, err := dc.clientset.CoreV1().Nodes().List(ctx, opts)
 	if err != nil {
 		logger.Warn().Err(err).Msg("querying k8s nodes")
 		return
@@ -371,12 +364,6 @@ func (dc *DC) collectNodes(ctx context.Context, collector Collector) {
 func (dc *DC) collectPods(ctx context.Context, collector Collector) {
 	logger := dc.log.With().Str("collector-type", collector.Type).Str("collector-name", collector.Name).Logger()
 
-	clientset, err := k8s.GetClient(dc.config)
-	if err != nil {
-		logger.Warn().Err(err).Msg("initializing k8s client")
-		return
-	}
-
 	opts := metav1.ListOptions{}
 	if collector.Selectors.Field != "" {
 		opts.FieldSelector = collector.Selectors.Field
@@ -385,7 +372,7 @@ func (dc *DC) collectPods(ctx context.Context, collector Collector) {
 		opts.LabelSelector = collector.Selectors.Label
 	}
 
-	pods, err := clientset.CoreV1().Pods("").List(ctx, opts)
+	pods, err := dc.clientset.CoreV1().Pods("").List(ctx, opts)
 	if err != nil {
 		logger.Warn().Err(err).Msg("querying k8s pods")
 		return
@@ -433,7 +420,7 @@ func (dc *DC) collectPods(ctx context.Context, collector Collector) {
 			var ownerName, ownerKind string
 			switch item.OwnerReferences[0].Kind {
 			case "ReplicaSet":
-				replica, repErr := clientset.AppsV1().ReplicaSets(item.Namespace).Get(ctx, item.OwnerReferences[0].Name, metav1.GetOptions{})
+				replica, repErr := dc.clientset.AppsV1().ReplicaSets(item.Namespace).Get(ctx, item.OwnerReferences[0].Name, metav1.GetOptions{})
 				if repErr != nil {
 					logger.Warn().Str("pod", item.Name).Msg("unable to get replicaset for pod")
 				} else if len(replica.OwnerReferences) > 0 {
@@ -474,12 +461,6 @@ func (dc *DC) collectPods(ctx context.Context, collector Collector) {
 func (dc *DC) collectServices(ctx context.Context, collector Collector) {
 	logger := dc.log.With().Str("collector-type", collector.Type).Str("collector-name", collector.Name).Logger()
 
-	clientset, err := k8s.GetClient(dc.config)
-	if err != nil {
-		logger.Warn().Err(err).Msg("initializing k8s client")
-		return
-	}
-
 	opts := metav1.ListOptions{}
 	if collector.Selectors.Field != "" {
 		opts.FieldSelector = collector.Selectors.Field
@@ -488,7 +469,7 @@ func (dc *DC) collectServices(ctx context.Context, collector Collector) {
 		opts.LabelSelector = collector.Selectors.Label
 	}
 
-	services, err := clientset.CoreV1().Services("").List(ctx, opts)
+	services, err := dc.clientset.CoreV1().Services("").List(ctx, opts)
 	if err != nil {
 		logger.Warn().Err(err).Msg("querying k8s services")
 		return
@@ -583,6 +564,7 @@ func (dc *DC) getMetrics(ctx context.Context, collector Collector, target metric
 		return
 	}
 	req.Header.Add("User-Agent", release.NAME+"/"+release.VERSION)
+	req.Header.Add("Accept", promtext.AcceptHeader)
 	defer client.CloseIdleConnections()
 	resp, err := client.Do(req)
 	if err != nil {
@@ -629,8 +611,7 @@ func (dc *DC) getMetrics(ctx context.Context, collector Collector, target metric
 	streamTags = append(streamTags, target.Tags...)
 	measurementTags := []string{}
 
-	var parser expfmt.TextParser
-	if err := promtext.QueueMetrics(ctx, parser, dc.check, logger, data, streamTags, measurementTags, dc.ts); err != nil {
+	if err := promtext.QueueFormatMetrics(ctx, promtext.ResponseFormat(resp.Header), dc.check, logger, data, streamTags, measurementTags, dc.ts); err != nil {
 		logger.Warn().Err(err).Str("url", target.URL).Msg("parsing metrics")
 		return
 	}
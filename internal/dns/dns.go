@@ -22,20 +22,19 @@ import (
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config/defaults"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config/keys"
-	"github.com/circonus-labs/circonus-kubernetes-agent/internal/k8s"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/promtext"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/release"
 	"github.com/pkg/errors"
-	"github.com/prometheus/common/expfmt"
 	"github.com/rs/zerolog"
-	"github.com/spf13/viper"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 type DNS struct {
 	sync.Mutex
 	config       *config.Cluster
 	check        *circonus.Check
+	clientset    kubernetes.Interface
 	ts           *time.Time
 	service      string
 	log          zerolog.Logger
@@ -43,18 +42,22 @@ type DNS struct {
 	running      bool
 }
 
-func New(cfg *config.Cluster, parentLog zerolog.Logger, check *circonus.Check) (*DNS, error) {
+func New(cfg *config.Cluster, parentLog zerolog.Logger, check *circonus.Check, clientset kubernetes.Interface) (*DNS, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid cluster config (nil)")
 	}
 	if check == nil {
 		return nil, errors.New("invalid check (nil)")
 	}
+	if clientset == nil {
+		return nil, errors.New("invalid clientset (nil)")
+	}
 
 	dns := &DNS{
-		config: cfg,
-		check:  check,
-		log:    parentLog.With().Str("collector", "dns").Logger(),
+		config:    cfg,
+		check:     check,
+		clientset: clientset,
+		log:       parentLog.With().Str("collector", "dns").Logger(),
 	}
 
 	if cfg.APITimelimit != "" {
@@ -144,17 +147,12 @@ func (dns *DNS) Collect(ctx context.Context, tlsConfig *tls.Config, ts *time.Tim
 }
 
 func (dns *DNS) getMetricURLs(ctx context.Context) (map[string]string, error) {
-	clientset, err := k8s.GetClient(dns.config)
-	if err != nil {
-		return nil, err
-	}
-
-	svc, err := clientset.CoreV1().Services("kube-system").Get(ctx, "kube-dns", metav1.GetOptions{})
+	svc, err := dns.clientset.CoreV1().Services("kube-system").Get(ctx, "kube-dns", metav1.GetOptions{})
 	dns.service = "kube-dns"
 	if err != nil {
 		dns.log.Info().Str("get kube-dns service failed", err.Error()).Msg("service not found, checking coredns")
 		dns.service = "coredns"
-		svc, err = clientset.CoreV1().Services("kube-system").Get(ctx, "coredns", metav1.GetOptions{})
+		svc, err = dns.clientset.CoreV1().Services("kube-system").Get(ctx, "coredns", metav1.GetOptions{})
 		if err != nil {
 			dns.service = ""
 			dns.log.Warn().Str("get all dns services failed", err.Error()).Msg("service not found, nothing to do")
@@ -183,7 +181,10 @@ func (dns *DNS) getMetricURLs(ctx context.Context) (map[string]string, error) {
 	}
 
 	if port == 0 {
-		port = viper.GetInt(keys.K8SDNSMetricsPort)
+		port = dns.config.DNSMetricsPort
+		if port == 0 {
+			port, _ = strconv.Atoi(defaults.K8SDNSMetricsPort)
+		}
 		dns.log.Debug().Int("port", port).Msgf("service annotation for port not found, checking config for %s", keys.K8SDNSMetricsPort)
 		if port == 0 {
 			return nil, fmt.Errorf("no dns port defined in annotations or configuration")
@@ -192,7 +193,7 @@ func (dns *DNS) getMetricURLs(ctx context.Context) (map[string]string, error) {
 
 	if !scrape {
 		dns.log.Debug().Msgf("service annotation for scrape not found, checking config for %s", keys.K8SEnableDNSMetrics)
-		if !viper.GetBool(keys.K8SEnableDNSMetrics) {
+		if !dns.config.EnableDNSMetrics {
 			return nil, errors.New("service not configured for scraping in annotations or configuration")
 		}
 	}
@@ -208,7 +209,7 @@ func (dns *DNS) getMetricURLs(ctx context.Context) (map[string]string, error) {
 		i++
 	}
 
-	pods, err := clientset.CoreV1().Pods(svc.Namespace).List(ctx, metav1.ListOptions{LabelSelector: strings.Join(selectors, ",")})
+	pods, err := dns.clientset.CoreV1().Pods(svc.Namespace).List(ctx, metav1.ListOptions{LabelSelector: strings.Join(selectors, ",")})
 	if err != nil {
 		return nil, errors.Wrap(err, "getting list of dns pods")
 	}
@@ -233,6 +234,7 @@ func (dns *DNS) getMetrics(ctx context.Context, podName, metricURL string) error
 		dns.log.Warn().Err(err).Str("url", metricURL).Msg("building request")
 		return err
 	}
+	req.Header.Add("Accept", promtext.AcceptHeader)
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		dns.check.IncrementCounter("collect_api_errors", cgm.Tags{
@@ -277,8 +279,7 @@ func (dns *DNS) getMetrics(ctx context.Context, podName, metricURL string) error
 	}
 	measurementTags := []string{}
 
-	var parser expfmt.TextParser
-	if err := promtext.QueueMetrics(ctx, parser, dns.check, dns.log, resp.Body, streamTags, measurementTags, dns.ts); err != nil {
+	if err := promtext.QueueFormatMetrics(ctx, promtext.ResponseFormat(resp.Header), dns.check, dns.log, resp.Body, streamTags, measurementTags, dns.ts); err != nil {
 		return err
 	}
 
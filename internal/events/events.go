@@ -11,12 +11,12 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"errors"
+	"sync/atomic"
 	"time"
 
 	cgm "github.com/circonus-labs/circonus-gometrics/v3"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/circonus"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
-	"github.com/circonus-labs/circonus-kubernetes-agent/internal/k8s"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/release"
 	"github.com/rs/zerolog"
 	corev1 "k8s.io/api/core/v1"
@@ -26,23 +26,31 @@ import (
 )
 
 type Events struct {
-	config *config.Cluster
-	check  *circonus.Check
-	log    zerolog.Logger
+	config    *config.Cluster
+	check     *circonus.Check
+	informers informers.SharedInformerFactory
+	log       zerolog.Logger
+	paused    atomic.Bool
+	synced    atomic.Bool
 }
 
-func New(cfg *config.Cluster, parentLog zerolog.Logger, check *circonus.Check) (*Events, error) {
+// New creates an events collector, the event informer is added to the cluster's shared informer factory
+func New(cfg *config.Cluster, parentLog zerolog.Logger, check *circonus.Check, factory informers.SharedInformerFactory) (*Events, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid cluster config (nil)")
 	}
 	if check == nil {
 		return nil, errors.New("invalid check (nil)")
 	}
+	if factory == nil {
+		return nil, errors.New("invalid informer factory (nil)")
+	}
 
 	e := &Events{
-		config: cfg,
-		check:  check,
-		log:    parentLog.With().Str("collector", "events").Logger(),
+		config:    cfg,
+		check:     check,
+		informers: factory,
+		log:       parentLog.With().Str("collector", "events").Logger(),
 	}
 	return e, nil
 }
@@ -51,19 +59,26 @@ func (e *Events) ID() string {
 	return "events"
 }
 
+// Pause stops submitting events, the informer keeps running (e.g. a standby replica
+// keeps its event cache warm while another replica is the leader)
+func (e *Events) Pause() {
+	e.paused.Store(true)
+}
+
+// Resume submitting events
+func (e *Events) Resume() {
+	e.paused.Store(false)
+}
+
+// Synced indicates the event informer's cache has synced
+func (e *Events) Synced() bool {
+	return e.synced.Load()
+}
+
 func (e *Events) Start(ctx context.Context, tlsConfig *tls.Config) {
 	e.log.Info().Msg("starting watcher")
 
-	clientset, err := k8s.GetClient(e.config)
-	if err != nil {
-		e.log.Error().Err(err).Msg("initializing client set")
-		return
-	}
-
-	factory := informers.NewSharedInformerFactory(clientset, 0)
-	informer := factory.Core().V1().Events().Informer()
-	stopper := make(chan struct{})
-	defer close(stopper)
+	informer := e.informers.Core().V1().Events().Informer()
 	defer runtime.HandleCrash()
 
 	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -78,14 +93,18 @@ func (e *Events) Start(ctx context.Context, tlsConfig *tls.Config) {
 		},
 	})
 
-	go informer.Run(stopper)
+	e.informers.Start(ctx.Done())
 
-	if !cache.WaitForCacheSync(stopper, informer.HasSynced) {
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
 		e.log.Warn().Msg("timed out waiting for cache to sync")
 		return
 	}
+	e.synced.Store(true)
 
 	go func() {
+		if e.paused.Load() {
+			return // only the active (leader) replica submits the initial event
+		}
 		var measurementTags []string
 		ets := time.Now()
 		ae := abridgedEvent{
@@ -126,6 +145,10 @@ type abridgedEvent struct {
 }
 
 func (e *Events) submitEvent(ctx context.Context, event *corev1.Event) {
+	if e.paused.Load() {
+		return
+	}
+
 	e.check.IncrementCounter("collect_k8s_event_count", cgm.Tags{}) // aggregate
 	e.check.IncrementCounter("collect_k8s_event_count", cgm.Tags{
 		cgm.Tag{Category: "namespace", Value: event.InvolvedObject.Namespace},
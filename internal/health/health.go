@@ -20,27 +20,31 @@ import (
 	"github.com/rs/zerolog"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
 )
 
 type Health struct {
-	config *config.Cluster
-	check  *circonus.Check
-	log    zerolog.Logger
+	config    *config.Cluster
+	check     *circonus.Check
+	clientset kubernetes.Interface
+	log       zerolog.Logger
 }
 
-func New(cfg *config.Cluster, parentLog zerolog.Logger, check *circonus.Check) (*Health, error) {
+func New(cfg *config.Cluster, parentLog zerolog.Logger, check *circonus.Check, clientset kubernetes.Interface) (*Health, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid cluster config (nil)")
 	}
 	if check == nil {
 		return nil, errors.New("invalid check (nil)")
 	}
+	if clientset == nil {
+		return nil, errors.New("invalid clientset (nil)")
+	}
 
 	h := &Health{
-		config: cfg,
-		check:  check,
-		log:    parentLog.With().Str("collector", "health").Logger(),
+		config:    cfg,
+		check:     check,
+		clientset: clientset,
+		log:       parentLog.With().Str("collector", "health").Logger(),
 	}
 	return h, nil
 }
@@ -50,33 +54,6 @@ func (h *Health) ID() string {
 }
 
 func (h *Health) Collect(ctx context.Context, tlsConfig *tls.Config, ts *time.Time) {
-	var cfg *rest.Config
-	if c, err := rest.InClusterConfig(); err != nil {
-		if !errors.Is(err, rest.ErrNotInCluster) {
-			h.log.Error().Err(err).Msg("unable to initialize health collector")
-			return
-		}
-		// not in cluster, use supplied customer config for cluster
-		cfg = &rest.Config{}
-		if h.config.BearerToken != "" {
-			cfg.BearerToken = h.config.BearerToken
-		}
-		if h.config.URL != "" {
-			cfg.Host = h.config.URL
-		}
-		if h.config.CAFile != "" {
-			cfg.TLSClientConfig = rest.TLSClientConfig{CAFile: h.config.CAFile}
-		}
-	} else {
-		cfg = c // use in-cluster config
-	}
-
-	clientset, err := kubernetes.NewForConfig(cfg)
-	if err != nil {
-		h.log.Error().Err(err).Msg("initializing client set")
-		return
-	}
-
 	baseMeasurementTags := []string{}
 	baseStreamTags := []string{"source:" + release.NAME}
 
@@ -84,29 +61,29 @@ func (h *Health) Collect(ctx context.Context, tlsConfig *tls.Config, ts *time.Ti
 
 	wg.Add(1)
 	go func() {
-		h.deployments(ctx, clientset, ts, baseStreamTags, baseMeasurementTags)
+		h.deployments(ctx, ts, baseStreamTags, baseMeasurementTags)
 		wg.Done()
 	}()
 
 	wg.Add(1)
 	go func() {
-		h.daemonsets(ctx, clientset, ts, baseStreamTags, baseMeasurementTags)
+		h.daemonsets(ctx, ts, baseStreamTags, baseMeasurementTags)
 		wg.Done()
 	}()
 
 	wg.Add(1)
 	go func() {
-		h.statefulsets(ctx, clientset, ts, baseStreamTags, baseMeasurementTags)
+		h.statefulsets(ctx, ts, baseStreamTags, baseMeasurementTags)
 		wg.Done()
 	}()
 
 	wg.Wait()
 }
 
-func (h *Health) deployments(ctx context.Context, cs *kubernetes.Clientset, ts *time.Time, parentStreamTags, parentMeasurementTags []string) {
+func (h *Health) deployments(ctx context.Context, ts *time.Time, parentStreamTags, parentMeasurementTags []string) {
 	metrics := make(map[string]circonus.MetricSample)
 
-	list, err := cs.AppsV1().Deployments("").List(ctx, v1.ListOptions{})
+	list, err := h.clientset.AppsV1().Deployments("").List(ctx, v1.ListOptions{})
 	if err != nil {
 		h.log.Error().Err(err).Msg("deployments list")
 	}
@@ -139,10 +116,10 @@ func (h *Health) deployments(ctx context.Context, cs *kubernetes.Clientset, ts *
 	}
 }
 
-func (h *Health) daemonsets(ctx context.Context, cs *kubernetes.Clientset, ts *time.Time, parentStreamTags, parentMeasurementTags []string) {
+func (h *Health) daemonsets(ctx context.Context, ts *time.Time, parentStreamTags, parentMeasurementTags []string) {
 	metrics := make(map[string]circonus.MetricSample)
 
-	list, err := cs.AppsV1().DaemonSets("").List(ctx, v1.ListOptions{})
+	list, err := h.clientset.AppsV1().DaemonSets("").List(ctx, v1.ListOptions{})
 	if err != nil {
 		h.log.Error().Err(err).Msg("daemonsets list")
 	}
@@ -175,10 +152,10 @@ func (h *Health) daemonsets(ctx context.Context, cs *kubernetes.Clientset, ts *t
 	}
 }
 
-func (h *Health) statefulsets(ctx context.Context, cs *kubernetes.Clientset, ts *time.Time, parentStreamTags, parentMeasurementTags []string) {
+func (h *Health) statefulsets(ctx context.Context, ts *time.Time, parentStreamTags, parentMeasurementTags []string) {
 	metrics := make(map[string]circonus.MetricSample)
 
-	list, err := cs.AppsV1().StatefulSets("").List(ctx, v1.ListOptions{})
+	list, err := h.clientset.AppsV1().StatefulSets("").List(ctx, v1.ListOptions{})
 	if err != nil {
 		h.log.Error().Err(err).Msg("statefulsets list")
 	}
@@ -5,34 +5,95 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config/defaults"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/release"
 	apimachineryversion "k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
-func GetClient(clusterConfig *config.Cluster) (*kubernetes.Clientset, error) {
+// ClientFactory creates the kubernetes client used to access a cluster
+type ClientFactory func(clusterConfig *config.Cluster) (kubernetes.Interface, error)
+
+var (
+	clientFactoryMu sync.RWMutex
+	clientFactory   ClientFactory = NewClient
+)
+
+// SetClientFactory replaces the factory used by GetClient (e.g. with one returning
+// clients for a fake api server in tests). It returns a function which restores
+// the previous factory.
+func SetClientFactory(factory ClientFactory) func() {
+	clientFactoryMu.Lock()
+	defer clientFactoryMu.Unlock()
+	prev := clientFactory
+	clientFactory = factory
+	return func() {
+		clientFactoryMu.Lock()
+		defer clientFactoryMu.Unlock()
+		clientFactory = prev
+	}
+}
+
+// GetClient returns a kubernetes client for the cluster from the current ClientFactory
+func GetClient(clusterConfig *config.Cluster) (kubernetes.Interface, error) {
+	clientFactoryMu.RLock()
+	factory := clientFactory
+	clientFactoryMu.RUnlock()
+	return factory(clusterConfig)
+}
+
+// NewClient creates a kubernetes client using the kubeconfig file, the in-cluster
+// configuration or, when not running in a cluster or the cluster configuration is
+// for another cluster (api url or client certificate/exec credentials), the
+// supplied cluster configuration. It is the default ClientFactory.
+func NewClient(clusterConfig *config.Cluster) (kubernetes.Interface, error) {
 	var cfg *rest.Config
-	if c, err := rest.InClusterConfig(); err != nil {
-		if !errors.Is(err, rest.ErrNotInCluster) {
+	if clusterConfig.Kubeconfig != "" {
+		c, err := KubeconfigRESTConfig(clusterConfig.Kubeconfig, clusterConfig.KubeContext)
+		if err != nil {
 			return nil, fmt.Errorf("unable to configure k8s api client: %w", err)
 		}
-		// not in cluster, use supplied customer config for cluster
-		cfg = &rest.Config{}
-		if clusterConfig.BearerToken != "" {
-			cfg.BearerToken = clusterConfig.BearerToken
-		}
-		if clusterConfig.URL != "" {
-			cfg.Host = clusterConfig.URL
+		cfg = c
+	} else if c, err := rest.InClusterConfig(); err != nil || !inCluster(clusterConfig) {
+		if err != nil && !errors.Is(err, rest.ErrNotInCluster) {
+			return nil, fmt.Errorf("unable to configure k8s api client: %w", err)
 		}
-		if clusterConfig.CAFile != "" {
-			cfg.TLSClientConfig = rest.TLSClientConfig{CAFile: clusterConfig.CAFile}
+		// not in cluster (or another cluster), use supplied customer config for cluster
+		rc, err := RESTConfig(clusterConfig)
+		if err != nil {
+			return nil, fmt.Errorf("unable to configure k8s api client: %w", err)
 		}
+		cfg = rc
 	} else {
 		cfg = c // use in-cluster config
+		if c.BearerTokenFile != "" {
+			// the service account token is rotated, use the shared token source which also
+			// re-reads the token when a request is unauthorized
+			ts, err := TokenFileSource(c.BearerTokenFile)
+			if err != nil {
+				return nil, fmt.Errorf("unable to configure k8s api client: %w", err)
+			}
+			cfg.BearerToken = ""
+			cfg.BearerTokenFile = ""
+			cfg.WrapTransport = ts.WrapTransport
+		}
 	}
 
+	// one clientset is shared by all collectors of a cluster, client-go defaults (5/10) are too low
+	if clusterConfig.APIQPS > 0 {
+		cfg.QPS = float32(clusterConfig.APIQPS)
+	}
+	if clusterConfig.APIBurst > 0 {
+		cfg.Burst = int(clusterConfig.APIBurst)
+	}
+	cfg.UserAgent = release.NAME + "/" + release.VERSION
+
 	clientset, err := kubernetes.NewForConfig(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("initializing k8s api Clientset: %w", err)
@@ -41,46 +102,96 @@ func GetClient(clusterConfig *config.Cluster) (*kubernetes.Clientset, error) {
 	return clientset, nil
 }
 
+// inCluster returns true if the cluster configuration is for the cluster the agent is running in
+func inCluster(clusterConfig *config.Cluster) bool {
+	if clusterConfig.ClientCertFile != "" || clusterConfig.Exec.Command != "" {
+		return false
+	}
+	return clusterConfig.URL == "" || clusterConfig.URL == defaults.K8SAPIURL
+}
+
+// RESTConfig returns the rest configuration for the supplied cluster configuration,
+// authenticating with a bearer token (see ClusterTokenSource), client certificate or
+// credential exec plugin
+func RESTConfig(clusterConfig *config.Cluster) (*rest.Config, error) {
+	cfg := &rest.Config{
+		Host: clusterConfig.URL,
+		TLSClientConfig: rest.TLSClientConfig{
+			CAFile:   clusterConfig.CAFile,
+			CertFile: clusterConfig.ClientCertFile,
+			KeyFile:  clusterConfig.ClientKeyFile,
+		},
+	}
+
+	if clusterConfig.Exec.Command != "" {
+		apiVersion := clusterConfig.Exec.APIVersion
+		if apiVersion == "" {
+			apiVersion = defaults.K8SExecAPIVersion
+		}
+		env := make([]clientcmdapi.ExecEnvVar, 0, len(clusterConfig.Exec.Env))
+		for _, e := range clusterConfig.Exec.Env {
+			name, value, _ := strings.Cut(e, "=")
+			env = append(env, clientcmdapi.ExecEnvVar{Name: name, Value: value})
+		}
+		cfg.ExecProvider = &clientcmdapi.ExecConfig{
+			Command:         clusterConfig.Exec.Command,
+			Args:            clusterConfig.Exec.Args,
+			Env:             env,
+			APIVersion:      apiVersion,
+			InteractiveMode: clientcmdapi.NeverExecInteractiveMode,
+		}
+	}
+
+	ts, err := ClusterTokenSource(clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	if ts != nil {
+		cfg.WrapTransport = ts.WrapTransport
+	}
+
+	return cfg, nil
+}
+
 // GetVersion gets the cluster version
 func GetVersion(ctx context.Context, clusterConfig *config.Cluster) (string, error) {
 	clientset, err := GetClient(clusterConfig)
 	if err != nil {
 		return "", err
 	}
-	req := clientset.CoreV1().RESTClient().Get().RequestURI("/version")
-	res := req.Do(ctx)
 
-	data, err := res.Raw()
+	ver, err := ServerVersion(ctx, clientset)
 	if err != nil {
 		return "", err
 	}
 
-	var ver apimachineryversion.Info
-	if err := json.Unmarshal(data, &ver); err != nil {
-		return "", err
-	}
-
 	return ver.GitVersion, nil
 }
 
 // GetVersionPlatform gets the cluster version + " " + platform
-func GetVersionPlatform(ctx context.Context, clusterConfig *config.Cluster) (string, error) {
-	clientset, err := GetClient(clusterConfig)
+func GetVersionPlatform(ctx context.Context, clientset kubernetes.Interface) (string, error) {
+	ver, err := ServerVersion(ctx, clientset)
 	if err != nil {
 		return "", err
 	}
+
+	return ver.GitVersion + " " + ver.Platform, nil
+}
+
+// ServerVersion gets the cluster version information
+func ServerVersion(ctx context.Context, clientset kubernetes.Interface) (*apimachineryversion.Info, error) {
 	req := clientset.CoreV1().RESTClient().Get().RequestURI("/version")
 	res := req.Do(ctx)
 
 	data, err := res.Raw()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	var ver apimachineryversion.Info
 	if err := json.Unmarshal(data, &ver); err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return ver.GitVersion + " " + ver.Platform, nil
+	return &ver, nil
 }
@@ -0,0 +1,230 @@
+package k8s
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config/defaults"
+	yaml "gopkg.in/yaml.v2"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// kubeconfig is the subset of the kubeconfig file format (clientcmd/api/v1) used to
+// configure an api client: clusters, users (credentials) and the contexts pairing them
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string            `yaml:"name"`
+		Cluster kubeconfigCluster `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string         `yaml:"name"`
+		User kubeconfigUser `yaml:"user"`
+	} `yaml:"users"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster string `yaml:"cluster"`
+			User    string `yaml:"user"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+type kubeconfigCluster struct {
+	Server                   string `yaml:"server"`
+	TLSServerName            string `yaml:"tls-server-name"`
+	CertificateAuthority     string `yaml:"certificate-authority"`
+	CertificateAuthorityData string `yaml:"certificate-authority-data"`
+	ProxyURL                 string `yaml:"proxy-url"`
+	InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+}
+
+type kubeconfigUser struct {
+	ClientCertificate     string `yaml:"client-certificate"`
+	ClientCertificateData string `yaml:"client-certificate-data"`
+	ClientKey             string `yaml:"client-key"`
+	ClientKeyData         string `yaml:"client-key-data"`
+	Token                 string `yaml:"token"`
+	TokenFile             string `yaml:"tokenFile"`
+	Username              string `yaml:"username"`
+	Password              string `yaml:"password"`
+	Exec                  *struct {
+		Command string   `yaml:"command"`
+		Args    []string `yaml:"args"`
+		Env     []struct {
+			Name  string `yaml:"name"`
+			Value string `yaml:"value"`
+		} `yaml:"env"`
+		APIVersion         string `yaml:"apiVersion"`
+		InstallHint        string `yaml:"installHint"`
+		ProvideClusterInfo bool   `yaml:"provideClusterInfo"`
+	} `yaml:"exec"`
+	AuthProvider *struct {
+		Name string `yaml:"name"`
+	} `yaml:"auth-provider"`
+}
+
+// KubeconfigRESTConfig returns the rest configuration for a context (blank for the current
+// context) in a kubeconfig file. Credentials are refreshed by client-go: exec plugins are
+// re-run when their credentials expire, token and client certificate files are re-read.
+func KubeconfigRESTConfig(path, context string) (*rest.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading kubeconfig: %w", err)
+	}
+
+	var kc kubeconfig
+	if err := yaml.Unmarshal(data, &kc); err != nil {
+		return nil, fmt.Errorf("parsing kubeconfig %s: %w", path, err)
+	}
+
+	if context == "" {
+		context = kc.CurrentContext
+	}
+	if context == "" {
+		return nil, fmt.Errorf("kubeconfig %s: no context specified and no current-context", path)
+	}
+
+	var clusterName, userName string
+	found := false
+	for _, c := range kc.Contexts {
+		if c.Name == context {
+			clusterName, userName, found = c.Context.Cluster, c.Context.User, true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("kubeconfig %s: context %s not found", path, context)
+	}
+
+	var cluster *kubeconfigCluster
+	for idx := range kc.Clusters {
+		if kc.Clusters[idx].Name == clusterName {
+			cluster = &kc.Clusters[idx].Cluster
+			break
+		}
+	}
+	if cluster == nil {
+		return nil, fmt.Errorf("kubeconfig %s: context %s cluster %s not found", path, context, clusterName)
+	}
+	if cluster.Server == "" {
+		return nil, fmt.Errorf("kubeconfig %s: cluster %s has no server", path, clusterName)
+	}
+
+	// a context without a user is anonymous, a user which is not defined is an error
+	var user kubeconfigUser
+	if userName != "" {
+		found = false
+		for idx := range kc.Users {
+			if kc.Users[idx].Name == userName {
+				user, found = kc.Users[idx].User, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("kubeconfig %s: context %s user %s not found", path, context, userName)
+		}
+	}
+
+	// relative paths are relative to the kubeconfig file
+	dir := filepath.Dir(path)
+	resolve := func(file string) string {
+		if file == "" || filepath.IsAbs(file) {
+			return file
+		}
+		return filepath.Join(dir, file)
+	}
+
+	cfg := &rest.Config{
+		Host:            cluster.Server,
+		BearerToken:     user.Token,
+		BearerTokenFile: resolve(user.TokenFile),
+		Username:        user.Username,
+		Password:        user.Password,
+		TLSClientConfig: rest.TLSClientConfig{
+			Insecure:   cluster.InsecureSkipTLSVerify,
+			ServerName: cluster.TLSServerName,
+			CAFile:     resolve(cluster.CertificateAuthority),
+			CertFile:   resolve(user.ClientCertificate),
+			KeyFile:    resolve(user.ClientKey),
+		},
+	}
+
+	for _, d := range []struct {
+		name string
+		data string
+		dst  *[]byte
+	}{
+		{"certificate-authority-data", cluster.CertificateAuthorityData, &cfg.TLSClientConfig.CAData},
+		{"client-certificate-data", user.ClientCertificateData, &cfg.TLSClientConfig.CertData},
+		{"client-key-data", user.ClientKeyData, &cfg.TLSClientConfig.KeyData},
+	} {
+		if d.data == "" {
+			continue
+		}
+		b, err := base64.StdEncoding.DecodeString(d.data)
+		if err != nil {
+			return nil, fmt.Errorf("kubeconfig %s: decoding %s: %w", path, d.name, err)
+		}
+		*d.dst = b
+	}
+
+	if cluster.ProxyURL != "" {
+		proxy, err := parseProxyURL(cluster.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("kubeconfig %s: %w", path, err)
+		}
+		cfg.Proxy = proxy
+	}
+
+	if user.Exec != nil {
+		apiVersion := user.Exec.APIVersion
+		if apiVersion == "" {
+			apiVersion = defaults.K8SExecAPIVersion
+		}
+		command := user.Exec.Command
+		if filepath.Base(command) != command && !filepath.IsAbs(command) {
+			command = resolve(command) // relative path, not a command in PATH
+		}
+		env := make([]clientcmdapi.ExecEnvVar, 0, len(user.Exec.Env))
+		for _, e := range user.Exec.Env {
+			env = append(env, clientcmdapi.ExecEnvVar{Name: e.Name, Value: e.Value})
+		}
+		cfg.ExecProvider = &clientcmdapi.ExecConfig{
+			Command:            command,
+			Args:               user.Exec.Args,
+			Env:                env,
+			APIVersion:         apiVersion,
+			InstallHint:        user.Exec.InstallHint,
+			ProvideClusterInfo: user.Exec.ProvideClusterInfo,
+			InteractiveMode:    clientcmdapi.NeverExecInteractiveMode,
+		}
+	}
+
+	if user.AuthProvider != nil && user.Exec == nil {
+		// legacy in-tree auth providers (gcp, azure, oidc) are not compiled in, the
+		// cloud providers' exec plugins (e.g. gke-gcloud-auth-plugin, kubelogin) replace them
+		return nil, fmt.Errorf("kubeconfig %s: user %s auth-provider %s not supported, use an exec credential plugin", path, userName, user.AuthProvider.Name)
+	}
+
+	return cfg, nil
+}
+
+// parseProxyURL returns the proxy function for a cluster's proxy-url
+func parseProxyURL(proxyURL string) (func(*http.Request) (*url.URL, error), error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing proxy-url %s: %w", proxyURL, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported proxy-url scheme %q", u.Scheme)
+	}
+	return http.ProxyURL(u), nil
+}
@@ -0,0 +1,140 @@
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: prod
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com
+    certificate-authority: certs/ca.crt
+- name: staging
+  cluster:
+    server: https://staging.example.com
+    certificate-authority-data: Y2EtZGF0YQ==
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: prod-admin
+- name: staging
+  context:
+    cluster: staging
+    user: staging-eks
+- name: legacy
+  context:
+    cluster: prod
+    user: legacy-gcp
+- name: typo
+  context:
+    cluster: prod
+    user: prod-admn
+users:
+- name: prod-admin
+  user:
+    client-certificate: /etc/certs/client.crt
+    client-key: /etc/certs/client.key
+- name: staging-eks
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: aws
+      args: ["eks", "get-token", "--cluster-name", "staging"]
+      env:
+      - name: AWS_PROFILE
+        value: staging
+- name: legacy-gcp
+  user:
+    auth-provider:
+      name: gcp
+`
+
+func TestKubeconfigRESTConfig(t *testing.T) {
+	t.Log("Testing KubeconfigRESTConfig")
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config")
+	if err := os.WriteFile(file, []byte(testKubeconfig), 0600); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	t.Log("current context, client certificate")
+	{
+		cfg, err := KubeconfigRESTConfig(file, "")
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if cfg.Host != "https://prod.example.com" {
+			t.Fatalf("expected prod host, got %s", cfg.Host)
+		}
+		if cfg.TLSClientConfig.CAFile != filepath.Join(dir, "certs", "ca.crt") {
+			t.Fatalf("expected ca file relative to kubeconfig, got %s", cfg.TLSClientConfig.CAFile)
+		}
+		if cfg.TLSClientConfig.CertFile != "/etc/certs/client.crt" || cfg.TLSClientConfig.KeyFile != "/etc/certs/client.key" {
+			t.Fatalf("expected client cert and key, got %#v", cfg.TLSClientConfig)
+		}
+		if cfg.ExecProvider != nil {
+			t.Fatal("expected no exec provider")
+		}
+	}
+
+	t.Log("named context, exec plugin")
+	{
+		cfg, err := KubeconfigRESTConfig(file, "staging")
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if cfg.Host != "https://staging.example.com" {
+			t.Fatalf("expected staging host, got %s", cfg.Host)
+		}
+		if string(cfg.TLSClientConfig.CAData) != "ca-data" {
+			t.Fatalf("expected ca-data, got %q", string(cfg.TLSClientConfig.CAData))
+		}
+		if cfg.ExecProvider == nil {
+			t.Fatal("expected exec provider")
+		}
+		if cfg.ExecProvider.Command != "aws" || len(cfg.ExecProvider.Args) != 4 {
+			t.Fatalf("expected aws eks get-token, got %s %v", cfg.ExecProvider.Command, cfg.ExecProvider.Args)
+		}
+		if cfg.ExecProvider.APIVersion != "client.authentication.k8s.io/v1beta1" {
+			t.Fatalf("expected v1beta1, got %s", cfg.ExecProvider.APIVersion)
+		}
+		if len(cfg.ExecProvider.Env) != 1 || cfg.ExecProvider.Env[0].Name != "AWS_PROFILE" {
+			t.Fatalf("expected AWS_PROFILE env, got %v", cfg.ExecProvider.Env)
+		}
+	}
+
+	t.Log("unknown context")
+	{
+		if _, err := KubeconfigRESTConfig(file, "missing"); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+
+	t.Log("unknown user")
+	{
+		if _, err := KubeconfigRESTConfig(file, "typo"); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+
+	t.Log("auth provider")
+	{
+		if _, err := KubeconfigRESTConfig(file, "legacy"); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+
+	t.Log("missing file")
+	{
+		if _, err := KubeconfigRESTConfig(filepath.Join(dir, "missing"), ""); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// PodCache is an informer backed cache of pods, used by collectors in place
+// of fetching each pod from the api server on every collection.
+type PodCache struct {
+	informer   cache.SharedIndexInformer
+	lister     corelisters.PodLister
+	lastUpdate int64 // unix nano of last add/update/delete/resync received
+	hits       uint64
+	misses     uint64
+}
+
+// PodCacheStats is a snapshot of the pod cache state
+type PodCacheStats struct {
+	Staleness time.Duration // time since the cache last received an event or resync
+	Pods      int
+	Hits      uint64
+	Misses    uint64
+	Synced    bool
+}
+
+// NewPodCache creates a pod cache using the pod informer of factory. The
+// informer is started with the factory (factory.Start). If the factory
+// filters pods (e.g. by spec.nodeName) so does the cache.
+func NewPodCache(factory informers.SharedInformerFactory) (*PodCache, error) {
+	if factory == nil {
+		return nil, fmt.Errorf("invalid informer factory (nil)")
+	}
+
+	podInformer := factory.Core().V1().Pods()
+
+	pc := &PodCache{
+		informer: podInformer.Informer(),
+		lister:   podInformer.Lister(),
+	}
+
+	// only the pod metadata and container resources are used by collectors,
+	// drop the rest to keep the cache small on large clusters
+	if err := pc.informer.SetTransform(trimPod); err != nil {
+		return nil, fmt.Errorf("setting pod transform: %w", err)
+	}
+
+	pc.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { pc.touch() },
+		UpdateFunc: func(interface{}, interface{}) { pc.touch() },
+		DeleteFunc: func(interface{}) { pc.touch() },
+	})
+
+	return pc, nil
+}
+
+// NewInformerFactory creates a shared informer factory for a cluster, pods
+// are resynced every podResync (bounding how stale the pod cache can appear
+// when there are no pod changes), other informers are not resynced
+func NewInformerFactory(clientset kubernetes.Interface, podResync time.Duration) informers.SharedInformerFactory {
+	return informers.NewSharedInformerFactoryWithOptions(clientset, 0,
+		informers.WithCustomResyncConfig(map[metav1.Object]time.Duration{&v1.Pod{}: podResync}))
+}
+
+// NewNodeInformerFactory creates an informer factory for pods scheduled on
+// nodeName (spec.nodeName field selector), used in node mode so each instance
+// only caches the pods of its own node
+func NewNodeInformerFactory(clientset kubernetes.Interface, podResync time.Duration, nodeName string) informers.SharedInformerFactory {
+	return informers.NewSharedInformerFactoryWithOptions(clientset, 0,
+		informers.WithCustomResyncConfig(map[metav1.Object]time.Duration{&v1.Pod{}: podResync}),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", nodeName).String()
+		}))
+}
+
+// WaitForSync waits for the initial list of pods, returns false if ctx is
+// done before the cache has synced
+func (pc *PodCache) WaitForSync(ctx context.Context) bool {
+	return cache.WaitForCacheSync(ctx.Done(), pc.informer.HasSynced)
+}
+
+// Synced returns true once the initial list of pods has been received
+func (pc *PodCache) Synced() bool {
+	return pc.informer.HasSynced()
+}
+
+// Get returns the cached pod, false if the cache has not synced or the pod is not in the cache
+func (pc *PodCache) Get(namespace, name string) (*v1.Pod, bool) {
+	if !pc.Synced() {
+		atomic.AddUint64(&pc.misses, 1)
+		return nil, false
+	}
+	pod, err := pc.lister.Pods(namespace).Get(name)
+	if err != nil {
+		atomic.AddUint64(&pc.misses, 1)
+		return nil, false
+	}
+	atomic.AddUint64(&pc.hits, 1)
+	return pod, true
+}
+
+// Stats returns the current cache stats, hits and misses are reset
+func (pc *PodCache) Stats() PodCacheStats {
+	stats := PodCacheStats{
+		Synced: pc.Synced(),
+		Pods:   len(pc.informer.GetStore().ListKeys()),
+		Hits:   atomic.SwapUint64(&pc.hits, 0),
+		Misses: atomic.SwapUint64(&pc.misses, 0),
+	}
+	if last := atomic.LoadInt64(&pc.lastUpdate); last > 0 {
+		stats.Staleness = time.Since(time.Unix(0, last))
+	}
+	return stats
+}
+
+func (pc *PodCache) touch() {
+	atomic.StoreInt64(&pc.lastUpdate, time.Now().UnixNano())
+}
+
+func trimPod(obj interface{}) (interface{}, error) {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		return obj, nil // e.g. cache.DeletedFinalStateUnknown
+	}
+
+	trimmed := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            pod.Name,
+			Namespace:       pod.Namespace,
+			UID:             pod.UID,
+			ResourceVersion: pod.ResourceVersion,
+			Labels:          pod.Labels,
+		},
+		Spec: v1.PodSpec{
+			NodeName:   pod.Spec.NodeName,
+			Containers: make([]v1.Container, len(pod.Spec.Containers)),
+		},
+	}
+	for i, c := range pod.Spec.Containers {
+		trimmed.Spec.Containers[i] = v1.Container{Name: c.Name, Resources: c.Resources}
+	}
+
+	return trimmed, nil
+}
@@ -0,0 +1,176 @@
+package k8s
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+)
+
+// tokenCheckInterval is how often a token file is checked for changes, bound service
+// account tokens are rotated by the kubelet well before they expire (at 80% of their ttl)
+const tokenCheckInterval = 10 * time.Second
+
+// TokenSource supplies a bearer token. A token read from a file (e.g. a projected
+// service account token) is re-read when the file changes and when a request
+// using it is rejected as unauthorized, so rotated tokens are picked up by every
+// client sharing the source.
+type TokenSource struct {
+	path    string
+	token   string
+	modTime time.Time
+	size    int64
+	checked time.Time
+	sync.Mutex
+}
+
+var (
+	tokenSourcesMu sync.Mutex
+	tokenSources   = make(map[string]*TokenSource)
+)
+
+// ClusterTokenSource returns the token source for a cluster configuration, a static
+// token or the (shared) source for the token file. It returns nil when a token is not
+// used: none is configured, or a kubeconfig, client certificate or exec plugin is used
+// (the token file defaults to the service account's).
+func ClusterTokenSource(cfg *config.Cluster) (*TokenSource, error) {
+	switch {
+	case cfg.Kubeconfig != "":
+		return nil, nil //nolint:nilnil
+	case cfg.BearerToken != "":
+		return &TokenSource{token: strings.TrimSpace(cfg.BearerToken)}, nil
+	case cfg.BearerTokenFile == "" || cfg.ClientCertFile != "" || cfg.Exec.Command != "":
+		return nil, nil //nolint:nilnil
+	}
+	return TokenFileSource(cfg.BearerTokenFile)
+}
+
+// TokenFileSource returns the token source for a token file, one source is shared by all
+// clients using the file so a rotated token is read once
+func TokenFileSource(path string) (*TokenSource, error) {
+	tokenSourcesMu.Lock()
+	defer tokenSourcesMu.Unlock()
+
+	if ts, ok := tokenSources[path]; ok {
+		return ts, nil
+	}
+
+	ts := &TokenSource{path: path}
+	if err := ts.read(); err != nil {
+		return nil, err
+	}
+	tokenSources[path] = ts
+
+	return ts, nil
+}
+
+// Token returns the current token, re-reading the token file if it has changed
+func (ts *TokenSource) Token() string {
+	ts.Lock()
+	defer ts.Unlock()
+
+	if ts.path != "" && time.Since(ts.checked) >= tokenCheckInterval {
+		ts.checked = time.Now()
+		if fi, err := os.Stat(ts.path); err == nil && (!fi.ModTime().Equal(ts.modTime) || fi.Size() != ts.size) {
+			_ = ts.read() // keep the current token if the new one cannot be read
+		}
+	}
+
+	return ts.token
+}
+
+// Refresh re-reads the token file after a request with the rejected token was unauthorized,
+// it returns true if the token changed
+func (ts *TokenSource) Refresh(rejected string) bool {
+	if ts.path == "" {
+		return false
+	}
+
+	ts.Lock()
+	defer ts.Unlock()
+
+	if ts.token != rejected {
+		return true // already refreshed (e.g. by a concurrent request)
+	}
+	if err := ts.read(); err != nil {
+		return false
+	}
+	return ts.token != rejected
+}
+
+// read reads the token file, must be called with the source locked (or before it is shared)
+func (ts *TokenSource) read() error {
+	fi, err := os.Stat(ts.path)
+	if err != nil {
+		return fmt.Errorf("bearer token file: %w", err)
+	}
+	data, err := os.ReadFile(ts.path)
+	if err != nil {
+		return fmt.Errorf("bearer token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return fmt.Errorf("bearer token file %s is empty", ts.path)
+	}
+
+	ts.token = token
+	ts.modTime = fi.ModTime()
+	ts.size = fi.Size()
+	ts.checked = time.Now()
+
+	return nil
+}
+
+// WrapTransport returns a round tripper which adds the token to requests, a request
+// rejected as unauthorized is retried once if the token file has a new token
+func (ts *TokenSource) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &tokenTransport{source: ts, base: rt}
+}
+
+type tokenTransport struct {
+	source *TokenSource
+	base   http.RoundTripper
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	token := t.source.Token()
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := t.base.RoundTrip(r)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if !t.source.Refresh(token) {
+		return resp, nil
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil // the body cannot be replayed, the next request uses the new token
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil //nolint:nilerr // return the unauthorized response
+		}
+		retry.Body = body
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	retry.Header.Set("Authorization", "Bearer "+t.source.Token())
+	return t.base.RoundTrip(retry)
+}
+
+// WrappedRoundTripper returns the base round tripper (used by client-go to cancel requests)
+func (t *tokenTransport) WrappedRoundTripper() http.RoundTripper { return t.base }
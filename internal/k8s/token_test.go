@@ -0,0 +1,123 @@
+package k8s
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+)
+
+func TestTokenSource(t *testing.T) {
+	t.Log("Testing TokenSource")
+
+	file := filepath.Join(t.TempDir(), "token")
+	write := func(token string, mtime time.Time) {
+		if err := os.WriteFile(file, []byte(token+"\n"), 0600); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if err := os.Chtimes(file, mtime, mtime); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+	}
+	now := time.Now()
+	write("token-1", now.Add(-time.Hour))
+
+	t.Log("cluster token sources")
+	{
+		ts, err := ClusterTokenSource(&config.Cluster{BearerToken: "static"})
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if ts.Token() != "static" {
+			t.Fatalf("expected static, got %s", ts.Token())
+		}
+		ts, err = ClusterTokenSource(&config.Cluster{BearerTokenFile: file, Exec: config.Exec{Command: "plugin"}})
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if ts != nil {
+			t.Fatal("expected no token source with exec credentials")
+		}
+		if _, err := ClusterTokenSource(&config.Cluster{BearerTokenFile: filepath.Join(t.TempDir(), "missing")}); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+
+	ts, err := ClusterTokenSource(&config.Cluster{BearerTokenFile: file})
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if ts.Token() != "token-1" {
+		t.Fatalf("expected token-1, got %s", ts.Token())
+	}
+	if shared, _ := TokenFileSource(file); shared != ts {
+		t.Fatal("expected shared token source")
+	}
+
+	t.Log("file changed")
+	{
+		write("token-2", now.Add(-time.Minute))
+		ts.Lock()
+		ts.checked = time.Time{}
+		ts.Unlock()
+		if ts.Token() != "token-2" {
+			t.Fatalf("expected token-2, got %s", ts.Token())
+		}
+	}
+
+	t.Log("unauthorized, retried with rotated token")
+	{
+		var requests int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			if r.Header.Get("Authorization") != "Bearer token-3" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+
+		// rotated, but not yet noticed (checked recently)
+		write("token-3", now)
+
+		client := &http.Client{Transport: ts.WrapTransport(http.DefaultTransport)}
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d", resp.StatusCode)
+		}
+		if n := atomic.LoadInt32(&requests); n != 2 {
+			t.Fatalf("expected 2 requests, got %d", n)
+		}
+		if ts.Token() != "token-3" {
+			t.Fatalf("expected token-3, got %s", ts.Token())
+		}
+	}
+
+	t.Log("unauthorized, token unchanged")
+	{
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer srv.Close()
+
+		client := &http.Client{Transport: ts.WrapTransport(http.DefaultTransport)}
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("expected 401, got %d", resp.StatusCode)
+		}
+	}
+}
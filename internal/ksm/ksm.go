@@ -21,17 +21,18 @@ import (
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/circonus"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config/defaults"
-	"github.com/circonus-labs/circonus-kubernetes-agent/internal/k8s"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/promtext"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/release"
 	"github.com/pkg/errors"
-	"github.com/prometheus/common/expfmt"
 	"github.com/rs/zerolog"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 type KSM struct {
 	sync.Mutex
 	check        *circonus.Check
+	clientset    kubernetes.Interface
 	cgmMetrics   *cgm.CirconusMetrics
 	config       *config.Cluster
 	ts           *time.Time
@@ -59,18 +60,22 @@ type Address struct {
 // for "direct" mode:
 //   use service endpoint w/ports (configured for each port name)
 
-func New(cfg *config.Cluster, parentLogger zerolog.Logger, check *circonus.Check) (*KSM, error) {
+func New(cfg *config.Cluster, parentLogger zerolog.Logger, check *circonus.Check, clientset kubernetes.Interface) (*KSM, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid cluster config (nil)")
 	}
 	if check == nil {
 		return nil, errors.New("invalid check (nil)")
 	}
+	if clientset == nil {
+		return nil, errors.New("invalid clientset (nil)")
+	}
 
 	ksm := &KSM{
-		config: cfg,
-		check:  check,
-		log:    parentLogger.With().Str("collector", "kube-state-metrics").Logger(),
+		config:    cfg,
+		check:     check,
+		clientset: clientset,
+		log:       parentLogger.With().Str("collector", "kube-state-metrics").Logger(),
 	}
 
 	if cfg.APITimelimit != "" {
@@ -205,12 +210,7 @@ func (ksm *KSM) getEndpointTargets(ctx context.Context) ([]Address, error) {
 		return nil, err
 	}
 
-	clientset, err := k8s.GetClient(ksm.config)
-	if err != nil {
-		return nil, err
-	}
-
-	endpoints, err := clientset.CoreV1().Endpoints("").List(ctx, metav1.ListOptions{FieldSelector: ksm.config.KSMFieldSelectorQuery})
+	endpoints, err := ksm.clientset.CoreV1().Endpoints("").List(ctx, metav1.ListOptions{FieldSelector: ksm.config.KSMFieldSelectorQuery})
 	if err != nil {
 		return nil, err
 	}
@@ -271,6 +271,7 @@ func (ksm *KSM) getMetrics(ctx context.Context, metricURL string) error {
 		return errors.Wrap(err, "/metrics req")
 	}
 	req.Header.Add("User-Agent", release.NAME+"/"+release.VERSION)
+	req.Header.Add("Accept", promtext.AcceptHeader)
 	defer client.CloseIdleConnections()
 	resp, err := client.Do(req)
 	if err != nil {
@@ -313,8 +314,7 @@ func (ksm *KSM) getMetrics(ctx context.Context, metricURL string) error {
 	}
 	measurementTags := []string{}
 
-	var parser expfmt.TextParser
-	if err := ksm.queueMetrics(ctx, metricURL+" - metrics", parser, ksm.check, data, streamTags, measurementTags); err != nil {
+	if err := ksm.queueMetrics(ctx, metricURL+" - metrics", promtext.ResponseFormat(resp.Header), ksm.check, data, streamTags, measurementTags); err != nil {
 		return err
 	}
 
@@ -5,8 +5,68 @@
 
 package ksm
 
-import "testing"
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/circonus"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/k8s"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/testsupport"
+	"github.com/rs/zerolog"
+)
 
 func Test(t *testing.T) {
 	t.Log("Placeholder...nothing to test currently")
 }
+
+func TestCollect(t *testing.T) {
+	t.Log("Testing Collect")
+
+	kube := testsupport.NewKubeAPI("v1.24.3")
+	defer kube.Close()
+	defer k8s.SetClientFactory(kube.NewClient)()
+
+	api, err := testsupport.NewAPI()
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	defer api.Close()
+
+	body, err := os.ReadFile(filepath.Join("testdata", "metrics.txt"))
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if _, err := kube.AddMetricsEndpoint("kube-system", "kube-state-metrics", "http-metrics", body); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	clusterCfg := kube.ClusterConfig("test-cluster")
+	clusterCfg.KSMFieldSelectorQuery = "metadata.name=kube-state-metrics"
+	clusterCfg.KSMMetricsPortName = "http-metrics"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	check, err := circonus.NewCheck(ctx, zerolog.Nop(), api.CirconusConfig("test-cluster"), clusterCfg)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	clientset, err := kube.NewClient(clusterCfg)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	k, err := New(clusterCfg, zerolog.Nop(), check, clientset)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	ts := time.Now()
+	k.Collect(ctx, nil, &ts)
+
+	testsupport.Golden(t, "ksm.golden", testsupport.MetricNames(api.Broker.Metrics()))
+}
@@ -14,6 +14,7 @@ import (
 
 	cgm "github.com/circonus-labs/circonus-gometrics/v3"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/circonus"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/promtext"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/expfmt"
 )
@@ -26,13 +27,13 @@ const (
 	circCumulativeHistogram = true
 )
 
-// queueMetrics is a generic function to digest prometheus text format metrics and
-// emit circonus formatted metrics.
+// queueMetrics is a generic function to digest prometheus metrics (text, OpenMetrics
+// or protobuf format) and emit circonus formatted metrics.
 // Formats supported: https://prometheus.io/docs/instrumenting/exposition_formats/
 func (ksm *KSM) queueMetrics(
 	ctx context.Context,
 	ksmSource string,
-	parser expfmt.TextParser,
+	format expfmt.Format,
 	check *circonus.Check,
 	data io.Reader,
 	parentStreamTags []string,
@@ -46,7 +47,7 @@ func (ksm *KSM) queueMetrics(
 		copy(baseStreamTags, parentStreamTags)
 	}
 
-	metricFamilies, err := parser.TextToMetricFamilies(data)
+	metricFamilies, units, err := promtext.Parse(data, format)
 	if err != nil {
 		return err
 	}
@@ -64,14 +65,17 @@ func (ksm *KSM) queueMetrics(
 			}
 			metricName := mn
 			streamTags := check.NewTagList(baseStreamTags, getLabels(m))
+			if unit, ok := units[mn]; ok {
+				streamTags = check.NewTagList(streamTags, []string{"units:" + unit})
+			}
 			switch mf.GetType() {
 			case dto.MetricType_SUMMARY:
-				_ = check.QueueMetricSample(
+				_ = check.QueueCounterSample(
 					metrics, metricName+"_count",
 					circonus.MetricTypeUint64,
 					streamTags, parentMeasurementTags,
 					m.GetSummary().GetSampleCount(), ksm.ts)
-				_ = check.QueueMetricSample(
+				_ = check.QueueCounterSample(
 					metrics, metricName+"_sum",
 					circonus.MetricTypeFloat64,
 					streamTags, parentMeasurementTags,
@@ -85,12 +89,12 @@ func (ksm *KSM) queueMetrics(
 						qv, ksm.ts)
 				}
 			case dto.MetricType_HISTOGRAM:
-				_ = check.QueueMetricSample(
+				_ = check.QueueCounterSample(
 					metrics, metricName+"_count",
 					circonus.MetricTypeUint64,
 					streamTags, parentMeasurementTags,
 					m.GetHistogram().GetSampleCount(), ksm.ts)
-				_ = check.QueueMetricSample(
+				_ = check.QueueCounterSample(
 					metrics, metricName+"_sum",
 					circonus.MetricTypeFloat64,
 					streamTags, parentMeasurementTags,
@@ -115,7 +119,7 @@ func (ksm *KSM) queueMetrics(
 					} else {
 						for bn, bv := range getBuckets(m) {
 							htags := check.NewTagList(streamTags, []string{"bucket:" + bn})
-							_ = check.QueueMetricSample(
+							_ = check.QueueCounterSample(
 								metrics, metricName,
 								circonus.MetricTypeUint64,
 								htags, parentMeasurementTags,
@@ -202,7 +206,7 @@ func (ksm *KSM) queueMetrics(
 				}
 			case dto.MetricType_COUNTER:
 				if m.GetCounter().Value != nil {
-					_ = check.QueueMetricSample(
+					_ = check.QueueCounterSample(
 						metrics, metricName,
 						circonus.MetricTypeFloat64,
 						streamTags, parentMeasurementTags,
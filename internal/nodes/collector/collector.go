@@ -17,19 +17,24 @@ import (
 	cgm "github.com/circonus-labs/circonus-gometrics/v3"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/circonus"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
-	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config/keys"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config/defaults"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/k8s"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/release"
 	"github.com/hashicorp/go-version"
 	"github.com/rs/zerolog"
-	"github.com/spf13/viper"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 type Collector struct {
 	ctx           context.Context
 	tlsConfig     *tls.Config
 	check         *circonus.Check
+	clientset     kubernetes.Interface
+	pods          *k8s.PodCache
+	kubelet       *Kubelet
 	node          *v1.Node
 	kubeletVer    *version.Version
 	ts            *time.Time
@@ -41,7 +46,10 @@ type Collector struct {
 	apiTimelimit  time.Duration
 }
 
-func New(cfg *config.Cluster, node *v1.Node, logger zerolog.Logger, check *circonus.Check, apiTimeout time.Duration) (*Collector, error) {
+// New creates a node collector, pods (optional) is used in place of fetching
+// pod specs from the api server for each pod on the node and kubelet (optional)
+// is used to request kubelet endpoints directly rather than via the api-server proxy
+func New(cfg *config.Cluster, node *v1.Node, logger zerolog.Logger, check *circonus.Check, clientset kubernetes.Interface, pods *k8s.PodCache, kubelet *Kubelet, apiTimeout time.Duration) (*Collector, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("invalid cluster config (nil)")
 	}
@@ -51,10 +59,16 @@ func New(cfg *config.Cluster, node *v1.Node, logger zerolog.Logger, check *circo
 	if check == nil {
 		return nil, fmt.Errorf("invalid check (nil)")
 	}
+	if clientset == nil {
+		return nil, fmt.Errorf("invalid clientset (nil)")
+	}
 
 	c := &Collector{
 		cfg:          *cfg, // make sure it's a copy
 		check:        check,
+		clientset:    clientset,
+		pods:         pods,
+		kubelet:      kubelet,
 		node:         node,
 		apiTimelimit: apiTimeout,
 		baseLogger:   logger.With().Str("node", node.Name).Logger(),
@@ -71,7 +85,10 @@ func New(cfg *config.Cluster, node *v1.Node, logger zerolog.Logger, check *circo
 		return nil, fmt.Errorf("parsing version (%s): %w", ver, err)
 	}
 
-	minVer := viper.GetString(keys.K8SNodeKubeletVersion)
+	minVer := cfg.NodeKubletVersion
+	if minVer == "" {
+		minVer = defaults.K8SNodeKubeletVersion
+	}
 	vc, err := version.NewConstraint(">= " + minVer)
 	if err != nil {
 		return nil, fmt.Errorf("parsing ver constraint: %w", err)
@@ -412,6 +429,18 @@ func (nc *Collector) resourceMetrics(metrics map[string]circonus.MetricSample, p
 	}
 }
 
+// getPod returns the pod from the pod cache, falling back to the api server when
+// there is no cache, it has not synced or it does not (yet) have the pod
+func (nc *Collector) getPod(namespace, name string) (*v1.Pod, error) {
+	if nc.pods != nil {
+		if pod, ok := nc.pods.Get(namespace, name); ok {
+			return pod, nil
+		}
+	}
+
+	return nc.clientset.CoreV1().Pods(namespace).Get(nc.ctx, name, metav1.GetOptions{})
+}
+
 func (nc *Collector) getPodLabels(pod *v1.Pod) (bool, []string) {
 	collect := true
 	if nc.cfg.PodLabelKey != "" {
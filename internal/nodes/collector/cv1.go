@@ -11,9 +11,7 @@ import (
 
 	cgm "github.com/circonus-labs/circonus-gometrics/v3"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/circonus"
-	"github.com/circonus-labs/circonus-kubernetes-agent/internal/k8s"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/release"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // collector v1 methods (for k8s < v1.20)
@@ -28,24 +26,15 @@ func (nc *Collector) summary(parentStreamTags []string, parentMeasurementTags []
 	logger := nc.log.With().Str("type", "/stats/summary").Logger()
 	logger.Debug().Msg("start")
 
-	clientset, err := k8s.GetClient(&nc.cfg)
-	if err != nil {
-		logger.Error().Err(err).Msg("initializing client set for stats/summary, abandoning collection")
-		logger.Debug().Str("duration", time.Since(start).String()).Msg("complete")
-		return
-	}
-
-	req := clientset.CoreV1().RESTClient().Get().RequestURI(nc.baseURI + "/proxy/stats/summary")
-	res := req.Do(nc.ctx)
-	data, err := res.Raw()
+	data, proxy, err := nc.kubeletGet("/stats/summary")
 	if err != nil {
 		nc.check.IncrementCounter("collect_api_errors", cgm.Tags{
 			cgm.Tag{Category: "source", Value: release.NAME},
 			cgm.Tag{Category: "request", Value: "stats/summary"},
-			cgm.Tag{Category: "proxy", Value: "api-server"},
+			cgm.Tag{Category: "proxy", Value: proxy},
 			cgm.Tag{Category: "target", Value: "kubelet"},
 		})
-		logger.Error().Err(err).Str("k8s_ver", nc.kubeletVer.String()).Str("url", req.URL().String()).Msg("fetching stats/summary stats")
+		logger.Error().Err(err).Str("k8s_ver", nc.kubeletVer.String()).Msg("fetching stats/summary stats")
 		logger.Debug().Str("duration", time.Since(start).String()).Msg("complete")
 		return
 	}
@@ -53,7 +42,7 @@ func (nc *Collector) summary(parentStreamTags []string, parentMeasurementTags []
 	nc.check.AddHistSample("collect_latency", cgm.Tags{
 		cgm.Tag{Category: "source", Value: release.NAME},
 		cgm.Tag{Category: "request", Value: "stats/summary"},
-		cgm.Tag{Category: "proxy", Value: "api-server"},
+		cgm.Tag{Category: "proxy", Value: proxy},
 		cgm.Tag{Category: "target", Value: "kubelet"},
 		cgm.Tag{Category: "units", Value: "milliseconds"},
 	}, float64(time.Since(start).Milliseconds()))
@@ -170,20 +159,12 @@ func (nc *Collector) summaryPods(stats *statsSummary, parentStreamTags []string,
 
 	metrics := make(map[string]circonus.MetricSample)
 
-	// clientset
-	clientset, err := k8s.GetClient(&nc.cfg)
-	if err != nil {
-		logger.Error().Err(err).Msg("initializing client set for pods, abandoning collection")
-		logger.Debug().Str("duration", time.Since(start).String()).Msg("complete")
-		return
-	}
-
 	for _, pod := range stats.Pods {
 		pod := pod
 		if nc.done() {
 			break
 		}
-		podSpec, err := clientset.CoreV1().Pods(pod.PodRef.Namespace).Get(nc.ctx, pod.PodRef.Name, metav1.GetOptions{})
+		podSpec, err := nc.getPod(pod.PodRef.Namespace, pod.PodRef.Name)
 		if err != nil {
 			nc.check.IncrementCounter("collect_api_errors", cgm.Tags{
 				cgm.Tag{Category: "source", Value: release.NAME},
@@ -10,7 +10,6 @@ import (
 	"time"
 
 	cgm "github.com/circonus-labs/circonus-gometrics/v3"
-	"github.com/circonus-labs/circonus-kubernetes-agent/internal/k8s"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/promtext"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/release"
 	"github.com/prometheus/common/expfmt"
@@ -30,24 +29,15 @@ func (nc *Collector) resources(parentStreamTags []string, parentMeasurementTags
 	logger := nc.log.With().Str("type", "/metrics/resource").Logger()
 	logger.Debug().Msg("start")
 
-	clientset, err := k8s.GetClient(&nc.cfg)
-	if err != nil {
-		logger.Error().Err(err).Msg("initializing client set for node resource metrics, abandoning collection")
-		logger.Debug().Str("duration", time.Since(start).String()).Msg("complete")
-		return
-	}
-
-	req := clientset.CoreV1().RESTClient().Get().RequestURI(nc.baseURI + "/proxy/metrics/resource")
-	res := req.Do(nc.ctx)
-	data, err := res.Raw()
+	data, proxy, err := nc.kubeletGet("/metrics/resource")
 	if err != nil {
 		nc.check.IncrementCounter("collect_api_errors", cgm.Tags{
 			cgm.Tag{Category: "source", Value: release.NAME},
 			cgm.Tag{Category: "request", Value: "metrics/resource"},
-			cgm.Tag{Category: "proxy", Value: "api-server"},
+			cgm.Tag{Category: "proxy", Value: proxy},
 			cgm.Tag{Category: "target", Value: "kubelet"},
 		})
-		logger.Error().Err(err).Str("url", req.URL().String()).Msg("fetching /metrics/resource")
+		logger.Error().Err(err).Msg("fetching /metrics/resource")
 		logger.Debug().Str("duration", time.Since(start).String()).Msg("complete")
 		return
 	}
@@ -55,7 +45,7 @@ func (nc *Collector) resources(parentStreamTags []string, parentMeasurementTags
 	nc.check.AddHistSample("collect_latency", cgm.Tags{
 		cgm.Tag{Category: "source", Value: release.NAME},
 		cgm.Tag{Category: "request", Value: "metrics/resource"},
-		cgm.Tag{Category: "proxy", Value: "api-server"},
+		cgm.Tag{Category: "proxy", Value: proxy},
 		cgm.Tag{Category: "target", Value: "kubelet"},
 		cgm.Tag{Category: "units", Value: "milliseconds"},
 	}, float64(time.Since(start).Milliseconds()))
@@ -77,24 +67,15 @@ func (nc *Collector) probes(parentStreamTags []string, parentMeasurementTags []s
 	logger := nc.log.With().Str("type", "/metrics/probes").Logger()
 	logger.Debug().Msg("start")
 
-	clientset, err := k8s.GetClient(&nc.cfg)
-	if err != nil {
-		logger.Error().Err(err).Msg("initializing client set for node probe metrics, abandoning collection")
-		logger.Debug().Str("duration", time.Since(start).String()).Msg("complete")
-		return
-	}
-
-	req := clientset.CoreV1().RESTClient().Get().RequestURI(nc.baseURI + "/proxy/metrics/probes")
-	res := req.Do(nc.ctx)
-	data, err := res.Raw()
+	data, proxy, err := nc.kubeletGet("/metrics/probes")
 	if err != nil {
 		nc.check.IncrementCounter("collect_api_errors", cgm.Tags{
 			cgm.Tag{Category: "source", Value: release.NAME},
 			cgm.Tag{Category: "request", Value: "metrics/probes"},
-			cgm.Tag{Category: "proxy", Value: "api-server"},
+			cgm.Tag{Category: "proxy", Value: proxy},
 			cgm.Tag{Category: "target", Value: "kubelet"},
 		})
-		logger.Error().Err(err).Str("url", req.URL().String()).Msg("fetching /metrics/probes")
+		logger.Error().Err(err).Msg("fetching /metrics/probes")
 		logger.Debug().Str("duration", time.Since(start).String()).Msg("complete")
 		return
 	}
@@ -102,7 +83,7 @@ func (nc *Collector) probes(parentStreamTags []string, parentMeasurementTags []s
 	nc.check.AddHistSample("collect_latency", cgm.Tags{
 		cgm.Tag{Category: "source", Value: release.NAME},
 		cgm.Tag{Category: "request", Value: "metrics/probes"},
-		cgm.Tag{Category: "proxy", Value: "api-server"},
+		cgm.Tag{Category: "proxy", Value: proxy},
 		cgm.Tag{Category: "target", Value: "kubelet"},
 		cgm.Tag{Category: "units", Value: "milliseconds"},
 	}, float64(time.Since(start).Milliseconds()))
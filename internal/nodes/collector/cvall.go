@@ -11,7 +11,6 @@ import (
 
 	cgm "github.com/circonus-labs/circonus-gometrics/v3"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/circonus"
-	"github.com/circonus-labs/circonus-kubernetes-agent/internal/k8s"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/promtext"
 	"github.com/circonus-labs/circonus-kubernetes-agent/internal/release"
 	"github.com/prometheus/common/expfmt"
@@ -171,24 +170,15 @@ func (nc *Collector) nmetrics(parentStreamTags []string, parentMeasurementTags [
 	logger := nc.log.With().Str("type", "/metrics").Logger()
 	logger.Debug().Msg("start")
 
-	clientset, err := k8s.GetClient(&nc.cfg)
-	if err != nil {
-		logger.Error().Err(err).Msg("initializing client set for node metrics, abandoning collection")
-		logger.Debug().Str("duration", time.Since(start).String()).Msg("complete")
-		return
-	}
-
-	req := clientset.CoreV1().RESTClient().Get().RequestURI(nc.baseURI + "/proxy/metrics")
-	res := req.Do(nc.ctx)
-	data, err := res.Raw()
+	data, proxy, err := nc.kubeletGet("/metrics")
 	if err != nil {
 		nc.check.IncrementCounter("collect_api_errors", cgm.Tags{
 			cgm.Tag{Category: "source", Value: release.NAME},
 			cgm.Tag{Category: "request", Value: "metrics"},
-			cgm.Tag{Category: "proxy", Value: "api-server"},
+			cgm.Tag{Category: "proxy", Value: proxy},
 			cgm.Tag{Category: "target", Value: "kubelet"},
 		})
-		logger.Error().Err(err).Str("url", req.URL().String()).Msg("fetching /metrics stats")
+		logger.Error().Err(err).Msg("fetching /metrics stats")
 		logger.Debug().Str("duration", time.Since(start).String()).Msg("complete")
 		return
 	}
@@ -196,7 +186,7 @@ func (nc *Collector) nmetrics(parentStreamTags []string, parentMeasurementTags [
 	nc.check.AddHistSample("collect_latency", cgm.Tags{
 		cgm.Tag{Category: "source", Value: release.NAME},
 		cgm.Tag{Category: "request", Value: "metrics"},
-		cgm.Tag{Category: "proxy", Value: "api-server"},
+		cgm.Tag{Category: "proxy", Value: proxy},
 		cgm.Tag{Category: "target", Value: "kubelet"},
 		cgm.Tag{Category: "units", Value: "milliseconds"},
 	}, float64(time.Since(start).Milliseconds()))
@@ -218,24 +208,15 @@ func (nc *Collector) cadvisor(parentStreamTags []string, parentMeasurementTags [
 	logger := nc.log.With().Str("type", "/metrics/cadvisor").Logger()
 	logger.Debug().Msg("start")
 
-	clientset, err := k8s.GetClient(&nc.cfg)
-	if err != nil {
-		logger.Error().Err(err).Msg("initializing client set for cadvisor metrics, abandoning collection")
-		logger.Debug().Str("duration", time.Since(start).String()).Msg("complete")
-		return
-	}
-
-	req := clientset.CoreV1().RESTClient().Get().RequestURI(nc.baseURI + "/proxy/metrics/cadvisor")
-	res := req.Do(nc.ctx)
-	data, err := res.Raw()
+	data, proxy, err := nc.kubeletGet("/metrics/cadvisor")
 	if err != nil {
 		nc.check.IncrementCounter("collect_api_errors", cgm.Tags{
 			cgm.Tag{Category: "source", Value: release.NAME},
 			cgm.Tag{Category: "request", Value: "metrics/cadvisor"},
-			cgm.Tag{Category: "proxy", Value: "api-server"},
+			cgm.Tag{Category: "proxy", Value: proxy},
 			cgm.Tag{Category: "target", Value: "kubelet"},
 		})
-		logger.Error().Err(err).Str("url", req.URL().String()).Msg("fetching /metrics/cadvisor stats")
+		logger.Error().Err(err).Msg("fetching /metrics/cadvisor stats")
 		logger.Debug().Str("duration", time.Since(start).String()).Msg("complete")
 		return
 	}
@@ -243,7 +224,7 @@ func (nc *Collector) cadvisor(parentStreamTags []string, parentMeasurementTags [
 	nc.check.AddHistSample("collect_latency", cgm.Tags{
 		cgm.Tag{Category: "source", Value: release.NAME},
 		cgm.Tag{Category: "request", Value: "metrics/cadvisor"},
-		cgm.Tag{Category: "proxy", Value: "api-server"},
+		cgm.Tag{Category: "proxy", Value: proxy},
 		cgm.Tag{Category: "target", Value: "kubelet"},
 		cgm.Tag{Category: "units", Value: "milliseconds"},
 	}, float64(time.Since(start).Milliseconds()))
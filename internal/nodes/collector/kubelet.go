@@ -0,0 +1,193 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package collector
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	cgm "github.com/circonus-labs/circonus-gometrics/v3"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/k8s"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/release"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// RequestModeProxy requests kubelet endpoints through the api-server node proxy
+	RequestModeProxy = "proxy"
+	// RequestModeDirect requests kubelet endpoints directly from the kubelet on each node
+	RequestModeDirect = "direct"
+
+	// how long a node which failed a direct request uses the api-server proxy before direct is tried again
+	directRetryInterval = 10 * time.Minute
+)
+
+// Kubelet makes requests directly to the kubelet on each node (node request mode 'direct'),
+// nodes where a direct request fails fall back to the api-server proxy for a period of time.
+type Kubelet struct {
+	client    *http.Client
+	port      int
+	fallbacks map[string]time.Time
+	sync.Mutex
+}
+
+// NewKubelet creates a kubelet client using the cluster bearer (service account) token,
+// re-read when the token file is rotated. The kubelet serving certificate is verified with the kubelet CA file (or the api CA
+// file if a kubelet CA is not configured), unless insecure is enabled.
+func NewKubelet(cfg *config.Cluster) (*Kubelet, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("invalid cluster config (nil)")
+	}
+	ts, err := k8s.ClusterTokenSource(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if ts == nil {
+		return nil, fmt.Errorf("invalid bearer token (empty)")
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	switch {
+	case cfg.KubeletInsecure:
+		tlsConfig.InsecureSkipVerify = true //nolint:gosec // explicitly configured
+	case cfg.KubeletCAFile != "" || cfg.CAFile != "":
+		caFile := cfg.KubeletCAFile
+		if caFile == "" {
+			caFile = cfg.CAFile
+		}
+		cert, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading kubelet CA file: %w", err)
+		}
+		cp := x509.NewCertPool()
+		if !cp.AppendCertsFromPEM(cert) {
+			return nil, fmt.Errorf("unable to add kubelet CA certificate (%s) to x509 cert pool", caFile)
+		}
+		tlsConfig.RootCAs = cp
+	}
+
+	port := cfg.KubeletPort
+	if port == 0 {
+		port = 10250
+	}
+
+	return &Kubelet{
+		client: &http.Client{
+			Transport: ts.WrapTransport(&http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				DialContext: (&net.Dialer{
+					Timeout:   10 * time.Second,
+					KeepAlive: 30 * time.Second,
+				}).DialContext,
+				TLSClientConfig:     tlsConfig,
+				TLSHandshakeTimeout: 10 * time.Second,
+				MaxIdleConnsPerHost: 2,
+				IdleConnTimeout:     90 * time.Second,
+			}),
+		},
+		port:      port,
+		fallbacks: make(map[string]time.Time),
+	}, nil
+}
+
+// useDirect returns true if the node has not recently fallen back to the api-server proxy
+func (k *Kubelet) useDirect(nodeName string) bool {
+	k.Lock()
+	defer k.Unlock()
+	if since, ok := k.fallbacks[nodeName]; ok {
+		if time.Since(since) < directRetryInterval {
+			return false
+		}
+		delete(k.fallbacks, nodeName)
+	}
+	return true
+}
+
+// fallback switches the node to the api-server proxy for directRetryInterval
+func (k *Kubelet) fallback(nodeName string) {
+	k.Lock()
+	k.fallbacks[nodeName] = time.Now()
+	k.Unlock()
+}
+
+// get requests path (e.g. /metrics/cadvisor) from the kubelet on node
+func (k *Kubelet) get(ctx context.Context, node *v1.Node, path string) ([]byte, error) {
+	addr := ""
+	for _, a := range node.Status.Addresses {
+		if a.Type == v1.NodeInternalIP {
+			addr = a.Address
+			break
+		}
+	}
+	if addr == "" {
+		return nil, fmt.Errorf("no InternalIP address for node")
+	}
+
+	port := k.port
+	if p := node.Status.DaemonEndpoints.KubeletEndpoint.Port; p > 0 {
+		port = int(p)
+	}
+
+	reqURL := "https://" + net.JoinHostPort(addr, strconv.Itoa(port)) + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", release.NAME+"/"+release.VERSION)
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", reqURL, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", reqURL, resp.Status)
+	}
+
+	return data, nil
+}
+
+// kubeletGet requests path (e.g. /metrics/cadvisor) from the node's kubelet, directly when
+// a kubelet client is configured and the node has not fallen back, otherwise via the
+// api-server proxy. It returns the data and the proxy used (none or api-server).
+func (nc *Collector) kubeletGet(path string) ([]byte, string, error) {
+	if nc.kubelet != nil && nc.kubelet.useDirect(nc.node.Name) {
+		data, err := nc.kubelet.get(nc.ctx, nc.node, path)
+		if err == nil {
+			return data, "none", nil
+		}
+		if nc.ctx.Err() != nil {
+			return nil, "none", err
+		}
+		nc.kubelet.fallback(nc.node.Name)
+		nc.check.IncrementCounter("collect_kubelet_direct_fallbacks", cgm.Tags{
+			cgm.Tag{Category: "source", Value: release.NAME},
+			cgm.Tag{Category: "node", Value: nc.node.Name},
+		})
+		nc.log.Warn().Err(err).Str("path", path).Str("retry_in", directRetryInterval.String()).Msg("direct kubelet request failed, falling back to api-server proxy")
+	}
+
+	data, err := nc.clientset.CoreV1().RESTClient().Get().RequestURI(nc.baseURI + "/proxy" + path).Do(nc.ctx).Raw()
+	if err != nil {
+		return nil, "api-server", fmt.Errorf("%s/proxy%s: %w", nc.baseURI, path, err)
+	}
+	return data, "api-server", nil
+}
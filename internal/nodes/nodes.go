@@ -23,31 +23,43 @@ import (
 	"github.com/rs/zerolog"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 type Nodes struct {
 	sync.Mutex
 	config       *config.Cluster
 	check        *circonus.Check
+	clientset    kubernetes.Interface
+	pods         *k8s.PodCache
+	kubelet      *collector.Kubelet
 	log          zerolog.Logger
 	apiTimelimit time.Duration
 	nodeCC       bool
 	running      bool
 }
 
-func New(cfg *config.Cluster, parentLog zerolog.Logger, check *circonus.Check, nodeCC bool) (*Nodes, error) {
+// New creates a nodes collector, pods is the (optional) pod cache used for pod labels and resource specs,
+// kubelet is the (optional) client used for direct kubelet requests (node request mode 'direct')
+func New(cfg *config.Cluster, parentLog zerolog.Logger, check *circonus.Check, clientset kubernetes.Interface, pods *k8s.PodCache, kubelet *collector.Kubelet, nodeCC bool) (*Nodes, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid cluster config (nil)")
 	}
 	if check == nil {
 		return nil, errors.New("invalid check (nil)")
 	}
+	if clientset == nil {
+		return nil, errors.New("invalid clientset (nil)")
+	}
 
 	nodes := &Nodes{
-		config: cfg,
-		check:  check,
-		nodeCC: nodeCC,
-		log:    parentLog.With().Str("pkg", "nodes").Logger(),
+		config:    cfg,
+		check:     check,
+		clientset: clientset,
+		pods:      pods,
+		kubelet:   kubelet,
+		nodeCC:    nodeCC,
+		log:       parentLog.With().Str("pkg", "nodes").Logger(),
 	}
 
 	if cfg.APITimelimit != "" {
@@ -130,7 +142,9 @@ func (n *Nodes) Collect(ctx context.Context, tlsConfig *tls.Config, ts *time.Tim
 		}(nodeQueue, id)
 	}
 
-	n.check.AddGauge("collect_k8s_node_count", cgm.Tags{cgm.Tag{Category: "source", Value: release.NAME}}, len(nodes.Items))
+	if n.config.Mode != config.ModeNode {
+		n.check.AddGauge("collect_k8s_node_count", cgm.Tags{cgm.Tag{Category: "source", Value: release.NAME}}, len(nodes.Items))
+	}
 	nodesQueued := 0
 	for _, node := range nodes.Items {
 		node := node
@@ -139,7 +153,7 @@ func (n *Nodes) Collect(ctx context.Context, tlsConfig *tls.Config, ts *time.Tim
 				continue
 			}
 			if cond.Status == v1.ConditionTrue {
-				nc, err := collector.New(n.config, &node, n.log, n.check, n.apiTimelimit)
+				nc, err := collector.New(n.config, &node, n.log, n.check, n.clientset, n.pods, n.kubelet, n.apiTimelimit)
 				if err != nil {
 					n.log.Error().Err(err).Str("node", node.Name).Msg("skipping...")
 					break
@@ -155,6 +169,8 @@ func (n *Nodes) Collect(ctx context.Context, tlsConfig *tls.Config, ts *time.Tim
 	close(nodeQueue)
 	wg.Wait() // wait for last one to finish
 
+	n.podCacheStats()
+
 	n.check.AddHistSample("collect_latency", cgm.Tags{
 		cgm.Tag{Category: "type", Value: "collect_nodes"},
 		cgm.Tag{Category: "source", Value: release.NAME},
@@ -172,11 +188,32 @@ func (n *Nodes) Collect(ctx context.Context, tlsConfig *tls.Config, ts *time.Tim
 	n.Unlock()
 }
 
+// podCacheStats emits the pod cache sync state, size, staleness, and hit/miss counts
+func (n *Nodes) podCacheStats() {
+	if n.pods == nil {
+		return
+	}
+
+	stats := n.pods.Stats()
+	synced := 0
+	if stats.Synced {
+		synced = 1
+	}
+	baseTags := cgm.Tags{cgm.Tag{Category: "source", Value: release.NAME}}
+	n.check.AddGauge("collect_pod_cache_synced", baseTags, synced)
+	n.check.AddGauge("collect_pod_cache_size", baseTags, stats.Pods)
+	n.check.AddGauge("collect_pod_cache_staleness", append(cgm.Tags{cgm.Tag{Category: "units", Value: "seconds"}}, baseTags...), stats.Staleness.Seconds())
+	n.check.IncrementCounterByValue("collect_pod_cache_requests", append(cgm.Tags{cgm.Tag{Category: "result", Value: "hit"}}, baseTags...), stats.Hits)
+	n.check.IncrementCounterByValue("collect_pod_cache_requests", append(cgm.Tags{cgm.Tag{Category: "result", Value: "miss"}}, baseTags...), stats.Misses)
+
+	if !stats.Synced {
+		n.log.Warn().Msg("pod cache not synced, fetching pods from api server")
+	}
+}
+
 func (n *Nodes) nodeList(ctx context.Context) (*v1.NodeList, error) {
-	clientset, err := k8s.GetClient(n.config)
-	if err != nil {
-		n.log.Error().Err(err).Msg("initializing client set")
-		return nil, err
+	if n.config.Mode == config.ModeNode {
+		return n.localNode(ctx)
 	}
 
 	listOptions := metav1.ListOptions{}
@@ -186,7 +223,7 @@ func (n *Nodes) nodeList(ctx context.Context) (*v1.NodeList, error) {
 	}
 
 	start := time.Now()
-	nodes, err := clientset.CoreV1().Nodes().List(ctx, listOptions)
+	nodes, err := n.clientset.CoreV1().Nodes().List(ctx, listOptions)
 	if err != nil {
 		n.check.IncrementCounter("collect_api_errors", cgm.Tags{
 			cgm.Tag{Category: "source", Value: release.NAME},
@@ -208,3 +245,25 @@ func (n *Nodes) nodeList(ctx context.Context) (*v1.NodeList, error) {
 
 	return nodes, nil
 }
+
+// localNode returns the node the agent is running on (node mode) as a single item list
+func (n *Nodes) localNode(ctx context.Context) (*v1.NodeList, error) {
+	start := time.Now()
+	node, err := n.clientset.CoreV1().Nodes().Get(ctx, n.config.NodeName, metav1.GetOptions{})
+	if err != nil {
+		n.check.IncrementCounter("collect_api_errors", cgm.Tags{
+			cgm.Tag{Category: "source", Value: release.NAME},
+			cgm.Tag{Category: "request", Value: "node"},
+			cgm.Tag{Category: "target", Value: "api-server"},
+		})
+		return nil, errors.Wrapf(err, "node %s", n.config.NodeName)
+	}
+	n.check.AddHistSample("collect_latency", cgm.Tags{
+		cgm.Tag{Category: "source", Value: release.NAME},
+		cgm.Tag{Category: "request", Value: "node"},
+		cgm.Tag{Category: "target", Value: "api-server"},
+		cgm.Tag{Category: "units", Value: "milliseconds"},
+	}, float64(time.Since(start).Milliseconds()))
+
+	return &v1.NodeList{Items: []v1.Node{*node}}, nil
+}
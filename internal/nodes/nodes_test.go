@@ -5,8 +5,298 @@
 
 package nodes
 
-import "testing"
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/circonus"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/k8s"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/nodes/collector"
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/testsupport"
+	"github.com/rs/zerolog"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const kubeletToken = "test-token"
 
 func Test(t *testing.T) {
 	t.Log("Placeholder...nothing to test currently")
 }
+
+func TestCollect(t *testing.T) {
+	t.Log("Testing Collect")
+
+	kube, api, clusterCfg := newTestCluster(t)
+	defer kube.Close()
+	defer k8s.SetClientFactory(kube.NewClient)()
+	defer api.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	check, err := circonus.NewCheck(ctx, zerolog.Nop(), api.CirconusConfig("test-cluster"), clusterCfg)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	clientset, err := kube.NewClient(clusterCfg)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	factory := k8s.NewInformerFactory(clientset, time.Minute)
+	pods, err := k8s.NewPodCache(factory)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	factory.Start(ctx.Done())
+	if !pods.WaitForSync(ctx) {
+		t.Fatal("expected pod cache to sync")
+	}
+
+	n, err := New(clusterCfg, zerolog.Nop(), check, clientset, pods, nil, false)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	// node conditions are only emitted when they change
+	collector.SetNodeStat("node-1", collector.NewNodeStat())
+
+	ts := time.Now()
+	n.Collect(ctx, nil, &ts)
+
+	testsupport.Golden(t, "nodes.golden", testsupport.MetricNames(api.Broker.Metrics()))
+
+	for _, req := range kube.Requests() {
+		if req == "GET /api/v1/namespaces/default/pods/web-0" {
+			t.Fatal("expected pod to be read from the pod cache")
+		}
+	}
+	if stats := pods.Stats(); !stats.Synced || stats.Pods != 1 {
+		t.Fatalf("expected synced cache with 1 pod, got %#v", stats)
+	}
+}
+
+func TestCollectDirect(t *testing.T) {
+	t.Log("Testing Collect (direct kubelet requests)")
+
+	kube, api, clusterCfg := newTestCluster(t)
+	defer kube.Close()
+	defer k8s.SetClientFactory(kube.NewClient)()
+	defer api.Close()
+
+	clusterCfg.NodeRequestMode = collector.RequestModeDirect
+	clusterCfg.KubeletInsecure = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	check, err := circonus.NewCheck(ctx, zerolog.Nop(), api.CirconusConfig("test-cluster"), clusterCfg)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	clientset, err := kube.NewClient(clusterCfg)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	collect := func(token string) []string {
+		t.Helper()
+		cfg := *clusterCfg
+		cfg.BearerToken = token
+		kubelet, err := collector.NewKubelet(&cfg)
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		n, err := New(&cfg, zerolog.Nop(), check, clientset, nil, kubelet, false)
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		api.Broker.Reset()
+		collector.SetNodeStat("node-1", collector.NewNodeStat())
+		before := len(kube.Requests())
+		ts := time.Now()
+		n.Collect(ctx, nil, &ts)
+		testsupport.Golden(t, "nodes.golden", testsupport.MetricNames(api.Broker.Metrics()))
+		return kube.Requests()[before:]
+	}
+
+	t.Log("direct")
+	{
+		for _, req := range collect(kubeletToken) {
+			if strings.Contains(req, "/proxy/") {
+				t.Fatalf("expected no api-server proxy requests, got %s", req)
+			}
+		}
+	}
+
+	t.Log("fallback to proxy")
+	{
+		proxied := 0
+		for _, req := range collect("invalid") {
+			if strings.Contains(req, "/proxy/") {
+				proxied++
+			}
+		}
+		if proxied != 4 {
+			t.Fatalf("expected 4 api-server proxy requests, got %d", proxied)
+		}
+	}
+}
+
+func TestCollectNodeMode(t *testing.T) {
+	t.Log("Testing Collect (node mode)")
+
+	kube, api, clusterCfg := newTestCluster(t)
+	defer kube.Close()
+	defer k8s.SetClientFactory(kube.NewClient)()
+	defer api.Close()
+
+	clusterCfg.Mode = config.ModeNode
+	clusterCfg.NodeName = "node-1"
+
+	// a second node (and pod) which should not be collected
+	err := kube.AddObjects(
+		&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-2"},
+			Status:     v1.NodeStatus{Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+			Spec:       v1.PodSpec{NodeName: "node-2"},
+		},
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	check, err := circonus.NewCheck(ctx, zerolog.Nop(), api.CirconusConfig("test-cluster"), clusterCfg)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	clientset, err := kube.NewClient(clusterCfg)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	factory := k8s.NewNodeInformerFactory(clientset, time.Minute, clusterCfg.NodeName)
+	pods, err := k8s.NewPodCache(factory)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	factory.Start(ctx.Done())
+	if !pods.WaitForSync(ctx) {
+		t.Fatal("expected pod cache to sync")
+	}
+
+	n, err := New(clusterCfg, zerolog.Nop(), check, clientset, pods, nil, false)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	collector.SetNodeStat("node-1", collector.NewNodeStat())
+
+	ts := time.Now()
+	n.Collect(ctx, nil, &ts)
+
+	// same node metrics as when iterating all nodes
+	testsupport.Golden(t, "nodes.golden", testsupport.MetricNames(api.Broker.Metrics()))
+
+	for _, req := range kube.Requests() {
+		if strings.Contains(req, "node-2") {
+			t.Fatalf("expected only node-1 to be collected, got %s", req)
+		}
+		if req == "GET /api/v1/nodes" {
+			t.Fatalf("expected no node list, got %s", req)
+		}
+	}
+	if stats := pods.Stats(); stats.Pods != 1 {
+		t.Fatalf("expected 1 pod (node-1) in cache, got %d", stats.Pods)
+	}
+}
+
+// newTestCluster returns a fake api with a node (and its kubelet) running one pod,
+// a fake circonus api and a cluster configuration with node and pod metrics enabled
+func newTestCluster(t *testing.T) (*testsupport.KubeAPI, *testsupport.API, *config.Cluster) {
+	t.Helper()
+
+	kube := testsupport.NewKubeAPI("v1.24.3")
+
+	api, err := testsupport.NewAPI()
+	if err != nil {
+		kube.Close()
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	host, port, err := kube.StartKubelet("node-1", kubeletToken)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	err = kube.AddObjects(
+		&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"kubernetes.io/os": "linux"}},
+			Status: v1.NodeStatus{
+				Conditions:      []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}},
+				NodeInfo:        v1.NodeSystemInfo{KubeletVersion: "v1.24.3"},
+				Addresses:       []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: host}},
+				DaemonEndpoints: v1.NodeDaemonEndpoints{KubeletEndpoint: v1.DaemonEndpoint{Port: int32(port)}},
+				Capacity: v1.ResourceList{
+					v1.ResourceCPU:              resource.MustParse("4"),
+					v1.ResourceMemory:           resource.MustParse("16Gi"),
+					v1.ResourcePods:             resource.MustParse("110"),
+					v1.ResourceEphemeralStorage: resource.MustParse("100Gi"),
+				},
+			},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "default", Labels: map[string]string{"app": "web"}},
+			Spec: v1.PodSpec{
+				NodeName: "node-1",
+				Containers: []v1.Container{{
+					Name: "web",
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m"), v1.ResourceMemory: resource.MustParse("64Mi")},
+						Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m"), v1.ResourceMemory: resource.MustParse("128Mi")},
+					},
+				}},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	for path, fn := range map[string]string{
+		"/stats/summary":    "stats-summary.json",
+		"/metrics":          "metrics.txt",
+		"/metrics/resource": "metrics-resource.txt",
+		"/metrics/cadvisor": "metrics-cadvisor.txt",
+	} {
+		body, err := os.ReadFile(filepath.Join("testdata", fn))
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		kube.SetNodeProxy("node-1", path, body)
+	}
+
+	clusterCfg := kube.ClusterConfig("test-cluster")
+	clusterCfg.EnableNodeStats = true
+	clusterCfg.EnableNodeMetrics = true
+	clusterCfg.EnableNodeResourceMetrics = true
+	clusterCfg.EnableCadvisorMetrics = true
+	clusterCfg.IncludePods = true
+	clusterCfg.IncludeContainers = true
+
+	return kube, api, clusterCfg
+}
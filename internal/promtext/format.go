@@ -0,0 +1,66 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package promtext
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// AcceptHeader is the Accept header for metrics requests, preferring delimited protobuf,
+// then OpenMetrics text and finally the classic text format
+const AcceptHeader = `application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.7,` +
+	`application/openmetrics-text;version=1.0.0;q=0.5,` +
+	`text/plain;version=0.0.4;q=0.3,*/*;q=0.1`
+
+// ResponseFormat returns the exposition format of a metrics response based on its
+// Content-Type, the classic text format is assumed when it is missing or unknown
+func ResponseFormat(h http.Header) expfmt.Format {
+	if mediatype, _, err := mime.ParseMediaType(h.Get("Content-Type")); err == nil && mediatype == expfmt.OpenMetricsType {
+		return expfmt.FmtOpenMetrics
+	}
+	if format := expfmt.ResponseFormat(h); format != expfmt.FmtUnknown {
+		return format
+	}
+	return expfmt.FmtText
+}
+
+// Parse parses metric families in the exposition format. Units (from OpenMetrics UNIT
+// metadata) are returned by family name, they are not available in the other formats.
+func Parse(data io.Reader, format expfmt.Format) (map[string]*dto.MetricFamily, map[string]string, error) {
+	switch format {
+	case expfmt.FmtOpenMetrics:
+		return parseOpenMetrics(data)
+	case expfmt.FmtProtoDelim:
+		families := make(map[string]*dto.MetricFamily)
+		decoder := expfmt.NewDecoder(data, format)
+		for {
+			mf := &dto.MetricFamily{}
+			if err := decoder.Decode(mf); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, nil, fmt.Errorf("decoding protobuf metrics: %w", err)
+			}
+			if existing, ok := families[mf.GetName()]; ok {
+				existing.Metric = append(existing.Metric, mf.Metric...)
+				continue
+			}
+			families[mf.GetName()] = mf
+		}
+		return families, nil, nil
+	default:
+		var parser expfmt.TextParser
+		families, err := parser.TextToMetricFamilies(data)
+		return families, nil, err
+	}
+}
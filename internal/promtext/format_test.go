@@ -0,0 +1,175 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package promtext
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+const testOpenMetrics = `# TYPE requests counter
+# HELP requests Requests \\ "served".
+requests_total{path="/a\"b"} 10 1700000000.5 # {trace_id="abc"} 1.0 1700000000.123
+requests_created{path="/a\"b"} 1699990000
+# TYPE latency histogram
+# UNIT latency seconds
+latency_bucket{le="0.1"} 2
+latency_bucket{le="1"} 5 # {trace_id="def"} 0.5
+latency_bucket{le="+Inf"} 6
+latency_count 6
+latency_sum 4.2
+# TYPE queue gaugehistogram
+queue_bucket{le="10"} 3
+queue_bucket{le="+Inf"} 4
+queue_gcount 4
+queue_gsum 25
+# TYPE rpc summary
+rpc{quantile="0.5"} 0.2
+rpc_count 7
+rpc_sum 3
+# TYPE build info
+build_info{version="1.2"} 1
+# TYPE temp gauge
+temp NaN
+other 3
+# EOF
+`
+
+func TestParse(t *testing.T) {
+	t.Log("Testing Parse")
+
+	t.Log("openmetrics")
+	{
+		families, units, err := Parse(strings.NewReader(testOpenMetrics), expfmt.FmtOpenMetrics)
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+
+		counter, ok := families["requests_total"]
+		if !ok || counter.GetType() != dto.MetricType_COUNTER {
+			t.Fatalf("expected counter requests_total, got %v", families)
+		}
+		if counter.GetHelp() != `Requests \ "served".` {
+			t.Fatalf("expected unescaped help, got %s", counter.GetHelp())
+		}
+		m := counter.Metric[0]
+		if m.Label[0].GetValue() != `/a"b` || m.GetCounter().GetValue() != 10 || m.GetTimestampMs() != 1700000000500 {
+			t.Fatalf("unexpected counter metric %v", m)
+		}
+		if e := m.GetCounter().GetExemplar(); e == nil || e.GetValue() != 1 || e.GetLabel()[0].GetValue() != "abc" || e.GetTimestamp().AsTime().UnixMilli() != 1700000000123 {
+			t.Fatalf("unexpected exemplar %v", e)
+		}
+		if created, ok := families["requests_created"]; !ok || created.GetType() != dto.MetricType_GAUGE || created.Metric[0].GetGauge().GetValue() != 1699990000 {
+			t.Fatalf("expected gauge requests_created, got %v", created)
+		}
+
+		histo := families["latency"]
+		if histo.GetType() != dto.MetricType_HISTOGRAM || len(histo.Metric) != 1 {
+			t.Fatalf("expected one histogram latency, got %v", histo)
+		}
+		h := histo.Metric[0].GetHistogram()
+		if len(h.Bucket) != 3 || h.GetSampleCount() != 6 || h.GetSampleSum() != 4.2 || !math.IsInf(h.Bucket[2].GetUpperBound(), 1) {
+			t.Fatalf("unexpected histogram %v", h)
+		}
+		if h.Bucket[1].GetExemplar().GetValue() != 0.5 {
+			t.Fatalf("expected bucket exemplar, got %v", h.Bucket[1])
+		}
+		if units["latency"] != "seconds" {
+			t.Fatalf("expected latency units seconds, got %v", units)
+		}
+
+		if gh := families["queue"]; gh.GetType() != dto.MetricType_GAUGE_HISTOGRAM || gh.Metric[0].GetHistogram().GetSampleCount() != 4 || gh.Metric[0].GetHistogram().GetSampleSum() != 25 {
+			t.Fatalf("unexpected gauge histogram %v", gh)
+		}
+		if s := families["rpc"]; s.GetType() != dto.MetricType_SUMMARY || len(s.Metric[0].GetSummary().Quantile) != 1 || s.Metric[0].GetSummary().GetSampleCount() != 7 {
+			t.Fatalf("unexpected summary %v", s)
+		}
+		if i := families["build_info"]; i.GetType() != dto.MetricType_GAUGE {
+			t.Fatalf("expected gauge build_info, got %v", i)
+		}
+		if g := families["temp"]; !math.IsNaN(g.Metric[0].GetGauge().GetValue()) {
+			t.Fatalf("expected NaN, got %v", g)
+		}
+		if u := families["other"]; u.GetType() != dto.MetricType_UNTYPED || u.Metric[0].GetUntyped().GetValue() != 3 {
+			t.Fatalf("expected untyped other, got %v", u)
+		}
+	}
+
+	t.Log("openmetrics, truncated")
+	{
+		if _, _, err := Parse(strings.NewReader("# TYPE temp gauge\ntemp 1\n"), expfmt.FmtOpenMetrics); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+
+	t.Log("openmetrics, invalid")
+	{
+		if _, _, err := Parse(strings.NewReader("temp{a=\"b} 1\n# EOF\n"), expfmt.FmtOpenMetrics); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+
+	t.Log("protobuf")
+	{
+		var buf bytes.Buffer
+		enc := expfmt.NewEncoder(&buf, expfmt.FmtProtoDelim)
+		name, value := "temp", 21.5
+		if err := enc.Encode(&dto.MetricFamily{
+			Name:   &name,
+			Type:   dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{{Gauge: &dto.Gauge{Value: &value}}},
+		}); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		families, _, err := Parse(&buf, expfmt.FmtProtoDelim)
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if families["temp"].Metric[0].GetGauge().GetValue() != 21.5 {
+			t.Fatalf("unexpected families %v", families)
+		}
+	}
+
+	t.Log("text")
+	{
+		families, _, err := Parse(strings.NewReader("# TYPE temp gauge\ntemp 3\n"), expfmt.FmtText)
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if families["temp"].Metric[0].GetGauge().GetValue() != 3 {
+			t.Fatalf("unexpected families %v", families)
+		}
+	}
+}
+
+func TestResponseFormat(t *testing.T) {
+	t.Log("Testing ResponseFormat")
+
+	tests := []struct {
+		contentType string
+		expected    expfmt.Format
+	}{
+		{"application/openmetrics-text; version=1.0.0; charset=utf-8", expfmt.FmtOpenMetrics},
+		{"application/vnd.google.protobuf; proto=io.prometheus.client.MetricFamily; encoding=delimited", expfmt.FmtProtoDelim},
+		{"text/plain; version=0.0.4", expfmt.FmtText},
+		{"", expfmt.FmtText},
+		{"application/json", expfmt.FmtText},
+	}
+
+	for _, test := range tests {
+		h := http.Header{}
+		h.Set("Content-Type", test.contentType)
+		if f := ResponseFormat(h); f != test.expected {
+			t.Fatalf("%q: expected %s, got %s", test.contentType, test.expected, f)
+		}
+	}
+}
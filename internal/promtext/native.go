@@ -0,0 +1,164 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package promtext
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// circonus log-linear histograms have bins of two significant digits with
+// decimal exponents in the range -128 to +127
+const (
+	circMinExp = -128
+	circMaxExp = 127
+)
+
+// isNativeHistogram returns true if the histogram has native (sparse) buckets,
+// a histogram may expose classic buckets as well, native buckets are preferred
+func isNativeHistogram(h *dto.Histogram) bool {
+	return h.GetZeroThreshold() > 0 ||
+		h.GetZeroCount() > 0 ||
+		h.GetZeroCountFloat() > 0 ||
+		len(h.GetPositiveSpan()) > 0 ||
+		len(h.GetNegativeSpan()) > 0
+}
+
+// histogramCount returns the observation count of an integer or float histogram
+func histogramCount(h *dto.Histogram) uint64 {
+	if h.SampleCountFloat != nil {
+		return uint64(math.Round(h.GetSampleCountFloat()))
+	}
+	return h.GetSampleCount()
+}
+
+// nativeHistoToCircHisto converts the buckets of a native histogram to circonus
+// log-linear bins. Bucket i of schema s covers (base^(i-1), base^i] with base
+// 2^(2^-s); its count is placed in the circonus bin containing the (geometric)
+// middle of the bucket, buckets of higher resolution schemas falling in the same
+// circonus bin are combined. Negative buckets mirror the positive ones and the zero
+// bucket (observations within the zero threshold) is placed in the zero bin.
+func nativeHistoToCircHisto(h *dto.Histogram) []string {
+	bins := make(map[float64]uint64)
+
+	zeroCount := h.GetZeroCount()
+	if h.ZeroCountFloat != nil {
+		zeroCount = uint64(math.Round(h.GetZeroCountFloat()))
+	}
+	if zeroCount > 0 {
+		bins[0] = zeroCount
+	}
+
+	schema := h.GetSchema()
+	nativeBuckets(h.GetPositiveSpan(), h.GetPositiveDelta(), h.GetPositiveCount(), func(idx int32, count uint64) {
+		bins[circBin(nativeBucketMiddle(schema, idx))] += count
+	})
+	nativeBuckets(h.GetNegativeSpan(), h.GetNegativeDelta(), h.GetNegativeCount(), func(idx int32, count uint64) {
+		bins[circBin(-nativeBucketMiddle(schema, idx))] += count
+	})
+
+	values := make([]float64, 0, len(bins))
+	for v, count := range bins {
+		if count > 0 {
+			values = append(values, v)
+		}
+	}
+	sort.Float64s(values)
+
+	ret := make([]string, len(values))
+	for i, v := range values {
+		ret[i] = fmt.Sprintf("H[%e]=%d", v, bins[v])
+	}
+	return ret
+}
+
+// nativeBuckets calls fn with the index and count of each populated bucket. The offset
+// of the first span is the index of its first bucket, the offsets of the following spans
+// are the number of empty buckets since the previous span. Integer histograms encode
+// counts as deltas from the previous bucket, float histograms as absolute counts.
+func nativeBuckets(spans []*dto.BucketSpan, deltas []int64, counts []float64, fn func(int32, uint64)) {
+	idx := int32(0)
+	pos := 0
+	count := int64(0)
+	for _, span := range spans {
+		idx += span.GetOffset()
+		for n := uint32(0); n < span.GetLength(); n++ {
+			var c uint64
+			if len(counts) > 0 {
+				if pos >= len(counts) {
+					return
+				}
+				if counts[pos] > 0 {
+					c = uint64(math.Round(counts[pos]))
+				}
+			} else {
+				if pos >= len(deltas) {
+					return
+				}
+				count += deltas[pos]
+				if count > 0 {
+					c = uint64(count)
+				}
+			}
+			if c > 0 {
+				fn(idx, c)
+			}
+			idx++
+			pos++
+		}
+	}
+}
+
+// nativeBucketMiddle returns the geometric middle of bucket idx of a schema, the
+// bucket's bounds are 2^((idx-1)*2^-schema) and 2^(idx*2^-schema)
+func nativeBucketMiddle(schema, idx int32) float64 {
+	return math.Exp2((float64(idx) - 0.5) / math.Exp2(float64(schema)))
+}
+
+// circBin returns the middle of the circonus log-linear bin containing v, values
+// beyond the range of circonus histograms are clamped to the outermost bins
+func circBin(v float64) float64 {
+	if v == 0 || math.IsNaN(v) {
+		return 0
+	}
+	sign := 1.0
+	if v < 0 {
+		sign = -1
+		v = -v
+	}
+	if math.IsInf(v, 1) {
+		v = math.MaxFloat64 // clamped below
+	}
+
+	exp := int(math.Floor(math.Log10(v)))
+	mantissa := math.Floor(v / math.Pow10(exp) * 10) // 10-99, two significant digits
+	switch {
+	case mantissa >= 100: // rounding
+		mantissa = 10
+		exp++
+	case mantissa < 10:
+		mantissa = 99
+		exp--
+	}
+	switch {
+	case exp > circMaxExp:
+		mantissa = 99
+		exp = circMaxExp
+	case exp < circMinExp:
+		mantissa = 10
+		exp = circMinExp
+	}
+
+	// the middle, (mantissa+0.5)/10 * 10^exp, from an integer numerator to limit rounding
+	middle := mantissa*10 + 5
+	if exp >= 2 {
+		return sign * middle * math.Pow10(exp-2)
+	}
+	return sign * middle / math.Pow10(2-exp)
+}
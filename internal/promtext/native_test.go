@@ -0,0 +1,99 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package promtext
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+func span(offset int32, length uint32) *dto.BucketSpan {
+	return &dto.BucketSpan{Offset: &offset, Length: &length}
+}
+
+func TestNativeHistoToCircHisto(t *testing.T) {
+	t.Log("Testing nativeHistoToCircHisto")
+
+	t.Log("integer counts, spans, zero and negative buckets")
+	{
+		schema := int32(0)
+		threshold := 1e-128
+		zero := uint64(4)
+		h := &dto.Histogram{
+			Schema:        &schema,
+			ZeroThreshold: &threshold,
+			ZeroCount:     &zero,
+			// buckets 0 (0.5,1], 1 (1,2] and 4 (8,16]
+			PositiveSpan:  []*dto.BucketSpan{span(0, 2), span(2, 1)},
+			PositiveDelta: []int64{2, 1, -2},
+			NegativeSpan:  []*dto.BucketSpan{span(1, 1)},
+			NegativeDelta: []int64{1},
+		}
+		if !isNativeHistogram(h) {
+			t.Fatal("expected native histogram")
+		}
+		expected := []string{
+			"H[-1.450000e+00]=1",
+			"H[0.000000e+00]=4",
+			"H[7.050000e-01]=2",
+			"H[1.450000e+00]=3",
+			"H[1.150000e+01]=1",
+		}
+		if bins := nativeHistoToCircHisto(h); !reflect.DeepEqual(bins, expected) {
+			t.Fatalf("expected %v, got %v", expected, bins)
+		}
+	}
+
+	t.Log("float counts, high resolution buckets combined")
+	{
+		schema := int32(8)
+		h := &dto.Histogram{
+			Schema: &schema,
+			// buckets 1-3 are within (1, 1.00815], all in the 1.0 circonus bin
+			PositiveSpan:  []*dto.BucketSpan{span(1, 3)},
+			PositiveCount: []float64{1, 2, 3},
+		}
+		expected := []string{"H[1.050000e+00]=6"}
+		if bins := nativeHistoToCircHisto(h); !reflect.DeepEqual(bins, expected) {
+			t.Fatalf("expected %v, got %v", expected, bins)
+		}
+	}
+
+	t.Log("classic histogram")
+	{
+		count := uint64(1)
+		bound := 0.5
+		h := &dto.Histogram{SampleCount: &count, Bucket: []*dto.Bucket{{UpperBound: &bound, CumulativeCount: &count}}}
+		if isNativeHistogram(h) {
+			t.Fatal("expected classic histogram")
+		}
+	}
+}
+
+func TestCircBin(t *testing.T) {
+	t.Log("Testing circBin")
+
+	tests := []struct {
+		value    float64
+		expected float64
+	}{
+		{0, 0},
+		{1, 1.05},
+		{0.0123, 0.0125},
+		{-250, -255},
+		{1e200, 9.95e127},
+		{math.Inf(-1), -9.95e127},
+		{1e-200, 1.05e-128},
+	}
+	for _, test := range tests {
+		if bin := circBin(test.value); math.Abs(bin-test.expected) > math.Abs(test.expected)*1e-12 {
+			t.Fatalf("%g: expected %g, got %g", test.value, test.expected, bin)
+		}
+	}
+}
@@ -0,0 +1,453 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package promtext
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// omSuffixes are the sample name suffixes of each OpenMetrics family type
+var omSuffixes = map[string][]string{
+	"counter":        {"_total", "_created"},
+	"gauge":          {""},
+	"histogram":      {"_bucket", "_count", "_sum", "_created"},
+	"gaugehistogram": {"_bucket", "_gcount", "_gsum"},
+	"summary":        {"", "_count", "_sum", "_created"},
+	"info":           {"_info"},
+	"stateset":       {""},
+	"unknown":        {""},
+}
+
+// omFamily is the metadata (TYPE, HELP and UNIT) of an OpenMetrics family
+type omFamily struct {
+	typ  string
+	help string
+	unit string
+}
+
+type omParser struct {
+	families map[string]*dto.MetricFamily
+	metrics  map[string]*dto.Metric // by family name and label signature
+	units    map[string]string
+	meta     map[string]*omFamily
+	current  string
+}
+
+// parseOpenMetrics parses the OpenMetrics text format into metric families. Family
+// names follow the classic text format, so metric names do not change with the
+// negotiated format: counters include the _total suffix and info metrics the _info
+// suffix (as gauges). The _created series of counters, histograms and summaries
+// become gauge families (<name>_created). Units from the UNIT metadata are returned
+// by family name.
+func parseOpenMetrics(r io.Reader) (map[string]*dto.MetricFamily, map[string]string, error) {
+	p := &omParser{
+		families: make(map[string]*dto.MetricFamily),
+		metrics:  make(map[string]*dto.Metric),
+		units:    make(map[string]string),
+		meta:     make(map[string]*omFamily),
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	lineNum := 0
+	eof := false
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if line == "# EOF" {
+				eof = true
+				break
+			}
+			if err := p.metadata(line); err != nil {
+				return nil, nil, fmt.Errorf("openmetrics line %d: %w", lineNum, err)
+			}
+			continue
+		}
+		if err := p.sample(line); err != nil {
+			return nil, nil, fmt.Errorf("openmetrics line %d: %w", lineNum, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("reading openmetrics: %w", err)
+	}
+	if !eof {
+		return nil, nil, fmt.Errorf("openmetrics: missing # EOF (truncated response)")
+	}
+
+	return p.families, p.units, nil
+}
+
+// metadata parses a TYPE, HELP or UNIT line, other comments are ignored
+func (p *omParser) metadata(line string) error {
+	fields := strings.SplitN(line, " ", 4)
+	if len(fields) < 3 || fields[0] != "#" {
+		return nil
+	}
+	name := fields[2]
+	text := ""
+	if len(fields) == 4 {
+		text = fields[3]
+	}
+
+	switch fields[1] {
+	case "TYPE", "HELP", "UNIT":
+	default:
+		return nil
+	}
+
+	m, ok := p.meta[name]
+	if !ok {
+		m = &omFamily{typ: "unknown"}
+		p.meta[name] = m
+	}
+	p.current = name
+
+	switch fields[1] {
+	case "TYPE":
+		if _, ok := omSuffixes[text]; !ok {
+			return fmt.Errorf("invalid type %q for %s", text, name)
+		}
+		m.typ = text
+	case "HELP":
+		m.help = unescape(text, false)
+	case "UNIT":
+		m.unit = text
+	}
+
+	return nil
+}
+
+// family returns the family a sample belongs to: the current family if the sample
+// name is one of its series, otherwise the sample is an unknown (untyped) metric
+func (p *omParser) family(sampleName string) (string, string, string) {
+	if m, ok := p.meta[p.current]; ok {
+		for _, suffix := range omSuffixes[m.typ] {
+			if sampleName == p.current+suffix {
+				return p.current, suffix, m.typ
+			}
+		}
+	}
+	return sampleName, "", "unknown"
+}
+
+// metric returns the metric of a family with the labels, creating the family and metric as needed
+func (p *omParser) metric(name string, typ dto.MetricType, help string, labels []*dto.LabelPair) *dto.Metric {
+	mf, ok := p.families[name]
+	if !ok {
+		mf = &dto.MetricFamily{Name: strPtr(name), Type: typ.Enum()}
+		if help != "" {
+			mf.Help = strPtr(help)
+		}
+		p.families[name] = mf
+	}
+
+	key := name + "\xff" + labelSignature(labels)
+	if m, ok := p.metrics[key]; ok {
+		return m
+	}
+	m := &dto.Metric{Label: labels}
+	mf.Metric = append(mf.Metric, m)
+	p.metrics[key] = m
+
+	return m
+}
+
+// sample parses a sample line: name[{labels}] value [timestamp] [# {labels} value [timestamp]]
+func (p *omParser) sample(line string) error {
+	end := strings.IndexAny(line, "{ ")
+	if end <= 0 {
+		return fmt.Errorf("invalid sample %q", line)
+	}
+	sampleName := line[:end]
+	rest := line[end:]
+
+	var labels []*dto.LabelPair
+	if strings.HasPrefix(rest, "{") {
+		var err error
+		labels, rest, err = parseLabels(rest)
+		if err != nil {
+			return fmt.Errorf("%s: %w", sampleName, err)
+		}
+	}
+
+	var exemplar *dto.Exemplar
+	if idx := strings.Index(rest, " # "); idx >= 0 {
+		e, err := parseExemplar(rest[idx+3:])
+		if err != nil {
+			return fmt.Errorf("%s: exemplar: %w", sampleName, err)
+		}
+		exemplar = e
+		rest = rest[:idx]
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 || len(fields) > 2 {
+		return fmt.Errorf("%s: invalid value and timestamp %q", sampleName, rest)
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return fmt.Errorf("%s: invalid value: %w", sampleName, err)
+	}
+	var timestampMs *int64
+	if len(fields) == 2 {
+		ts, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return fmt.Errorf("%s: invalid timestamp: %w", sampleName, err)
+		}
+		ms := int64(math.Round(ts * 1000))
+		timestampMs = &ms
+	}
+
+	base, suffix, typ := p.family(sampleName)
+	help := ""
+	if m, ok := p.meta[base]; ok {
+		help = m.help
+		if m.unit != "" {
+			p.units[familyName(base, typ)] = m.unit
+		}
+	}
+
+	if suffix == "_created" {
+		m := p.metric(base+"_created", dto.MetricType_GAUGE, "", labels)
+		m.Gauge = &dto.Gauge{Value: &value}
+		m.TimestampMs = timestampMs
+		return nil
+	}
+
+	switch typ {
+	case "counter":
+		m := p.metric(familyName(base, typ), dto.MetricType_COUNTER, help, labels)
+		m.Counter = &dto.Counter{Value: &value, Exemplar: exemplar}
+		m.TimestampMs = timestampMs
+	case "gauge", "stateset", "info":
+		m := p.metric(familyName(base, typ), dto.MetricType_GAUGE, help, labels)
+		m.Gauge = &dto.Gauge{Value: &value}
+		m.TimestampMs = timestampMs
+	case "histogram", "gaugehistogram":
+		mtype := dto.MetricType_HISTOGRAM
+		if typ == "gaugehistogram" {
+			mtype = dto.MetricType_GAUGE_HISTOGRAM
+		}
+		var le string
+		if suffix == "_bucket" {
+			le, labels = removeLabel(labels, "le")
+			if le == "" {
+				return fmt.Errorf("%s: bucket without le label", sampleName)
+			}
+		}
+		m := p.metric(base, mtype, help, labels)
+		if m.Histogram == nil {
+			m.Histogram = &dto.Histogram{}
+		}
+		m.TimestampMs = timestampMs
+		switch suffix {
+		case "_bucket":
+			bound, err := strconv.ParseFloat(le, 64)
+			if err != nil {
+				return fmt.Errorf("%s: invalid le: %w", sampleName, err)
+			}
+			count := uint64(value)
+			m.Histogram.Bucket = append(m.Histogram.Bucket, &dto.Bucket{UpperBound: &bound, CumulativeCount: &count, Exemplar: exemplar})
+		case "_count", "_gcount":
+			count := uint64(value)
+			m.Histogram.SampleCount = &count
+		case "_sum", "_gsum":
+			m.Histogram.SampleSum = &value
+		}
+	case "summary":
+		var quantile string
+		if suffix == "" {
+			quantile, labels = removeLabel(labels, "quantile")
+			if quantile == "" {
+				return fmt.Errorf("%s: summary sample without quantile label", sampleName)
+			}
+		}
+		m := p.metric(base, dto.MetricType_SUMMARY, help, labels)
+		if m.Summary == nil {
+			m.Summary = &dto.Summary{}
+		}
+		m.TimestampMs = timestampMs
+		switch suffix {
+		case "":
+			q, err := strconv.ParseFloat(quantile, 64)
+			if err != nil {
+				return fmt.Errorf("%s: invalid quantile: %w", sampleName, err)
+			}
+			m.Summary.Quantile = append(m.Summary.Quantile, &dto.Quantile{Quantile: &q, Value: &value})
+		case "_count":
+			count := uint64(value)
+			m.Summary.SampleCount = &count
+		case "_sum":
+			m.Summary.SampleSum = &value
+		}
+	default:
+		m := p.metric(base, dto.MetricType_UNTYPED, help, labels)
+		m.Untyped = &dto.Untyped{Value: &value}
+		m.TimestampMs = timestampMs
+	}
+
+	return nil
+}
+
+// familyName returns the classic text format family name of an OpenMetrics family
+func familyName(base, typ string) string {
+	switch typ {
+	case "counter":
+		return base + "_total"
+	case "info":
+		return base + "_info"
+	default:
+		return base
+	}
+}
+
+// parseLabels parses {name="value",...} returning the labels and the remainder of the line
+func parseLabels(s string) ([]*dto.LabelPair, string, error) {
+	labels := []*dto.LabelPair{}
+	i := 1 // skip {
+	for {
+		for i < len(s) && (s[i] == ' ' || s[i] == ',') {
+			i++
+		}
+		if i >= len(s) {
+			return nil, "", fmt.Errorf("unterminated labels")
+		}
+		if s[i] == '}' {
+			return labels, s[i+1:], nil
+		}
+		eq := strings.IndexByte(s[i:], '=')
+		if eq <= 0 {
+			return nil, "", fmt.Errorf("invalid label in %q", s)
+		}
+		name := strings.TrimSpace(s[i : i+eq])
+		i += eq + 1
+		if i >= len(s) || s[i] != '"' {
+			return nil, "", fmt.Errorf("label %s value not quoted", name)
+		}
+		i++
+		start := i
+		escaped := false
+		for ; i < len(s); i++ {
+			if escaped {
+				escaped = false
+				continue
+			}
+			if s[i] == '\\' {
+				escaped = true
+				continue
+			}
+			if s[i] == '"' {
+				break
+			}
+		}
+		if i >= len(s) {
+			return nil, "", fmt.Errorf("label %s value not terminated", name)
+		}
+		value := unescape(s[start:i], true)
+		labels = append(labels, &dto.LabelPair{Name: strPtr(name), Value: strPtr(value)})
+		i++ // closing quote
+	}
+}
+
+// parseExemplar parses {labels} value [timestamp]
+func parseExemplar(s string) (*dto.Exemplar, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "{") {
+		return nil, fmt.Errorf("invalid exemplar %q", s)
+	}
+	labels, rest, err := parseLabels(s)
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, fmt.Errorf("invalid exemplar value %q", rest)
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exemplar value: %w", err)
+	}
+	e := &dto.Exemplar{Label: labels, Value: &value}
+	if len(fields) == 2 {
+		ts, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exemplar timestamp: %w", err)
+		}
+		sec, frac := math.Modf(ts)
+		e.Timestamp = timestamppb.New(time.Unix(int64(sec), int64(math.Round(frac*1e6))*1e3)) // microsecond precision
+	}
+	return e, nil
+}
+
+// removeLabel returns the value of the named label and the labels without it
+func removeLabel(labels []*dto.LabelPair, name string) (string, []*dto.LabelPair) {
+	value := ""
+	ret := make([]*dto.LabelPair, 0, len(labels))
+	for _, l := range labels {
+		if l.GetName() == name {
+			value = l.GetValue()
+			continue
+		}
+		ret = append(ret, l)
+	}
+	return value, ret
+}
+
+// labelSignature returns a key identifying a label set, independent of label order
+func labelSignature(labels []*dto.LabelPair) string {
+	pairs := make([]string, len(labels))
+	for idx, l := range labels {
+		pairs[idx] = l.GetName() + "\xfe" + l.GetValue()
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "\xff")
+}
+
+// unescape replaces the escape sequences \\, \n and (in label values) \"
+func unescape(s string, quotes bool) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch next := s[i+1]; {
+		case next == '\\':
+			b.WriteByte('\\')
+		case next == 'n':
+			b.WriteByte('\n')
+		case next == '"' && quotes:
+			b.WriteByte('"')
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(next)
+		}
+		i++
+	}
+	return b.String()
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -3,7 +3,7 @@
 // license that can be found in the LICENSE file.
 //
 
-// Package promtext parses prometheus text metrics
+// Package promtext parses prometheus metrics (text, OpenMetrics and protobuf formats)
 package promtext
 
 import (
@@ -40,17 +40,53 @@ func QueueMetrics(
 	parentMeasurementTags []string,
 	ts *time.Time,
 ) error {
-	var baseStreamTags []string
-	if len(parentStreamTags) > 0 {
-		baseStreamTags = make([]string, len(parentStreamTags))
-		copy(baseStreamTags, parentStreamTags)
+	metricFamilies, err := parser.TextToMetricFamilies(data)
+	if err != nil {
+		return err
 	}
 
-	metricFamilies, err := parser.TextToMetricFamilies(data)
+	return queueFamilies(ctx, check, logger, metricFamilies, nil, parentStreamTags, parentMeasurementTags, ts)
+}
+
+// QueueFormatMetrics digests metrics in the exposition format of a response (see
+// ResponseFormat) and emits circonus formatted metrics. Classic text, OpenMetrics
+// text and delimited protobuf are supported.
+func QueueFormatMetrics(
+	ctx context.Context,
+	format expfmt.Format,
+	check *circonus.Check,
+	logger zerolog.Logger,
+	data io.Reader,
+	parentStreamTags []string,
+	parentMeasurementTags []string,
+	ts *time.Time,
+) error {
+	metricFamilies, units, err := Parse(data, format)
 	if err != nil {
 		return err
 	}
 
+	return queueFamilies(ctx, check, logger, metricFamilies, units, parentStreamTags, parentMeasurementTags, ts)
+}
+
+// queueFamilies converts metric families to circonus metrics and flushes them, units
+// (by family name, from OpenMetrics UNIT metadata) are added as a stream tag
+func queueFamilies(
+	ctx context.Context,
+	check *circonus.Check,
+	logger zerolog.Logger,
+	metricFamilies map[string]*dto.MetricFamily,
+	units map[string]string,
+	parentStreamTags []string,
+	parentMeasurementTags []string,
+	ts *time.Time,
+) error {
+	var baseStreamTags []string
+	if len(parentStreamTags) > 0 {
+		baseStreamTags = make([]string, len(parentStreamTags))
+		copy(baseStreamTags, parentStreamTags)
+	}
+
 	metrics := make(map[string]circonus.MetricSample)
 
 	metricsProcessed := 0
@@ -65,14 +101,17 @@ func QueueMetrics(
 			}
 			metricName := mn
 			streamTags := check.NewTagList(baseStreamTags, getLabels(m))
+			if unit, ok := units[mn]; ok {
+				streamTags = check.NewTagList(streamTags, []string{"units:" + unit})
+			}
 			switch mf.GetType() {
 			case dto.MetricType_SUMMARY:
-				_ = check.QueueMetricSample(
+				_ = check.QueueCounterSample(
 					metrics, metricName+"_count",
 					circonus.MetricTypeUint64,
 					streamTags, parentMeasurementTags,
 					m.GetSummary().GetSampleCount(), ts)
-				_ = check.QueueMetricSample(
+				_ = check.QueueCounterSample(
 					metrics, metricName+"_sum",
 					circonus.MetricTypeFloat64,
 					streamTags, parentMeasurementTags,
@@ -86,12 +125,12 @@ func QueueMetrics(
 						qv, ts)
 				}
 			case dto.MetricType_HISTOGRAM:
-				_ = check.QueueMetricSample(
+				_ = check.QueueCounterSample(
 					metrics, metricName+"_count",
 					circonus.MetricTypeUint64,
 					streamTags, parentMeasurementTags,
-					m.GetHistogram().GetSampleCount(), ts)
-				_ = check.QueueMetricSample(
+					histogramCount(m.GetHistogram()), ts)
+				_ = check.QueueCounterSample(
 					metrics, metricName+"_sum",
 					circonus.MetricTypeFloat64,
 					streamTags, parentMeasurementTags,
@@ -101,9 +140,18 @@ func QueueMetrics(
 					metrics, metricName+"_avg",
 					circonus.MetricTypeFloat64,
 					streamTags, parentMeasurementTags,
-					m.GetHistogram().GetSampleSum()/float64(m.GetHistogram().GetSampleCount()), ts)
+					m.GetHistogram().GetSampleSum()/float64(histogramCount(m.GetHistogram())), ts)
 
-				if emitHistogramBuckets {
+				if isNativeHistogram(m.GetHistogram()) {
+					histo := nativeHistoToCircHisto(m.GetHistogram())
+					if len(histo) > 0 {
+						_ = check.QueueMetricSample(
+							metrics, metricName,
+							circonus.MetricTypeCumulativeHistogram,
+							streamTags, parentMeasurementTags,
+							histo, ts)
+					}
+				} else if emitHistogramBuckets {
 					if circCumulativeHistogram {
 						histo := promHistoBucketsToCircHisto(m)
 						if len(histo) > 0 {
@@ -116,7 +164,7 @@ func QueueMetrics(
 					} else {
 						for bn, bv := range getBuckets(m) {
 							htags := check.NewTagList(streamTags, []string{"bucket:" + bn})
-							_ = check.QueueMetricSample(
+							_ = check.QueueCounterSample(
 								metrics, metricName,
 								circonus.MetricTypeUint64,
 								htags, parentMeasurementTags,
@@ -124,6 +172,31 @@ func QueueMetrics(
 						}
 					}
 				}
+			case dto.MetricType_GAUGE_HISTOGRAM:
+				// the current distribution (not accumulated over time), emitted as a non-cumulative histogram
+				_ = check.QueueMetricSample(
+					metrics, metricName+"_gcount",
+					circonus.MetricTypeUint64,
+					streamTags, parentMeasurementTags,
+					histogramCount(m.GetHistogram()), ts)
+				_ = check.QueueMetricSample(
+					metrics, metricName+"_gsum",
+					circonus.MetricTypeFloat64,
+					streamTags, parentMeasurementTags,
+					m.GetHistogram().GetSampleSum(), ts)
+				var histo []string
+				if isNativeHistogram(m.GetHistogram()) {
+					histo = nativeHistoToCircHisto(m.GetHistogram())
+				} else {
+					histo = promHistoBucketsToCircHisto(m)
+				}
+				if len(histo) > 0 {
+					_ = check.QueueMetricSample(
+						metrics, metricName,
+						circonus.MetricTypeHistogram,
+						streamTags, parentMeasurementTags,
+						histo, ts)
+				}
 			case dto.MetricType_GAUGE:
 				if m.GetGauge().Value != nil {
 					if math.IsNaN(*m.GetGauge().Value) {
@@ -150,7 +223,7 @@ func QueueMetrics(
 							Msg("cannot coerce NaN")
 						continue
 					}
-					_ = check.QueueMetricSample(
+					_ = check.QueueCounterSample(
 						metrics, metricName,
 						circonus.MetricTypeFloat64,
 						streamTags, parentMeasurementTags,
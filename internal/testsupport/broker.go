@@ -0,0 +1,139 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package testsupport
+
+import (
+	"compress/gzip"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// Metric is a metric submitted to a fake broker
+type Metric struct {
+	Value     interface{} `json:"_value"`
+	Type      string      `json:"_type"`
+	Timestamp uint64      `json:"_ts,omitempty"`
+}
+
+// Broker is a fake httptrap broker, it decodes and records submitted metrics
+type Broker struct {
+	*httptest.Server
+	metrics     map[string]Metric
+	CID         string
+	mu          sync.Mutex
+	status      int
+	submissions int
+}
+
+// newBroker starts a fake broker serving tls with a certificate issued by ca
+func newBroker(cid string, ca *certAuthority) (*Broker, error) {
+	cert, err := ca.issue(BrokerCN)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Broker{
+		CID:     cid,
+		metrics: make(map[string]Metric),
+	}
+	b.Server = httptest.NewUnstartedServer(http.HandlerFunc(b.handler))
+	b.Server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	b.Server.StartTLS()
+
+	return b, nil
+}
+
+// SubmissionURL returns the httptrap submission url for a check
+func (b *Broker) SubmissionURL(checkUUID, secret string) string {
+	return fmt.Sprintf("%s/module/httptrap/%s/%s", b.URL, checkUUID, secret)
+}
+
+// SetStatus makes the broker respond to submissions with the http status code,
+// zero restores normal operation
+func (b *Broker) SetStatus(code int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.status = code
+}
+
+// Metrics returns the metrics received by the broker, the last sample for each name
+func (b *Broker) Metrics() map[string]Metric {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	metrics := make(map[string]Metric, len(b.metrics))
+	for name, m := range b.metrics {
+		metrics[name] = m
+	}
+	return metrics
+}
+
+// Submissions returns the number of accepted submissions
+func (b *Broker) Submissions() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.submissions
+}
+
+// Reset clears the recorded metrics and submissions
+func (b *Broker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.metrics = make(map[string]Metric)
+	b.submissions = 0
+}
+
+func (b *Broker) handler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodPost {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, "/module/httptrap/") {
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+		return
+	}
+
+	b.mu.Lock()
+	status := b.status
+	b.mu.Unlock()
+	if status != 0 {
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"error":"%s"}`, http.StatusText(status))
+		return
+	}
+
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, `{"error":"invalid gzip"}`, http.StatusBadRequest)
+			return
+		}
+		defer zr.Close()
+		body = zr
+	}
+
+	var metrics map[string]Metric
+	if err := json.NewDecoder(body).Decode(&metrics); err != nil {
+		http.Error(w, `{"error":"invalid json"}`, http.StatusBadRequest)
+		return
+	}
+
+	b.mu.Lock()
+	for name, m := range metrics {
+		b.metrics[name] = m
+	}
+	b.submissions++
+	b.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"stats":%d}`, len(metrics))
+}
@@ -0,0 +1,533 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package testsupport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+	apiclient "github.com/circonus-labs/go-apiclient"
+	apiclicfg "github.com/circonus-labs/go-apiclient/config"
+)
+
+// searchTermRx matches `(key:value)` and `(key:"value")` terms in api search queries
+var searchTermRx = regexp.MustCompile(`\(([a-z_]+):(?:"([^"]*)"|([^)]*))\)`)
+
+// API is a fake Circonus API. It supports the subset of endpoints used by the
+// agent: check bundle search/create/update, checks, brokers, the broker CA
+// certificate, rule sets and contact groups. Every check bundle created is
+// served by the fake broker(s) it references.
+type API struct {
+	*httptest.Server
+	ca            *certAuthority
+	Broker        *Broker
+	brokers       map[string]*Broker
+	checkBundles  map[string]*apiclient.CheckBundle
+	checks        map[string]*apiclient.Check
+	ruleSets      map[string]*apiclient.RuleSet
+	contactGroups map[string]*apiclient.ContactGroup
+	requests      []string
+	mu            sync.Mutex
+	nextID        int
+}
+
+// NewAPI starts a fake Circonus API with one fake broker (API.Broker)
+func NewAPI() (*API, error) {
+	ca, err := newCertAuthority()
+	if err != nil {
+		return nil, err
+	}
+
+	a := &API{
+		ca:            ca,
+		brokers:       make(map[string]*Broker),
+		checkBundles:  make(map[string]*apiclient.CheckBundle),
+		checks:        make(map[string]*apiclient.Check),
+		ruleSets:      make(map[string]*apiclient.RuleSet),
+		contactGroups: make(map[string]*apiclient.ContactGroup),
+		nextID:        1000,
+	}
+
+	b, err := a.AddBroker()
+	if err != nil {
+		return nil, err
+	}
+	a.Broker = b
+
+	a.Server = httptest.NewServer(http.HandlerFunc(a.handler))
+
+	return a, nil
+}
+
+// Close shuts down the api and all brokers
+func (a *API) Close() {
+	a.Server.Close()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, b := range a.brokers {
+		b.Close()
+	}
+}
+
+// CACert returns the pem encoded ca certificate for the fake brokers
+func (a *API) CACert() []byte {
+	return a.ca.certPEM
+}
+
+// AddBroker starts an additional fake broker
+func (a *API) AddBroker() (*Broker, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.nextID++
+	b, err := newBroker(fmt.Sprintf("%s/%d", apiclicfg.BrokerPrefix, a.nextID), a.ca)
+	if err != nil {
+		return nil, err
+	}
+	a.brokers[b.CID] = b
+
+	return b, nil
+}
+
+// AddCheckBundle adds an existing check bundle, checks are created for each broker
+func (a *API) AddCheckBundle(bundle apiclient.CheckBundle) (*apiclient.CheckBundle, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.createCheckBundle(&bundle)
+}
+
+// AddContactGroup adds an existing contact group
+func (a *API) AddContactGroup(cg apiclient.ContactGroup) *apiclient.ContactGroup {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.nextID++
+	cg.CID = fmt.Sprintf("%s/%d", apiclicfg.ContactGroupPrefix, a.nextID)
+	a.contactGroups[cg.CID] = &cg
+	return &cg
+}
+
+// AddRuleSet adds an existing rule set
+func (a *API) AddRuleSet(rs apiclient.RuleSet) *apiclient.RuleSet {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.nextID++
+	rs.CID = fmt.Sprintf("%s/%d", apiclicfg.RuleSetPrefix, a.nextID)
+	a.ruleSets[rs.CID] = &rs
+	return &rs
+}
+
+// CheckBundles returns the check bundles, ordered by cid
+func (a *API) CheckBundles() []apiclient.CheckBundle {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	list := make([]apiclient.CheckBundle, 0, len(a.checkBundles))
+	for _, cid := range sortedKeys(a.checkBundles) {
+		list = append(list, *a.checkBundles[cid])
+	}
+	return list
+}
+
+// RuleSets returns the rule sets, ordered by cid
+func (a *API) RuleSets() []apiclient.RuleSet {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	list := make([]apiclient.RuleSet, 0, len(a.ruleSets))
+	for _, cid := range sortedKeys(a.ruleSets) {
+		list = append(list, *a.ruleSets[cid])
+	}
+	return list
+}
+
+// ContactGroups returns the contact groups, ordered by cid
+func (a *API) ContactGroups() []apiclient.ContactGroup {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	list := make([]apiclient.ContactGroup, 0, len(a.contactGroups))
+	for _, cid := range sortedKeys(a.contactGroups) {
+		list = append(list, *a.contactGroups[cid])
+	}
+	return list
+}
+
+// Requests returns the requests received, as "METHOD /path"
+func (a *API) Requests() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string{}, a.requests...)
+}
+
+// RequestCount returns the number of requests received matching "METHOD /path",
+// a path ending in "/" matches any cid with that prefix
+func (a *API) RequestCount(req string) int {
+	n := 0
+	for _, r := range a.Requests() {
+		if r == req || (strings.HasSuffix(req, "/") && strings.HasPrefix(r, req)) {
+			n++
+		}
+	}
+	return n
+}
+
+func (a *API) handler(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/v2")
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.requests = append(a.requests, r.Method+" "+path)
+
+	if r.Header.Get("X-Circonus-Auth-Token") == "" {
+		writeError(w, http.StatusForbidden, "missing auth token")
+		return
+	}
+
+	var body []byte
+	if r.Body != nil {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		body = data
+	}
+
+	search := r.URL.Query().Get("search")
+
+	switch {
+	case path == "/pki/ca.crt":
+		writeJSON(w, map[string]string{"contents": string(a.ca.certPEM)})
+
+	case path == apiclicfg.CheckBundlePrefix:
+		switch r.Method {
+		case http.MethodGet:
+			a.searchCheckBundles(w, search)
+		case http.MethodPost:
+			var bundle apiclient.CheckBundle
+			if err := json.Unmarshal(body, &bundle); err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			cb, err := a.createCheckBundle(&bundle)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			writeJSON(w, cb)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, r.Method)
+		}
+
+	case strings.HasPrefix(path, apiclicfg.CheckBundlePrefix+"/"):
+		cb, ok := a.checkBundles[path]
+		if !ok {
+			writeError(w, http.StatusNotFound, path)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, cb)
+		case http.MethodPut:
+			var bundle apiclient.CheckBundle
+			if err := json.Unmarshal(body, &bundle); err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			// read-only attributes are managed by the api
+			bundle.CID = cb.CID
+			bundle.Checks = cb.Checks
+			bundle.CheckUUIDs = cb.CheckUUIDs
+			bundle.Brokers = cb.Brokers
+			if bundle.Config == nil {
+				bundle.Config = apiclient.CheckBundleConfig{}
+			}
+			bundle.Config[apiclicfg.SubmissionURL] = cb.Config[apiclicfg.SubmissionURL]
+			a.checkBundles[path] = &bundle
+			writeJSON(w, &bundle)
+		case http.MethodDelete:
+			delete(a.checkBundles, path)
+			writeJSON(w, map[string]string{})
+		default:
+			writeError(w, http.StatusMethodNotAllowed, r.Method)
+		}
+
+	case strings.HasPrefix(path, apiclicfg.CheckPrefix+"/"):
+		check, ok := a.checks[path]
+		if !ok {
+			writeError(w, http.StatusNotFound, path)
+			return
+		}
+		writeJSON(w, check)
+
+	case strings.HasPrefix(path, apiclicfg.BrokerPrefix+"/"):
+		b, ok := a.brokers[path]
+		if !ok {
+			writeError(w, http.StatusNotFound, path)
+			return
+		}
+		ip := "127.0.0.1"
+		writeJSON(w, &apiclient.Broker{
+			CID:  b.CID,
+			Name: "fake broker",
+			Type: "enterprise",
+			Details: []apiclient.BrokerDetail{
+				{CN: BrokerCN, IP: &ip, Status: "active", Modules: []string{"httptrap"}},
+			},
+		})
+
+	case path == apiclicfg.RuleSetPrefix:
+		switch r.Method {
+		case http.MethodGet:
+			a.searchRuleSets(w, search)
+		case http.MethodPost:
+			var rs apiclient.RuleSet
+			if err := json.Unmarshal(body, &rs); err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			a.nextID++
+			rs.CID = fmt.Sprintf("%s/%d", apiclicfg.RuleSetPrefix, a.nextID)
+			a.ruleSets[rs.CID] = &rs
+			writeJSON(w, &rs)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, r.Method)
+		}
+
+	case strings.HasPrefix(path, apiclicfg.RuleSetPrefix+"/"):
+		rs, ok := a.ruleSets[path]
+		if !ok {
+			writeError(w, http.StatusNotFound, path)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, rs)
+		case http.MethodPut:
+			var nrs apiclient.RuleSet
+			if err := json.Unmarshal(body, &nrs); err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			nrs.CID = rs.CID
+			a.ruleSets[path] = &nrs
+			writeJSON(w, &nrs)
+		case http.MethodDelete:
+			delete(a.ruleSets, path)
+			writeJSON(w, map[string]string{})
+		default:
+			writeError(w, http.StatusMethodNotAllowed, r.Method)
+		}
+
+	case path == apiclicfg.ContactGroupPrefix:
+		switch r.Method {
+		case http.MethodGet:
+			a.searchContactGroups(w, search)
+		case http.MethodPost:
+			var cg apiclient.ContactGroup
+			if err := json.Unmarshal(body, &cg); err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			a.nextID++
+			cg.CID = fmt.Sprintf("%s/%d", apiclicfg.ContactGroupPrefix, a.nextID)
+			a.contactGroups[cg.CID] = &cg
+			writeJSON(w, &cg)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, r.Method)
+		}
+
+	case strings.HasPrefix(path, apiclicfg.ContactGroupPrefix+"/"):
+		cg, ok := a.contactGroups[path]
+		if !ok {
+			writeError(w, http.StatusNotFound, path)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, cg)
+		case http.MethodPut:
+			var ncg apiclient.ContactGroup
+			if err := json.Unmarshal(body, &ncg); err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			ncg.CID = cg.CID
+			a.contactGroups[path] = &ncg
+			writeJSON(w, &ncg)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, r.Method)
+		}
+
+	default:
+		writeError(w, http.StatusNotFound, path)
+	}
+}
+
+// createCheckBundle assigns a cid and creates a check, on the
+// referenced fake broker, for each broker in the bundle
+func (a *API) createCheckBundle(bundle *apiclient.CheckBundle) (*apiclient.CheckBundle, error) {
+	if len(bundle.Brokers) == 0 {
+		return nil, fmt.Errorf("check bundle has no brokers")
+	}
+
+	a.nextID++
+	bundle.CID = fmt.Sprintf("%s/%d", apiclicfg.CheckBundlePrefix, a.nextID)
+	if bundle.Config == nil {
+		bundle.Config = apiclient.CheckBundleConfig{}
+	}
+	secret := bundle.Config[apiclicfg.Secret]
+	if secret == "" {
+		secret = "secret"
+	}
+	if bundle.Status == "" {
+		bundle.Status = "active"
+	}
+
+	bundle.Checks = nil
+	bundle.CheckUUIDs = nil
+	for _, brokerCID := range bundle.Brokers {
+		b, ok := a.brokers[brokerCID]
+		if !ok {
+			return nil, fmt.Errorf("unknown broker (%s)", brokerCID)
+		}
+		a.nextID++
+		check := &apiclient.Check{
+			CID:            fmt.Sprintf("%s/%d", apiclicfg.CheckPrefix, a.nextID),
+			CheckUUID:      fmt.Sprintf("00000000-0000-4000-8000-%012d", a.nextID),
+			CheckBundleCID: bundle.CID,
+			BrokerCID:      brokerCID,
+			Active:         true,
+		}
+		check.Details = apiclient.CheckDetails{apiclicfg.SubmissionURL: b.SubmissionURL(check.CheckUUID, secret)}
+		a.checks[check.CID] = check
+		bundle.Checks = append(bundle.Checks, check.CID)
+		bundle.CheckUUIDs = append(bundle.CheckUUIDs, check.CheckUUID)
+		if _, ok := bundle.Config[apiclicfg.SubmissionURL]; !ok {
+			bundle.Config[apiclicfg.SubmissionURL] = check.Details[apiclicfg.SubmissionURL]
+		}
+	}
+
+	a.checkBundles[bundle.CID] = bundle
+
+	return bundle, nil
+}
+
+func (a *API) searchCheckBundles(w http.ResponseWriter, search string) {
+	_, terms := parseSearch(search)
+	list := []apiclient.CheckBundle{}
+	for _, cid := range sortedKeys(a.checkBundles) {
+		cb := a.checkBundles[cid]
+		if v, ok := terms["active"]; ok && (v == "1") != (cb.Status == "active") {
+			continue
+		}
+		if v, ok := terms["type"]; ok && v != cb.Type {
+			continue
+		}
+		if v, ok := terms["host"]; ok && v != cb.Target {
+			continue
+		}
+		list = append(list, *cb)
+	}
+	writeJSON(w, list)
+}
+
+func (a *API) searchRuleSets(w http.ResponseWriter, search string) {
+	_, terms := parseSearch(search)
+	list := []apiclient.RuleSet{}
+	for _, cid := range sortedKeys(a.ruleSets) {
+		rs := a.ruleSets[cid]
+		if v, ok := terms["name"]; ok && v != rs.Name {
+			continue
+		}
+		if v, ok := terms["tags"]; ok && !hasTag(rs.Tags, v) {
+			continue
+		}
+		list = append(list, *rs)
+	}
+	writeJSON(w, list)
+}
+
+func (a *API) searchContactGroups(w http.ResponseWriter, search string) {
+	text, terms := parseSearch(search)
+	list := []apiclient.ContactGroup{}
+	for _, cid := range sortedKeys(a.contactGroups) {
+		cg := a.contactGroups[cid]
+		if text != "" && text != cg.Name {
+			continue
+		}
+		if v, ok := terms["tags"]; ok && !hasTag(cg.Tags, v) {
+			continue
+		}
+		list = append(list, *cg)
+	}
+	writeJSON(w, list)
+}
+
+// parseSearch splits an api search query into free text and (key:value) terms
+func parseSearch(search string) (string, map[string]string) {
+	terms := make(map[string]string)
+	for _, m := range searchTermRx.FindAllStringSubmatch(search, -1) {
+		terms[m[1]] = m[2] + m[3]
+	}
+	return strings.TrimSpace(searchTermRx.ReplaceAllString(search, "")), terms
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"code": http.StatusText(code), "message": msg})
+}
+
+// CirconusConfig returns a circonus configuration using the api and its
+// primary broker, tags are not base64 encoded so metric names are readable
+func (a *API) CirconusConfig(target string) *config.Circonus {
+	return &config.Circonus{
+		API: config.API{
+			Key: "11111111-2222-3333-4444-555555555555",
+			App: "test",
+			URL: a.URL,
+		},
+		Check: config.Check{
+			BrokerCID: a.Broker.CID,
+			Create:    true,
+			Target:    target,
+			Title:     target + " /k8s",
+		},
+		SubmitDeadline: "10s",
+	}
+}
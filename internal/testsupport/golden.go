@@ -0,0 +1,53 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package testsupport
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// MetricNames returns the sorted names (including tags) and types of the metrics, one per line
+func MetricNames(metrics map[string]Metric) []byte {
+	lines := make([]string, 0, len(metrics))
+	for name, m := range metrics {
+		lines = append(lines, name+" "+m.Type)
+	}
+	sort.Strings(lines)
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+// Golden compares got to the golden file testdata/<name>, with -update the golden file is (re)written
+func Golden(t testing.TB, name string, got []byte) {
+	t.Helper()
+
+	fn := filepath.Join("testdata", name)
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		if err := os.WriteFile(fn, got, 0600); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(fn)
+	if err != nil {
+		t.Fatalf("reading golden file (run with -update to create): %s", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("output does not match %s (run with -update to accept)\n--- got\n%s\n--- want\n%s", fn, got, want)
+	}
+}
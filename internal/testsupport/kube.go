@@ -0,0 +1,522 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package testsupport
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/circonus-labs/circonus-kubernetes-agent/internal/config"
+	appsv1 "k8s.io/api/apps/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// kubeResource describes how a resource is served by the fake kubernetes api
+type kubeResource struct {
+	group    string // api path prefix, e.g. /api/v1 or /apis/apps/v1
+	listKind string
+}
+
+var kubeResources = map[string]kubeResource{
+	"nodes":        {group: "/api/v1", listKind: "NodeList"},
+	"pods":         {group: "/api/v1", listKind: "PodList"},
+	"services":     {group: "/api/v1", listKind: "ServiceList"},
+	"endpoints":    {group: "/api/v1", listKind: "EndpointsList"},
+	"events":       {group: "/api/v1", listKind: "EventList"},
+	"deployments":  {group: "/apis/apps/v1", listKind: "DeploymentList"},
+	"daemonsets":   {group: "/apis/apps/v1", listKind: "DaemonSetList"},
+	"statefulsets": {group: "/apis/apps/v1", listKind: "StatefulSetList"},
+	"replicasets":  {group: "/apis/apps/v1", listKind: "ReplicaSetList"},
+	"leases":       {group: "/apis/coordination.k8s.io/v1", listKind: "LeaseList"},
+}
+
+// rawResponse is a canned response for a path
+type rawResponse struct {
+	contentType string
+	body        []byte
+	status      int
+}
+
+// KubeAPI is a fake kubernetes api server. It serves objects added with AddObjects
+// (list, with label and field selectors, and get), canned responses for node
+// proxy paths (e.g. /stats/summary, /metrics/cadvisor) and arbitrary paths.
+// Watches are accepted and held open without events. Leases can also be
+// created and updated (used by leader election).
+type KubeAPI struct {
+	*httptest.Server
+	objects   map[string][]runtime.Object
+	nodeProxy map[string]map[string]rawResponse
+	raw       map[string]rawResponse
+	handlers  map[string]http.HandlerFunc
+	endpoints []*httptest.Server
+	done      chan struct{}
+	version   string
+	requests  []string
+	// resourceVersion of the last lease write
+	resourceVersion int
+	mu              sync.Mutex
+}
+
+// NewKubeAPI starts a fake kubernetes api server reporting gitVersion from /version
+func NewKubeAPI(gitVersion string) *KubeAPI {
+	k := &KubeAPI{
+		objects:   make(map[string][]runtime.Object),
+		nodeProxy: make(map[string]map[string]rawResponse),
+		raw:       make(map[string]rawResponse),
+		handlers:  make(map[string]http.HandlerFunc),
+		done:      make(chan struct{}),
+		version:   gitVersion,
+	}
+	k.Server = httptest.NewServer(http.HandlerFunc(k.handler))
+	return k
+}
+
+// Close shuts down the api server and any metrics endpoints
+func (k *KubeAPI) Close() {
+	close(k.done) // release watches
+	k.Server.Close()
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for _, srv := range k.endpoints {
+		srv.Close()
+	}
+}
+
+// NewClient returns a client for the fake api server, it can be used as a k8s.ClientFactory.
+// Requests are not rate limited.
+func (k *KubeAPI) NewClient(clusterConfig *config.Cluster) (kubernetes.Interface, error) {
+	return kubernetes.NewForConfig(&rest.Config{Host: k.URL, QPS: -1})
+}
+
+// ClusterConfig returns a cluster configuration for the fake api server
+func (k *KubeAPI) ClusterConfig(name string) *config.Cluster {
+	return &config.Cluster{
+		Name:         name,
+		URL:          k.URL,
+		APITimelimit: "5s",
+		NodePoolSize: 1,
+	}
+}
+
+// AddObjects adds objects (nodes, pods, services, endpoints, events,
+// deployments, daemonsets, statefulsets and replicasets) to the api
+func (k *KubeAPI) AddObjects(objs ...runtime.Object) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for _, obj := range objs {
+		resource, err := resourceName(obj)
+		if err != nil {
+			return err
+		}
+		k.objects[resource] = append(k.objects[resource], obj)
+	}
+	return nil
+}
+
+// SetNodeProxy sets the response for a node proxy path, e.g. "/stats/summary" or "/metrics/cadvisor"
+func (k *KubeAPI) SetNodeProxy(node, path string, body []byte) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.nodeProxy[node]; !ok {
+		k.nodeProxy[node] = make(map[string]rawResponse)
+	}
+	k.nodeProxy[node][path] = rawResponse{status: http.StatusOK, contentType: "text/plain; version=0.0.4", body: body}
+}
+
+// Handle sets a canned response for a path, it takes precedence over objects
+func (k *KubeAPI) Handle(path string, status int, contentType string, body []byte) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.raw[path] = rawResponse{status: status, contentType: contentType, body: body}
+}
+
+// HandleFunc sets a handler for a path, it takes precedence over canned responses
+// and objects (e.g. to delay or fail a response)
+func (k *KubeAPI) HandleFunc(path string, h http.HandlerFunc) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.handlers[path] = h
+}
+
+// AddMetricsEndpoint starts a server answering /metrics with body and adds an
+// endpoints object, in namespace with name, pointing at it with a port named
+// portName (e.g. to emulate kube-state-metrics)
+func (k *KubeAPI) AddMetricsEndpoint(namespace, name, portName string, body []byte) (*httptest.Server, error) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write(body)
+	}))
+
+	host, p, err := net.SplitHostPort(srv.Listener.Addr().String())
+	if err != nil {
+		srv.Close()
+		return nil, fmt.Errorf("parsing listener address: %w", err)
+	}
+	port, err := strconv.Atoi(p)
+	if err != nil {
+		srv.Close()
+		return nil, fmt.Errorf("parsing listener port: %w", err)
+	}
+
+	k.mu.Lock()
+	k.endpoints = append(k.endpoints, srv)
+	k.mu.Unlock()
+
+	ep := &v1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Subsets: []v1.EndpointSubset{
+			{
+				Addresses: []v1.EndpointAddress{{IP: host}},
+				Ports:     []v1.EndpointPort{{Name: portName, Port: int32(port)}},
+			},
+		},
+	}
+	if err := k.AddObjects(ep); err != nil {
+		srv.Close()
+		return nil, err
+	}
+
+	return srv, nil
+}
+
+// StartKubelet starts a TLS server emulating the kubelet on node, it serves the
+// node proxy responses (see SetNodeProxy) to requests bearing token. It returns
+// the address (host and port) of the server, the server is closed with the api.
+func (k *KubeAPI) StartKubelet(node, token string) (string, int, error) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		k.mu.Lock()
+		k.requests = append(k.requests, "KUBELET "+node+" "+r.Method+" "+r.URL.Path)
+		resp, ok := k.nodeProxy[node][r.URL.Path]
+		k.mu.Unlock()
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			writeKubeStatus(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		if !ok {
+			writeKubeStatus(w, http.StatusNotFound, r.URL.Path)
+			return
+		}
+		writeRaw(w, resp)
+	}))
+
+	host, p, err := net.SplitHostPort(srv.Listener.Addr().String())
+	if err != nil {
+		srv.Close()
+		return "", 0, fmt.Errorf("parsing listener address: %w", err)
+	}
+	port, err := strconv.Atoi(p)
+	if err != nil {
+		srv.Close()
+		return "", 0, fmt.Errorf("parsing listener port: %w", err)
+	}
+
+	k.mu.Lock()
+	k.endpoints = append(k.endpoints, srv)
+	k.mu.Unlock()
+
+	return host, port, nil
+}
+
+// Requests returns the requests received, as "METHOD /path"
+func (k *KubeAPI) Requests() []string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return append([]string{}, k.requests...)
+}
+
+func (k *KubeAPI) handler(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+
+	k.mu.Lock()
+	k.requests = append(k.requests, r.Method+" "+path)
+	raw, haveRaw := k.raw[path]
+	h, haveHandler := k.handlers[path]
+	k.mu.Unlock()
+
+	if haveHandler {
+		h(w, r)
+		return
+	}
+
+	if haveRaw {
+		writeRaw(w, raw)
+		return
+	}
+
+	if path == "/version" {
+		writeJSON(w, map[string]string{"major": "1", "gitVersion": k.version, "platform": "linux/amd64"})
+		return
+	}
+
+	if r.Method == http.MethodPost || r.Method == http.MethodPut {
+		k.writeLease(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeKubeStatus(w, http.StatusMethodNotAllowed, r.Method)
+		return
+	}
+
+	// node proxy, /api/v1/nodes/<node>/proxy/<path>
+	if strings.HasPrefix(path, "/api/v1/nodes/") && strings.Contains(path, "/proxy/") {
+		parts := strings.SplitN(strings.TrimPrefix(path, "/api/v1/nodes/"), "/proxy", 2)
+		k.mu.Lock()
+		resp, ok := k.nodeProxy[parts[0]][parts[1]]
+		k.mu.Unlock()
+		if !ok {
+			writeKubeStatus(w, http.StatusNotFound, path)
+			return
+		}
+		writeRaw(w, resp)
+		return
+	}
+
+	for resource, rd := range kubeResources {
+		if !strings.HasPrefix(path, rd.group+"/") {
+			continue
+		}
+		parts := strings.Split(strings.TrimPrefix(path, rd.group+"/"), "/")
+		namespace := ""
+		if len(parts) >= 3 && parts[0] == "namespaces" {
+			namespace = parts[1]
+			parts = parts[2:]
+		}
+		if parts[0] != resource {
+			continue
+		}
+		switch len(parts) {
+		case 1:
+			if r.URL.Query().Get("watch") == "true" || r.URL.Query().Get("watch") == "1" {
+				k.watch(w, r)
+				return
+			}
+			k.list(w, r, resource, rd, namespace)
+		case 2:
+			k.get(w, resource, namespace, parts[1])
+		default:
+			writeKubeStatus(w, http.StatusNotFound, path)
+		}
+		return
+	}
+
+	writeKubeStatus(w, http.StatusNotFound, path)
+}
+
+func (k *KubeAPI) list(w http.ResponseWriter, r *http.Request, resource string, rd kubeResource, namespace string) {
+	labelSelector, err := labels.Parse(r.URL.Query().Get("labelSelector"))
+	if err != nil {
+		writeKubeStatus(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	fieldSelector, err := fields.ParseSelector(r.URL.Query().Get("fieldSelector"))
+	if err != nil {
+		writeKubeStatus(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	items := []runtime.Object{}
+	for _, obj := range k.objects[resource] {
+		m, err := meta.Accessor(obj)
+		if err != nil {
+			continue
+		}
+		if namespace != "" && m.GetNamespace() != namespace {
+			continue
+		}
+		if !labelSelector.Matches(labels.Set(m.GetLabels())) {
+			continue
+		}
+		fieldSet := fields.Set{"metadata.name": m.GetName(), "metadata.namespace": m.GetNamespace()}
+		if pod, ok := obj.(*v1.Pod); ok {
+			fieldSet["spec.nodeName"] = pod.Spec.NodeName
+		}
+		if !fieldSelector.Matches(fieldSet) {
+			continue
+		}
+		items = append(items, obj)
+	}
+
+	apiVersion := strings.TrimPrefix(strings.TrimPrefix(rd.group, "/apis/"), "/api/")
+	writeJSON(w, map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       rd.listKind,
+		"metadata":   map[string]string{"resourceVersion": "1"},
+		"items":      items,
+	})
+}
+
+func (k *KubeAPI) get(w http.ResponseWriter, resource, namespace, name string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for _, obj := range k.objects[resource] {
+		m, err := meta.Accessor(obj)
+		if err != nil {
+			continue
+		}
+		if m.GetName() == name && (namespace == "" || m.GetNamespace() == namespace) {
+			writeJSON(w, obj)
+			return
+		}
+	}
+
+	writeKubeStatus(w, http.StatusNotFound, fmt.Sprintf("%s %q not found", resource, name))
+}
+
+// writeLease creates (POST .../namespaces/<ns>/leases) or updates (PUT .../namespaces/<ns>/leases/<name>)
+// a lease, updates must have the current resourceVersion
+func (k *KubeAPI) writeLease(w http.ResponseWriter, r *http.Request) {
+	prefix := kubeResources["leases"].group + "/namespaces/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		writeKubeStatus(w, http.StatusMethodNotAllowed, r.Method)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	if len(parts) < 2 || parts[1] != "leases" {
+		writeKubeStatus(w, http.StatusMethodNotAllowed, r.Method)
+		return
+	}
+
+	var lease coordinationv1.Lease
+	if err := json.NewDecoder(r.Body).Decode(&lease); err != nil {
+		writeKubeStatus(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	lease.Namespace = parts[0]
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	idx := -1
+	for i, obj := range k.objects["leases"] {
+		if l := obj.(*coordinationv1.Lease); l.Name == lease.Name && l.Namespace == lease.Namespace {
+			idx = i
+			break
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	k.resourceVersion++
+	switch {
+	case r.Method == http.MethodPost && idx == -1:
+		lease.ResourceVersion = strconv.Itoa(k.resourceVersion)
+		k.objects["leases"] = append(k.objects["leases"], &lease)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPost:
+		writeKubeStatusReason(w, http.StatusConflict, metav1.StatusReasonAlreadyExists, fmt.Sprintf("leases %q already exists", lease.Name))
+		return
+	case idx == -1:
+		writeKubeStatusReason(w, http.StatusNotFound, metav1.StatusReasonNotFound, fmt.Sprintf("leases %q not found", lease.Name))
+		return
+	case k.objects["leases"][idx].(*coordinationv1.Lease).ResourceVersion != lease.ResourceVersion:
+		writeKubeStatusReason(w, http.StatusConflict, metav1.StatusReasonConflict, fmt.Sprintf("leases %q modified", lease.Name))
+		return
+	default:
+		lease.ResourceVersion = strconv.Itoa(k.resourceVersion)
+		k.objects["leases"][idx] = &lease
+	}
+
+	_ = json.NewEncoder(w).Encode(&lease)
+}
+
+// Lease returns a copy of a lease created by a client, nil if it does not exist
+func (k *KubeAPI) Lease(namespace, name string) *coordinationv1.Lease {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for _, obj := range k.objects["leases"] {
+		if l := obj.(*coordinationv1.Lease); l.Name == name && l.Namespace == namespace {
+			return l.DeepCopy()
+		}
+	}
+	return nil
+}
+
+// watch holds the request open, without sending events, until the client or api closes
+func (k *KubeAPI) watch(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	select {
+	case <-r.Context().Done():
+	case <-k.done:
+	}
+}
+
+// resourceName returns the api resource name for an object
+func resourceName(obj runtime.Object) (string, error) {
+	switch obj.(type) {
+	case *v1.Node:
+		return "nodes", nil
+	case *v1.Pod:
+		return "pods", nil
+	case *v1.Service:
+		return "services", nil
+	case *v1.Endpoints:
+		return "endpoints", nil
+	case *v1.Event:
+		return "events", nil
+	case *appsv1.Deployment:
+		return "deployments", nil
+	case *appsv1.DaemonSet:
+		return "daemonsets", nil
+	case *appsv1.StatefulSet:
+		return "statefulsets", nil
+	case *appsv1.ReplicaSet:
+		return "replicasets", nil
+	case *coordinationv1.Lease:
+		return "leases", nil
+	default:
+		return "", fmt.Errorf("unsupported object type %T", obj)
+	}
+}
+
+func writeRaw(w http.ResponseWriter, resp rawResponse) {
+	if resp.contentType != "" {
+		w.Header().Set("Content-Type", resp.contentType)
+	}
+	w.WriteHeader(resp.status)
+	_, _ = w.Write(resp.body)
+}
+
+func writeKubeStatus(w http.ResponseWriter, code int, msg string) {
+	writeKubeStatusReason(w, code, "", msg)
+}
+
+func writeKubeStatusReason(w http.ResponseWriter, code int, reason metav1.StatusReason, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(metav1.Status{
+		TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+		Status:   metav1.StatusFailure,
+		Message:  msg,
+		Reason:   reason,
+		Code:     int32(code),
+	})
+}
@@ -0,0 +1,23 @@
+// Copyright © 2026 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package testsupport
+
+import "testing"
+
+func TestParseSearch(t *testing.T) {
+	t.Log("Testing parseSearch")
+
+	text, terms := parseSearch(`test default alerts (active:1)(name:"CPU (test)")(tags:cluster:test)`)
+	if text != "test default alerts" {
+		t.Fatalf("expected 'test default alerts', got %q", text)
+	}
+	expect := map[string]string{"active": "1", "name": "CPU (test)", "tags": "cluster:test"}
+	for k, v := range expect {
+		if terms[k] != v {
+			t.Fatalf("expected %s=%q, got %q", k, v, terms[k])
+		}
+	}
+}